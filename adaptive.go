@@ -0,0 +1,87 @@
+package orchwf
+
+import (
+	"math"
+	"sync"
+)
+
+// adaptiveWindow is the number of recent attempts of a step that make up its success rate
+const adaptiveWindow = 20
+
+// WithAdaptiveRetry fits the attempt cap of steps that have a retry policy to their recent
+// success rate in this process. A step whose recent attempts mostly fail gets up to
+// maxAttempts, one that mostly succeeds gets minAttempts, and rates in between scale
+// linearly. A step with no attempts yet gets maxAttempts. The policy's backoff still applies,
+// and steps without a retry policy keep their single attempt.
+func WithAdaptiveRetry(minAttempts, maxAttempts int) Option {
+	return func(o *Orchestrator) {
+		if minAttempts < 1 {
+			minAttempts = 1
+		}
+		if maxAttempts < minAttempts {
+			maxAttempts = minAttempts
+		}
+		o.adaptive = &adaptiveRetry{
+			minAttempts: minAttempts,
+			maxAttempts: maxAttempts,
+			windows:     make(map[string]*attemptWindow),
+		}
+	}
+}
+
+// adaptiveRetry tracks recent attempt outcomes per step and derives attempt caps from them
+type adaptiveRetry struct {
+	minAttempts int
+	maxAttempts int
+	mu          sync.Mutex
+	windows     map[string]*attemptWindow // Recent outcomes by workflow and step ID
+}
+
+// attemptWindow is a ring of the most recent attempt outcomes of a step
+type attemptWindow struct {
+	succeeded [adaptiveWindow]bool
+	next      int
+	count     int
+}
+
+// adaptiveKey identifies a step across the instances of its workflow
+func adaptiveKey(workflowID, stepID string) string {
+	return workflowID + "/" + stepID
+}
+
+// record adds the outcome of one attempt of a step
+func (a *adaptiveRetry) record(key string, succeeded bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	window, ok := a.windows[key]
+	if !ok {
+		window = &attemptWindow{}
+		a.windows[key] = window
+	}
+	window.succeeded[window.next] = succeeded
+	window.next = (window.next + 1) % adaptiveWindow
+	if window.count < adaptiveWindow {
+		window.count++
+	}
+}
+
+// attemptsFor returns the attempt cap for a step given its recent success rate
+func (a *adaptiveRetry) attemptsFor(key string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	window, ok := a.windows[key]
+	if !ok || window.count == 0 {
+		return a.maxAttempts
+	}
+
+	successes := 0
+	for i := 0; i < window.count; i++ {
+		if window.succeeded[i] {
+			successes++
+		}
+	}
+	rate := float64(successes) / float64(window.count)
+	return a.maxAttempts - int(math.Round(rate*float64(a.maxAttempts-a.minAttempts)))
+}
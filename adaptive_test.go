@@ -0,0 +1,84 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_AdaptiveRetry(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithAdaptiveRetry(1, 4))
+
+	failing := true
+	attempts := 0
+	workflow, _ := NewWorkflowBuilder("adaptive", "Adaptive").
+		AddStepFunc("call", "Call", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			attempts++
+			if failing {
+				return nil, errors.New("dependency unavailable")
+			}
+			return nil, nil
+		}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	run := func() int {
+		attempts = 0
+		orchestrator.StartWorkflow(context.Background(), "adaptive", nil, nil)
+		return attempts
+	}
+
+	// No history yet: the step gets the most attempts, not the policy's 2
+	if got := run(); got != 4 {
+		t.Errorf("attempts with no history = %d, want 4", got)
+	}
+
+	// A run of successes fills the window and brings the cap down to the minimum
+	failing = false
+	for i := 0; i < adaptiveWindow; i++ {
+		run()
+	}
+	failing = true
+	if got := run(); got != 1 {
+		t.Errorf("attempts after a high success rate = %d, want 1", got)
+	}
+
+	// As failures push the success rate back down, the cap rises again
+	for i := 0; i < adaptiveWindow; i++ {
+		run()
+	}
+	if got := run(); got != 4 {
+		t.Errorf("attempts after a low success rate = %d, want 4", got)
+	}
+}
+
+func TestAdaptiveRetry_AttemptsFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		failures  int
+		want      int
+	}{
+		{"no history", 0, 0, 5},
+		{"all failed", 0, 10, 5},
+		{"half succeeded", 5, 5, 3},
+		{"mostly succeeded", 9, 1, 1},
+		{"all succeeded", 10, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adaptive := &adaptiveRetry{minAttempts: 1, maxAttempts: 5, windows: make(map[string]*attemptWindow)}
+			for i := 0; i < tt.successes; i++ {
+				adaptive.record("wf/step", true)
+			}
+			for i := 0; i < tt.failures; i++ {
+				adaptive.record("wf/step", false)
+			}
+			if got := adaptive.attemptsFor("wf/step"); got != tt.want {
+				t.Errorf("attemptsFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package orchwf
+
+import (
+	"context"
+	"sort"
+)
+
+// AdvancedExecutor is a step executor that receives a StepContext instead of just its input.
+// It is an escape hatch for steps that need the outputs of steps outside their dependencies.
+type AdvancedExecutor func(ctx context.Context, sc *StepContext) (map[string]interface{}, error)
+
+// StepContext is what an AdvancedExecutor sees of the workflow instance it runs for
+type StepContext struct {
+	StepID   string
+	Input    map[string]interface{} // The prepared input a plain executor would receive
+	Instance InstanceInfo           // IDs and a snapshot of the metadata of the instance
+
+	outputs map[string]map[string]interface{} // Outputs of steps completed before the step's wave, by step ID
+}
+
+// StepOutput returns a copy of the output of a step of the instance that completed before
+// this step's wave started. Steps running alongside it are not visible.
+func (sc *StepContext) StepOutput(stepID string) (map[string]interface{}, bool) {
+	output, ok := sc.outputs[stepID]
+	if !ok {
+		return nil, false
+	}
+	return copyMap(output), true
+}
+
+// StepIDs returns the IDs of the steps whose outputs StepOutput can read
+func (sc *StepContext) StepIDs() []string {
+	ids := make([]string, 0, len(sc.outputs))
+	for id := range sc.outputs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// hasAdvancedExecutor reports whether any of the steps, or their alternatives, has an advanced executor
+func hasAdvancedExecutor(steps []*StepDefinition) bool {
+	for _, stepDef := range steps {
+		if stepDef.AdvancedExecutor != nil || hasAdvancedExecutor(stepDef.Alternatives) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCompletedOutputs returns a copy of ctx carrying the outputs of the completed steps,
+// for the advanced executors run under it. It must be called while no step is running.
+func withCompletedOutputs(ctx context.Context, stepInstMap map[string]*StepInstance) context.Context {
+	outputs := make(map[string]map[string]interface{})
+	for stepID, stepInst := range stepInstMap {
+		if stepInst.Status == StepStatusCompleted {
+			outputs[stepID] = copyMap(stepInst.Output)
+		}
+	}
+	return context.WithValue(ctx, completedOutputsKey, outputs)
+}
+
+// callExecutor calls a step's executor. Advanced executors run in this process, since they
+// read the live instance; plain executors go through the transport. Panics are recovered
+// as errors unless the panic policy is PanicRepanic.
+func (o *Orchestrator) callExecutor(ctx context.Context, stepDef *StepDefinition, input map[string]interface{}) (output map[string]interface{}, err error) {
+	if o.panicPolicy != PanicRepanic {
+		defer func() {
+			if p := recover(); p != nil {
+				output, err = nil, o.recoveredPanic(stepDef, p)
+			}
+		}()
+	}
+
+	if stepDef.AdvancedExecutor == nil {
+		return o.transport.Execute(ctx, stepDef, input)
+	}
+
+	info, _ := InstanceInfoFromContext(ctx)
+	outputs, _ := ctx.Value(completedOutputsKey).(map[string]map[string]interface{})
+	return stepDef.AdvancedExecutor(ctx, &StepContext{
+		StepID:   stepDef.ID,
+		Input:    input,
+		Instance: info,
+		outputs:  outputs,
+	})
+}
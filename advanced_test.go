@@ -0,0 +1,50 @@
+package orchwf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestOrchestrator_AdvancedExecutor(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	var seen *StepContext
+	workflow, _ := NewWorkflowBuilder("billing", "Billing").
+		AddStepFunc("usage", "Collect Usage", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"units": 42}, nil
+		}).
+		AddStepFunc("plan", "Load Plan", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"price": 2}, nil
+		}, WithStepDeps("usage")).
+		// invoice depends only on plan, yet reads the usage step's output
+		AddStepFunc("invoice", "Invoice", nil, WithStepDeps("plan"), WithStepAdvancedExecutor(func(ctx context.Context, sc *StepContext) (map[string]interface{}, error) {
+			seen = sc
+			usage, _ := sc.StepOutput("usage")
+			usage["units"] = 0 // Outputs are copies
+			return map[string]interface{}{"total": 42 * sc.Input["price"].(int)}, nil
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "billing", map[string]interface{}{"account": "a1"}, map[string]interface{}{"region": "eu"})
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if result.WorkflowInst.Output["total"] != 84 {
+		t.Errorf("total = %v, want 84", result.WorkflowInst.Output["total"])
+	}
+
+	if seen.StepID != "invoice" || seen.Instance.InstanceID != result.WorkflowInst.ID || seen.Instance.Metadata["region"] != "eu" {
+		t.Errorf("step context = %+v, want the invoice step of the instance", seen)
+	}
+	if ids := seen.StepIDs(); !reflect.DeepEqual(ids, []string{"plan", "usage"}) {
+		t.Errorf("StepIDs() = %v, want [plan usage]", ids)
+	}
+	if usage, ok := seen.StepOutput("usage"); !ok || usage["units"] != 42 {
+		t.Errorf("StepOutput(usage) = %v, %v, want the collected units", usage, ok)
+	}
+	if _, ok := seen.StepOutput("invoice"); ok {
+		t.Errorf("StepOutput(invoice) found, want only steps completed before the wave")
+	}
+}
@@ -0,0 +1,72 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// executeAlternatives runs the members of an alternatives group in order and completes the
+// group with the output of the first member that succeeds. The remaining members are skipped.
+// The group fails only when every member has failed.
+func (o *Orchestrator) executeAlternatives(ctx context.Context, group *StepDefinition, groupInst *StepInstance, workflowInst *WorkflowInstance, stepInstMap map[string]*StepInstance) error {
+	if err := o.transitionStep(groupInst, StepStatusRunning); err != nil {
+		return err
+	}
+	startedAt := time.Now()
+	groupInst.StartedAt = &startedAt
+	if o.savesTransitions(workflowInst.WorkflowID) {
+		o.notePersistence(o.stateManager.UpdateStepStatus(ctx, groupInst.ID, StepStatusRunning))
+
+		o.emitEvent(ctx, workflowInst.ID, &groupInst.ID, EventStepStarted, map[string]interface{}{
+			"step_id": group.ID,
+		})
+	}
+
+	var winner *StepInstance
+	var lastErr error
+	kind := FailureKindExhaustedRetries
+	for _, member := range group.Alternatives {
+		memberInst, err := o.ensureStepInstance(ctx, workflowInst, stepInstMap, member)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to save alternative %s: %w", member.ID, err)
+			break
+		}
+
+		if winner != nil {
+			if memberInst.Status == StepStatusPending {
+				o.skipStep(ctx, memberInst, workflowInst, SkipReasonAlternativeWon)
+			}
+			continue
+		}
+
+		// Members that already ran before a resume keep their outcome
+		if !memberInst.IsCompleted() {
+			if err := o.executeStep(ctx, member, memberInst, workflowInst, stepInstMap); err != nil {
+				lastErr = err
+			}
+			if memberInst.FailureKind != "" {
+				kind = memberInst.FailureKind
+			}
+		}
+		if memberInst.Status == StepStatusCompleted {
+			winner = memberInst
+		}
+	}
+
+	if winner == nil {
+		err := fmt.Errorf("all %d alternatives failed", len(group.Alternatives))
+		if lastErr != nil {
+			err = fmt.Errorf("all %d alternatives failed: %w", len(group.Alternatives), lastErr)
+		}
+		o.failStep(ctx, group, groupInst, workflowInst, err, kind)
+		return fmt.Errorf("step %s failed: %w", group.ID, err)
+	}
+
+	o.emitEvent(ctx, workflowInst.ID, &groupInst.ID, EventAlternativeWon, map[string]interface{}{
+		"alternative": winner.StepID,
+	})
+	o.completeStep(ctx, group, groupInst, workflowInst, winner.Output, time.Since(startedAt))
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package orchwf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment describes an artifact produced by a step. The content lives in a BlobStore;
+// only this metadata is persisted with the step.
+type Attachment struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Ref         string    `json:"ref"` // BlobStore reference for the content
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlobStore stores attachment content and returns a reference to it
+type BlobStore interface {
+	Put(ctx context.Context, name, contentType string, r io.Reader) (ref string, size int64, err error)
+	Get(ctx context.Context, ref string) (io.ReadCloser, error)
+}
+
+// WithBlobStore sets the store used for step attachments
+func WithBlobStore(store BlobStore) Option {
+	return func(o *Orchestrator) {
+		o.blobStore = store
+	}
+}
+
+// InMemoryBlobStore keeps attachment content in memory, for development and tests
+type InMemoryBlobStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewInMemoryBlobStore creates an empty in-memory blob store
+func NewInMemoryBlobStore() *InMemoryBlobStore {
+	return &InMemoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+// Put reads r fully and stores it under a new reference
+func (s *InMemoryBlobStore) Put(ctx context.Context, name, contentType string, r io.Reader) (string, int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ref := uuid.New().String()
+	s.mu.Lock()
+	s.blobs[ref] = data
+	s.mu.Unlock()
+
+	return ref, int64(len(data)), nil
+}
+
+// Get returns the content stored under ref
+func (s *InMemoryBlobStore) Get(ctx context.Context, ref string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	data, ok := s.blobs[ref]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, ref)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// AddAttachment stores the content of r in the orchestrator's BlobStore and records it
+// on the step being executed. It returns ErrNoWorkflowContext outside a step and
+// ErrNoBlobStore when no store was configured with WithBlobStore.
+func AddAttachment(ctx context.Context, name, contentType string, r io.Reader) (Attachment, error) {
+	run, ok := ctx.Value(stepRunKey).(*stepRun)
+	if !ok {
+		return Attachment{}, ErrNoWorkflowContext
+	}
+
+	o := run.orchestrator
+	if o.blobStore == nil {
+		return Attachment{}, ErrNoBlobStore
+	}
+
+	ref, size, err := o.blobStore.Put(ctx, name, contentType, r)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to store attachment %s: %w", name, err)
+	}
+
+	attachment := Attachment{
+		Name:        name,
+		ContentType: contentType,
+		Ref:         ref,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+
+	run.mu.Lock()
+	run.stepInst.Attachments = append(run.stepInst.Attachments, attachment)
+	attachments := append([]Attachment(nil), run.stepInst.Attachments...)
+	run.mu.Unlock()
+
+	if err := o.stateManager.UpdateStepAttachments(ctx, run.stepInst.ID, attachments); err != nil {
+		return attachment, fmt.Errorf("failed to record attachment %s: %w", name, err)
+	}
+	return attachment, nil
+}
+
+// attachmentsOrEmpty returns a non-nil slice so attachments serialize as a JSON array
+func attachmentsOrEmpty(attachments []Attachment) []Attachment {
+	if attachments == nil {
+		return []Attachment{}
+	}
+	return attachments
+}
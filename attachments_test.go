@@ -0,0 +1,96 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInMemoryBlobStore(t *testing.T) {
+	store := NewInMemoryBlobStore()
+	ctx := context.Background()
+
+	ref, size, err := store.Put(ctx, "export.csv", "text/csv", strings.NewReader("id,total\n1,9.99\n"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if size != 16 {
+		t.Errorf("Put() size = %d, want 16", size)
+	}
+
+	rc, err := store.Get(ctx, ref)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if string(data) != "id,total\n1,9.99\n" {
+		t.Errorf("Get() content = %q", data)
+	}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() on missing ref error = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestOrchestrator_StepAttachments(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	store := NewInMemoryBlobStore()
+	orchestrator := NewOrchestrator(sm, WithBlobStore(store))
+
+	workflow, _ := NewWorkflowBuilder("export", "Export").
+		AddStepFunc("export", "Export", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			if _, err := AddAttachment(ctx, "orders.csv", "text/csv", strings.NewReader("id\n1\n")); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "export", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	if len(steps) != 1 || len(steps[0].Attachments) != 1 {
+		t.Fatalf("step attachments = %+v, want one attachment", steps)
+	}
+	attachment := steps[0].Attachments[0]
+	if attachment.Name != "orders.csv" || attachment.ContentType != "text/csv" || attachment.Size != 5 {
+		t.Errorf("attachment = %+v", attachment)
+	}
+
+	rc, err := store.Get(context.Background(), attachment.Ref)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != "id\n1\n" {
+		t.Errorf("attachment content = %q", data)
+	}
+}
+
+func TestAddAttachment_Errors(t *testing.T) {
+	if _, err := AddAttachment(context.Background(), "a.txt", "text/plain", strings.NewReader("a")); !errors.Is(err, ErrNoWorkflowContext) {
+		t.Errorf("AddAttachment() outside a step error = %v, want ErrNoWorkflowContext", err)
+	}
+
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	var attachErr error
+	workflow, _ := NewWorkflowBuilder("no-store", "No Store").
+		AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			_, attachErr = AddAttachment(ctx, "a.txt", "text/plain", strings.NewReader("a"))
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	orchestrator.StartWorkflow(context.Background(), "no-store", nil, nil)
+
+	if !errors.Is(attachErr, ErrNoBlobStore) {
+		t.Errorf("AddAttachment() without a blob store error = %v, want ErrNoBlobStore", attachErr)
+	}
+}
@@ -0,0 +1,56 @@
+package orchwf
+
+import (
+	"context"
+	"time"
+)
+
+// RequestBackpressure asks the orchestrator to wait at least d before starting the
+// next wave of the running workflow, so a step feeding a bounded consumer can throttle
+// the DAG without failing. When several steps of a wave ask, the longest delay wins.
+// It returns ErrNoWorkflowContext when ctx does not come from a step.
+func RequestBackpressure(ctx context.Context, d time.Duration) error {
+	run, ok := ctx.Value(workflowRunKey).(*workflowRun)
+	if !ok {
+		return ErrNoWorkflowContext
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if d > run.backpressure {
+		run.backpressure = d
+	}
+	return nil
+}
+
+// takeBackpressure returns the delay requested during the wave that just finished and
+// clears it for the next one
+func takeBackpressure(ctx context.Context) time.Duration {
+	run, ok := ctx.Value(workflowRunKey).(*workflowRun)
+	if !ok {
+		return 0
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	d := run.backpressure
+	run.backpressure = 0
+	return d
+}
+
+// applyBackpressure delays the next wave by the backpressure steps requested. The wait
+// ends early when ctx is done, so a long request cannot hold up cancellation.
+func (o *Orchestrator) applyBackpressure(ctx context.Context, instance *WorkflowInstance) error {
+	delay := takeBackpressure(ctx)
+	if delay <= 0 {
+		return nil
+	}
+
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowBackpressure, map[string]interface{}{
+		"delay": delay.String(),
+	})
+	leaveGate := o.waitAtGate(instance.ID, stepGate{blocker: StepBlockerBackpressure})
+	err := o.sleep(ctx, delay)
+	leaveGate()
+	return err
+}
@@ -0,0 +1,95 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_Backpressure(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	const delay = 100 * time.Millisecond
+	var producedAt, consumedAt time.Time
+	workflow, err := NewWorkflowBuilder("backpressure", "Backpressure").
+		AddStepFunc("produce", "Produce", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			// The shorter request from the same wave is overridden by the longer one
+			if err := RequestBackpressure(ctx, delay/2); err != nil {
+				return nil, err
+			}
+			if err := RequestBackpressure(ctx, delay); err != nil {
+				return nil, err
+			}
+			producedAt = time.Now()
+			return map[string]interface{}{}, nil
+		}).
+		AddStepFunc("consume", "Consume", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			consumedAt = time.Now()
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("produce")).
+		AddStepFunc("finish", "Finish", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("consume")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	start := time.Now()
+	result, err := orchestrator.StartWorkflow(context.Background(), "backpressure", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	if waited := consumedAt.Sub(producedAt); waited < delay {
+		t.Errorf("next wave started %v after backpressure was requested, want at least %v", waited, delay)
+	}
+	// The request applies to one wave only
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Errorf("workflow took %v, want backpressure cleared after one wave", elapsed)
+	}
+}
+
+func TestOrchestrator_CancelDuringBackpressure(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	requested := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("slow-consumer", "Slow Consumer").
+		AddStepFunc("produce", "Produce", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			defer close(requested)
+			return map[string]interface{}{}, RequestBackpressure(ctx, time.Hour)
+		}).
+		AddStepFunc("consume", "Consume", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("produce")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	instanceID, err := orchestrator.StartWorkflowAsync(ctx, "slow-consumer", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	<-requested
+
+	begin := time.Now()
+	result, err := orchestrator.CancelWorkflow(ctx, instanceID)
+	if err != nil {
+		t.Fatalf("CancelWorkflow() error = %v, want the hour of backpressure cut short", err)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusCancelled {
+		t.Errorf("status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusCancelled)
+	}
+	if waited := time.Since(begin); waited > time.Second {
+		t.Errorf("CancelWorkflow() returned after %v, want promptly", waited)
+	}
+}
+
+func TestRequestBackpressure_OutsideStep(t *testing.T) {
+	if err := RequestBackpressure(context.Background(), time.Second); !errors.Is(err, ErrNoWorkflowContext) {
+		t.Errorf("RequestBackpressure() error = %v, want %v", err, ErrNoWorkflowContext)
+	}
+}
@@ -2,12 +2,15 @@ package orchwf
 
 import (
 	"fmt"
+	"sort"
 	"time"
+	"unicode"
 )
 
 // WorkflowBuilder helps build workflow definitions
 type WorkflowBuilder struct {
 	workflow *WorkflowDefinition
+	err      error // First error from an inline step build, surfaced by Build
 }
 
 // NewWorkflowBuilder creates a new workflow builder
@@ -41,14 +44,235 @@ func (b *WorkflowBuilder) WithMetadata(key string, value interface{}) *WorkflowB
 	return b
 }
 
+// WithFailurePolicy sets what happens to the rest of the workflow when a required step fails
+func (b *WorkflowBuilder) WithFailurePolicy(policy FailurePolicy) *WorkflowBuilder {
+	b.workflow.FailurePolicy = policy
+	return b
+}
+
+// WithPersistPolicy sets how often step state is saved while the workflow runs, trading
+// durability for fewer state manager writes
+func (b *WorkflowBuilder) WithPersistPolicy(policy PersistPolicy) *WorkflowBuilder {
+	b.workflow.PersistPolicy = policy
+	return b
+}
+
+// WithCompensateOnCancel makes CancelWorkflow compensate the completed steps, most recent
+// first, before the instance is marked cancelled
+func (b *WorkflowBuilder) WithCompensateOnCancel(enabled bool) *WorkflowBuilder {
+	b.workflow.CompensateOnCancel = enabled
+	return b
+}
+
+// WithStrictOrdering runs every step one at a time, async steps included, for workflows
+// that must not run steps in parallel. Once the steps finish, their recorded start and
+// completion times are checked, and the workflow fails with ErrStrictOrderViolated if
+// any two overlapped.
+func (b *WorkflowBuilder) WithStrictOrdering(strict bool) *WorkflowBuilder {
+	b.workflow.StrictOrdering = strict
+	return b
+}
+
+// WithTreatOptionalFailureAsWorkflowFailure fails the workflow once its steps are done if any
+// non-required step failed or timed out and was skipped, with an ErrOptionalStepsFailed
+// error naming them. Dependents of those steps still run first, as they do by default.
+func (b *WorkflowBuilder) WithTreatOptionalFailureAsWorkflowFailure(enabled bool) *WorkflowBuilder {
+	b.workflow.FailOnOptionalFailure = enabled
+	return b
+}
+
+// WithInputDefaults sets input values used when the caller does not supply them
+func (b *WorkflowBuilder) WithInputDefaults(defaults map[string]interface{}) *WorkflowBuilder {
+	b.workflow.InputDefaults = defaults
+	return b
+}
+
+// WithOverlapAsync starts the async steps of each wave before its sync steps so they overlap,
+// instead of waiting for the sync steps to finish
+func (b *WorkflowBuilder) WithOverlapAsync(overlap bool) *WorkflowBuilder {
+	if overlap {
+		b.workflow.WaveStrategy = WaveStrategyOverlap
+	} else {
+		b.workflow.WaveStrategy = WaveStrategySequential
+	}
+	return b
+}
+
+// WithMaxConcurrentSteps caps how many steps of this workflow run at once, summed over
+// all of its instances, so a resource-heavy workflow limits itself whatever the
+// orchestrator's configuration
+func (b *WorkflowBuilder) WithMaxConcurrentSteps(n int) *WorkflowBuilder {
+	b.workflow.MaxConcurrentSteps = n
+	return b
+}
+
+// WithPriority sets the workflow priority (higher number = higher priority). Instances
+// queued under WithMaxInFlight take free slots in priority order, FIFO within a priority.
+func (b *WorkflowBuilder) WithPriority(priority int) *WorkflowBuilder {
+	b.workflow.Priority = priority
+	return b
+}
+
+// WithDeepMerge merges nested map values of step outputs recursively into the workflow
+// context and output, so steps writing into the same top-level key keep each other's
+// keys. By default a later step replaces the whole value.
+func (b *WorkflowBuilder) WithDeepMerge(enabled bool) *WorkflowBuilder {
+	b.workflow.DeepMerge = enabled
+	return b
+}
+
+// WithOutputMapping builds the output of a completed workflow from its step outputs, keyed by
+// step ID, instead of merging the output keys of every step together
+func (b *WorkflowBuilder) WithOutputMapping(fn OutputMapper) *WorkflowBuilder {
+	b.workflow.OutputMapping = fn
+	return b
+}
+
+// WithRedactedKeys masks the values of the given keys, at any depth, in the inputs,
+// outputs and event payloads that are persisted. Executors still see the real values.
+// A resumed instance reads its input back from the state manager, so it sees the masks.
+func (b *WorkflowBuilder) WithRedactedKeys(keys ...string) *WorkflowBuilder {
+	b.workflow.RedactedKeys = append(b.workflow.RedactedKeys, keys...)
+	return b
+}
+
 // AddStep adds a step to the workflow
 func (b *WorkflowBuilder) AddStep(step *StepDefinition) *WorkflowBuilder {
 	b.workflow.Steps = append(b.workflow.Steps, step)
 	return b
 }
 
+// AddStepAfter adds a step that depends on a previously added step.
+// An unknown predecessor is recorded and returned by Build.
+func (b *WorkflowBuilder) AddStepAfter(step *StepDefinition, afterStepID string) *WorkflowBuilder {
+	if !b.hasStep(afterStepID) {
+		if b.err == nil {
+			b.err = fmt.Errorf("step %s added after unknown step: %s", step.ID, afterStepID)
+		}
+		return b
+	}
+
+	if !containsString(step.Dependencies, afterStepID) {
+		step.Dependencies = append(step.Dependencies, afterStepID)
+	}
+	return b.AddStep(step)
+}
+
+// ThenStep adds a step that depends on the last added step, for building linear chains.
+// When no step has been added yet it behaves like AddStep.
+func (b *WorkflowBuilder) ThenStep(step *StepDefinition) *WorkflowBuilder {
+	if len(b.workflow.Steps) == 0 {
+		return b.AddStep(step)
+	}
+	return b.AddStepAfter(step, b.workflow.Steps[len(b.workflow.Steps)-1].ID)
+}
+
+// hasStep reports whether a step with the given ID has been added
+func (b *WorkflowBuilder) hasStep(stepID string) bool {
+	for _, step := range b.workflow.Steps {
+		if step.ID == stepID {
+			return true
+		}
+	}
+	return false
+}
+
+// WithGateStep adds a step that runs before every other step, such as a cheap input
+// validation. Every other step implicitly depends on it, so when it fails the workflow
+// fails before anything else runs, async steps included. The gate is always required
+// and cannot have dependencies itself.
+func (b *WorkflowBuilder) WithGateStep(step *StepDefinition) *WorkflowBuilder {
+	if b.workflow.GateStep != "" {
+		if b.err == nil {
+			b.err = fmt.Errorf("gate step %s conflicts with gate step %s", step.ID, b.workflow.GateStep)
+		}
+		return b
+	}
+
+	step.Required = true
+	b.workflow.GateStep = step.ID
+	return b.AddStep(step)
+}
+
+// WithFinalizer sets a step that always runs after the other steps complete or fail.
+// The finalizer can read the workflow failure with WorkflowErrorFromContext.
+func (b *WorkflowBuilder) WithFinalizer(step *StepDefinition) *WorkflowBuilder {
+	b.workflow.Finalizer = step
+	return b
+}
+
+// WithSuccessStep marks a step whose completion ends the workflow early: no further
+// steps are scheduled and the rest are skipped. It can be called for several steps,
+// for example a chain of optional fallback providers.
+func (b *WorkflowBuilder) WithSuccessStep(stepID string) *WorkflowBuilder {
+	b.workflow.SuccessSteps = append(b.workflow.SuccessSteps, stepID)
+	return b
+}
+
+// AddAlternatives adds a group step that tries the given steps in priority order
+// (higher first, then in the order given) until one succeeds. The winning step's output
+// becomes the group's output, so dependents read it under the group name. The rest are
+// skipped. The group depends on the union of the alternatives' dependencies. It is
+// required unless every alternative is marked not required, and it fails only when all
+// alternatives fail.
+func (b *WorkflowBuilder) AddAlternatives(name string, steps ...*StepDefinition) *WorkflowBuilder {
+	if len(steps) == 0 {
+		if b.err == nil {
+			b.err = fmt.Errorf("alternatives %s must have at least one step", name)
+		}
+		return b
+	}
+
+	alternatives := make([]*StepDefinition, len(steps))
+	copy(alternatives, steps)
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return alternatives[i].Priority > alternatives[j].Priority
+	})
+
+	group := &StepDefinition{
+		ID:           name,
+		Name:         name,
+		Dependencies: make([]string, 0),
+		Alternatives: alternatives,
+	}
+	for _, step := range alternatives {
+		for _, dep := range step.Dependencies {
+			if !containsString(group.Dependencies, dep) {
+				group.Dependencies = append(group.Dependencies, dep)
+			}
+		}
+		if step.Required {
+			group.Required = true
+		}
+	}
+
+	return b.AddStep(group)
+}
+
+// AddStepFunc builds a step inline and adds it to the workflow.
+// Any step build error is recorded and returned by Build.
+func (b *WorkflowBuilder) AddStepFunc(id, name string, executor StepExecutor, opts ...StepOption) *WorkflowBuilder {
+	stepBuilder := NewStepBuilder(id, name, executor)
+	for _, opt := range opts {
+		opt(stepBuilder)
+	}
+
+	step, err := stepBuilder.Build()
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("failed to build step %s: %w", id, err)
+		}
+		return b
+	}
+
+	return b.AddStep(step)
+}
+
 // Build returns the workflow definition
 func (b *WorkflowBuilder) Build() (*WorkflowDefinition, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
 	if b.workflow.ID == "" {
 		return nil, fmt.Errorf("workflow ID is required")
 	}
@@ -58,6 +282,26 @@ func (b *WorkflowBuilder) Build() (*WorkflowDefinition, error) {
 	if len(b.workflow.Steps) == 0 {
 		return nil, fmt.Errorf("workflow must have at least one step")
 	}
+	if b.workflow.MaxConcurrentSteps < 0 {
+		return nil, fmt.Errorf("max concurrent steps cannot be negative: %d", b.workflow.MaxConcurrentSteps)
+	}
+
+	// Steps built without StepBuilder still need safe IDs
+	for _, step := range b.workflow.Steps {
+		if err := validateStepID(step.ID); err != nil {
+			return nil, err
+		}
+		for _, alt := range step.Alternatives {
+			if err := validateStepID(alt.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if finalizer := b.workflow.Finalizer; finalizer != nil {
+		if err := validateStepID(finalizer.ID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Validate dependencies
 	stepIDs := make(map[string]bool)
@@ -73,6 +317,39 @@ func (b *WorkflowBuilder) Build() (*WorkflowDefinition, error) {
 		}
 	}
 
+	// Alternatives run inside their group and must not clash with other step IDs
+	for _, step := range b.workflow.Steps {
+		for _, alt := range step.Alternatives {
+			if stepIDs[alt.ID] {
+				return nil, fmt.Errorf("alternative %s of %s conflicts with another step of the same ID", alt.ID, step.ID)
+			}
+			stepIDs[alt.ID] = true
+		}
+	}
+
+	// The gate runs first, so it cannot wait on other steps
+	for _, step := range b.workflow.Steps {
+		if step.ID == b.workflow.GateStep && len(step.Dependencies) > 0 {
+			return nil, fmt.Errorf("gate step %s cannot have dependencies", step.ID)
+		}
+	}
+
+	for _, stepID := range b.workflow.SuccessSteps {
+		if !stepIDs[stepID] {
+			return nil, fmt.Errorf("success step %s is not a step of the workflow", stepID)
+		}
+	}
+
+	// Validate finalizer
+	if finalizer := b.workflow.Finalizer; finalizer != nil {
+		if stepIDs[finalizer.ID] {
+			return nil, fmt.Errorf("finalizer %s conflicts with a step of the same ID", finalizer.ID)
+		}
+		if len(finalizer.Dependencies) > 0 {
+			return nil, fmt.Errorf("finalizer %s cannot have dependencies", finalizer.ID)
+		}
+	}
+
 	return b.workflow, nil
 }
 
@@ -119,12 +396,49 @@ func (b *StepBuilder) WithRetryPolicy(policy *RetryPolicy) *StepBuilder {
 	return b
 }
 
-// WithTimeout sets the step timeout
+// WithTimeout sets the step's time budget. It covers every attempt and the backoff
+// between them; each attempt's context expires when the budget does, and no new
+// attempt starts once it is spent.
 func (b *StepBuilder) WithTimeout(timeout time.Duration) *StepBuilder {
 	b.step.Timeout = timeout
 	return b
 }
 
+// WithMaxDuration caps the step's wall-clock time across all attempts and backoffs,
+// counted from the start of its first attempt. An attempt is cancelled when the cap runs
+// out, and no retry starts whose backoff would end past it. A step resumed mid-retry
+// keeps counting from its original start.
+func (b *StepBuilder) WithMaxDuration(d time.Duration) *StepBuilder {
+	b.step.MaxDuration = d
+	return b
+}
+
+// WithTags adds tags that group the step with similar steps across workflows, such as
+// "external-call" or "db-write". They are reported as the "tags" label of step metrics,
+// in step event payloads and on timeline entries. Tags belong to the definition, so they
+// keep metric cardinality bounded; do not build them from input values.
+func (b *StepBuilder) WithTags(tags ...string) *StepBuilder {
+	for _, tag := range tags {
+		if !containsString(b.step.Tags, tag) {
+			b.step.Tags = append(b.step.Tags, tag)
+		}
+	}
+	return b
+}
+
+// WithRequiredOutputKeys lists keys the step's output must hold with non-nil values, as
+// a lightweight alternative to a full schema. An attempt whose output lacks one fails with
+// ErrMissingOutputKeys and is retried under the step's retry policy, so a dependent never
+// reads a field the executor forgot to set.
+func (b *StepBuilder) WithRequiredOutputKeys(keys ...string) *StepBuilder {
+	for _, key := range keys {
+		if !containsString(b.step.RequiredOutputKeys, key) {
+			b.step.RequiredOutputKeys = append(b.step.RequiredOutputKeys, key)
+		}
+	}
+	return b
+}
+
 // WithRequired sets whether the step is required
 func (b *StepBuilder) WithRequired(required bool) *StepBuilder {
 	b.step.Required = required
@@ -137,27 +451,218 @@ func (b *StepBuilder) WithAsync(async bool) *StepBuilder {
 	return b
 }
 
+// WithExecutorKey records the ExecutorRegistry key of the step's executor, so an exported
+// definition can be imported again. Without one, exports use the step ID.
+func (b *StepBuilder) WithExecutorKey(key string) *StepBuilder {
+	b.step.ExecutorKey = key
+	return b
+}
+
 // WithPriority sets the step priority (higher number = higher priority)
 func (b *StepBuilder) WithPriority(priority int) *StepBuilder {
 	b.step.Priority = priority
 	return b
 }
 
+// WithTimerUntil makes the step wait until the time returned by fn before executing.
+// The wake time is persisted, so the wait survives restarts.
+func (b *StepBuilder) WithTimerUntil(fn StepTimer) *StepBuilder {
+	b.step.TimerUntil = fn
+	return b
+}
+
+// WithDeadlineFromInput makes the step read an absolute deadline from the given input key.
+// The value may be a time.Time or an RFC3339 string; the step fails if the deadline has passed.
+func (b *StepBuilder) WithDeadlineFromInput(key string) *StepBuilder {
+	b.step.DeadlineKey = key
+	return b
+}
+
+// WithResource makes the step hold weight units of the named resource pool while it runs.
+// The pool must be registered on the orchestrator with WithResourcePool.
+func (b *StepBuilder) WithResource(name string, weight int) *StepBuilder {
+	if weight < 1 {
+		weight = 1
+	}
+	b.step.Resource = name
+	b.step.ResourceWeight = weight
+	return b
+}
+
+// WithFanIn collects the outputs of the step's dependencies, in dependency order,
+// as a []map[string]interface{} under the step's own ID. Read them with FanInOutputs.
+func (b *StepBuilder) WithFanIn() *StepBuilder {
+	b.step.FanIn = true
+	return b
+}
+
+// WithReducer enables fan-in and folds the collected dependency outputs with fn.
+// The reducer's result is merged into the step input.
+func (b *StepBuilder) WithReducer(fn StepReducer) *StepBuilder {
+	b.step.FanIn = true
+	b.step.Reducer = fn
+	return b
+}
+
+// WithRetryableOutput retries an attempt that returned no error when fn reports
+// that its output asks for a retry, such as an API answering 200 with "try again later".
+// The retry policy's MaxAttempts still applies; the last attempt's output is kept.
+func (b *StepBuilder) WithRetryableOutput(fn OutputPredicate) *StepBuilder {
+	b.step.RetryableOutput = fn
+	return b
+}
+
+// WithInputInterceptor sets a function called before every attempt with a copy of the
+// prepared, dependency-merged input. Its result is what the executor receives, so it can
+// inject secrets or values that must be fresh per attempt. They are not persisted or
+// traced. An error fails the attempt, which is retried like an executor error.
+func (b *StepBuilder) WithInputInterceptor(fn InputInterceptor) *StepBuilder {
+	b.step.InputInterceptor = fn
+	return b
+}
+
+// WithDynamicDependencies sets a function that resolves the step's dependencies from
+// the workflow input once, when the workflow starts. Its result replaces the static
+// dependencies; unknown step IDs fail the workflow.
+func (b *StepBuilder) WithDynamicDependencies(fn DependencyResolver) *StepBuilder {
+	b.step.DynamicDependencies = fn
+	return b
+}
+
+// WithAdvancedExecutor sets an executor that receives a StepContext, with read-only access to
+// the outputs of steps outside the step's dependencies, in place of the plain executor.
+// Advanced executors always run in process, whatever the transport.
+func (b *StepBuilder) WithAdvancedExecutor(fn AdvancedExecutor) *StepBuilder {
+	b.step.AdvancedExecutor = fn
+	return b
+}
+
 // Build returns the step definition
 func (b *StepBuilder) Build() (*StepDefinition, error) {
 	if b.step.ID == "" {
 		return nil, fmt.Errorf("step ID is required")
 	}
+	if err := validateStepID(b.step.ID); err != nil {
+		return nil, err
+	}
 	if b.step.Name == "" {
 		return nil, fmt.Errorf("step name is required")
 	}
-	if b.step.Executor == nil {
+	if b.step.Executor == nil && b.step.AdvancedExecutor == nil {
 		return nil, fmt.Errorf("step executor is required")
 	}
 
 	return b.step, nil
 }
 
+// StepOption configures a step built with WorkflowBuilder.AddStepFunc
+type StepOption func(*StepBuilder)
+
+// WithStepDescription sets the step description
+func WithStepDescription(description string) StepOption {
+	return func(b *StepBuilder) { b.WithDescription(description) }
+}
+
+// WithStepDeps sets the step dependencies
+func WithStepDeps(dependencies ...string) StepOption {
+	return func(b *StepBuilder) { b.WithDependencies(dependencies...) }
+}
+
+// WithStepCompensator sets the step compensator
+func WithStepCompensator(compensator StepCompensator) StepOption {
+	return func(b *StepBuilder) { b.WithCompensator(compensator) }
+}
+
+// WithStepRetryPolicy sets the step retry policy
+func WithStepRetryPolicy(policy *RetryPolicy) StepOption {
+	return func(b *StepBuilder) { b.WithRetryPolicy(policy) }
+}
+
+// WithStepTimeout sets the step timeout
+func WithStepTimeout(timeout time.Duration) StepOption {
+	return func(b *StepBuilder) { b.WithTimeout(timeout) }
+}
+
+// WithStepMaxDuration sets the step's wall-clock cap across retries
+func WithStepMaxDuration(d time.Duration) StepOption {
+	return func(b *StepBuilder) { b.WithMaxDuration(d) }
+}
+
+// WithStepTags adds tags that group the step in metrics, events and timelines
+func WithStepTags(tags ...string) StepOption {
+	return func(b *StepBuilder) { b.WithTags(tags...) }
+}
+
+// WithStepRequired sets whether the step is required
+func WithStepRequired(required bool) StepOption {
+	return func(b *StepBuilder) { b.WithRequired(required) }
+}
+
+// WithStepAsync sets whether the step is async
+func WithStepAsync(async bool) StepOption {
+	return func(b *StepBuilder) { b.WithAsync(async) }
+}
+
+// WithStepExecutorKey records the ExecutorRegistry key of the step's executor
+func WithStepExecutorKey(key string) StepOption {
+	return func(b *StepBuilder) { b.WithExecutorKey(key) }
+}
+
+// WithStepPriority sets the step priority (higher number = higher priority)
+func WithStepPriority(priority int) StepOption {
+	return func(b *StepBuilder) { b.WithPriority(priority) }
+}
+
+// WithStepTimerUntil makes the step wait until the time returned by fn
+func WithStepTimerUntil(fn StepTimer) StepOption {
+	return func(b *StepBuilder) { b.WithTimerUntil(fn) }
+}
+
+// WithStepDeadlineFromInput sets the input key holding the step's absolute deadline
+func WithStepDeadlineFromInput(key string) StepOption {
+	return func(b *StepBuilder) { b.WithDeadlineFromInput(key) }
+}
+
+// WithStepResource makes the step hold weight units of the named resource pool
+func WithStepResource(name string, weight int) StepOption {
+	return func(b *StepBuilder) { b.WithResource(name, weight) }
+}
+
+// WithStepFanIn collects dependency outputs as a list under the step's ID
+func WithStepFanIn() StepOption {
+	return func(b *StepBuilder) { b.WithFanIn() }
+}
+
+// WithStepReducer folds the collected dependency outputs with fn
+func WithStepReducer(fn StepReducer) StepOption {
+	return func(b *StepBuilder) { b.WithReducer(fn) }
+}
+
+// WithStepRetryableOutput retries successful attempts whose output matches fn
+func WithStepRetryableOutput(fn OutputPredicate) StepOption {
+	return func(b *StepBuilder) { b.WithRetryableOutput(fn) }
+}
+
+// WithStepDynamicDependencies sets a function that resolves the step's dependencies from the workflow input
+func WithStepDynamicDependencies(fn DependencyResolver) StepOption {
+	return func(b *StepBuilder) { b.WithDynamicDependencies(fn) }
+}
+
+// WithStepInputInterceptor sets a function that rewrites the step input before every attempt
+func WithStepInputInterceptor(fn InputInterceptor) StepOption {
+	return func(b *StepBuilder) { b.WithInputInterceptor(fn) }
+}
+
+// WithStepAdvancedExecutor sets an executor that receives a StepContext; pass a nil executor to AddStepFunc
+func WithStepAdvancedExecutor(fn AdvancedExecutor) StepOption {
+	return func(b *StepBuilder) { b.WithAdvancedExecutor(fn) }
+}
+
+// WithStepRequiredOutputKeys fails attempts whose output lacks any of the keys
+func WithStepRequiredOutputKeys(keys ...string) StepOption {
+	return func(b *StepBuilder) { b.WithRequiredOutputKeys(keys...) }
+}
+
 // RetryPolicyBuilder helps build retry policies
 type RetryPolicyBuilder struct {
 	policy *RetryPolicy
@@ -176,6 +681,17 @@ func NewRetryPolicyBuilder() *RetryPolicyBuilder {
 	}
 }
 
+// From starts the builder from a copy of a preset, so the With methods override its values
+func (b *RetryPolicyBuilder) From(preset *RetryPolicy) *RetryPolicyBuilder {
+	if preset == nil {
+		return b
+	}
+	policy := *preset
+	policy.RetryableErrors = append(make([]string, 0, len(preset.RetryableErrors)), preset.RetryableErrors...)
+	b.policy = &policy
+	return b
+}
+
 // WithMaxAttempts sets the maximum number of retry attempts
 func (b *RetryPolicyBuilder) WithMaxAttempts(attempts int) *RetryPolicyBuilder {
 	b.policy.MaxAttempts = attempts
@@ -206,7 +722,70 @@ func (b *RetryPolicyBuilder) WithRetryableErrors(errors ...string) *RetryPolicyB
 	return b
 }
 
-// Build returns the retry policy
-func (b *RetryPolicyBuilder) Build() *RetryPolicy {
-	return b.policy
+// Build validates and returns the retry policy
+func (b *RetryPolicyBuilder) Build() (*RetryPolicy, error) {
+	if err := b.policy.Validate(); err != nil {
+		return nil, err
+	}
+	return b.policy, nil
+}
+
+// MustBuild is like Build but panics if the policy is invalid
+func (b *RetryPolicyBuilder) MustBuild() *RetryPolicy {
+	policy, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// AggressiveRetry returns a policy for cheap, fast-recovering calls: 10 attempts starting
+// at 100ms, growing 1.5x per attempt up to 5s
+func AggressiveRetry() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     10,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      1.5,
+		RetryableErrors: make([]string, 0),
+	}
+}
+
+// ConservativeRetry returns a policy for expensive or rate-limited calls: 3 attempts
+// starting at 5s, tripling per attempt up to 1m
+func ConservativeRetry() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     1 * time.Minute,
+		Multiplier:      3.0,
+		RetryableErrors: make([]string, 0),
+	}
+}
+
+// NetworkRetry returns a policy for remote calls: 5 attempts starting at 500ms, doubling
+// per attempt up to 30s, with the common transient network errors as retryable errors
+func NetworkRetry() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2.0,
+		RetryableErrors: []string{"connection refused", "connection reset", "broken pipe", "timeout", "unexpected EOF"},
+	}
+}
+
+// validateStepID checks that a step ID is a safe identifier made of letters, digits, '_' and '-'.
+// IDs key step outputs and are referenced by dependencies and dot-paths, where spaces or dots
+// would make them ambiguous.
+func validateStepID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: step ID is required", ErrInvalidStepID)
+	}
+	for _, r := range id {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return fmt.Errorf("%w: %q contains %q, use only letters, digits, '_' and '-'", ErrInvalidStepID, id, r)
+		}
+	}
+	return nil
 }
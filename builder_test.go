@@ -2,6 +2,8 @@ package orchwf
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -51,6 +53,15 @@ func TestWorkflowBuilder_WithMetadata(t *testing.T) {
 	}
 }
 
+func TestWorkflowBuilder_WithFailurePolicy(t *testing.T) {
+	builder := NewWorkflowBuilder("test-workflow", "Test Workflow")
+	builder.WithFailurePolicy(FailurePolicyCompleteWave)
+
+	if builder.workflow.FailurePolicy != FailurePolicyCompleteWave {
+		t.Errorf("WithFailurePolicy() = %v, want %v", builder.workflow.FailurePolicy, FailurePolicyCompleteWave)
+	}
+}
+
 func TestWorkflowBuilder_AddStep(t *testing.T) {
 	builder := NewWorkflowBuilder("test-workflow", "Test Workflow")
 
@@ -150,6 +161,65 @@ func TestWorkflowBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestWorkflowBuilder_AddStepFunc(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"result": "success"}, nil
+	}
+	policy := NewRetryPolicyBuilder().WithMaxAttempts(2).MustBuild()
+
+	workflow, err := NewWorkflowBuilder("test-workflow", "Test Workflow").
+		AddStepFunc("step1", "Step 1", executor).
+		AddStepFunc("step2", "Step 2", executor,
+			WithStepDeps("step1"),
+			WithStepTimeout(5*time.Second),
+			WithStepRetryPolicy(policy),
+			WithStepRequired(false),
+			WithStepAsync(true),
+			WithStepPriority(7),
+			WithStepDescription("Second step"),
+		).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(workflow.Steps) != 2 {
+		t.Fatalf("AddStepFunc() steps length = %v, want %v", len(workflow.Steps), 2)
+	}
+
+	step := workflow.Steps[1]
+	if len(step.Dependencies) != 1 || step.Dependencies[0] != "step1" {
+		t.Errorf("WithStepDeps() = %v, want [step1]", step.Dependencies)
+	}
+	if step.Timeout != 5*time.Second {
+		t.Errorf("WithStepTimeout() = %v, want %v", step.Timeout, 5*time.Second)
+	}
+	if step.RetryPolicy != policy {
+		t.Errorf("WithStepRetryPolicy() = %v, want %v", step.RetryPolicy, policy)
+	}
+	if step.Required {
+		t.Errorf("WithStepRequired() = %v, want %v", step.Required, false)
+	}
+	if !step.Async {
+		t.Errorf("WithStepAsync() = %v, want %v", step.Async, true)
+	}
+	if step.Priority != 7 {
+		t.Errorf("WithStepPriority() = %v, want %v", step.Priority, 7)
+	}
+	if step.Description != "Second step" {
+		t.Errorf("WithStepDescription() = %v, want %v", step.Description, "Second step")
+	}
+
+	// Step build errors surface at Build
+	_, err = NewWorkflowBuilder("test-workflow", "Test Workflow").
+		AddStepFunc("step1", "Step 1", executor).
+		AddStepFunc("step2", "Step 2", nil).
+		Build()
+	if err == nil {
+		t.Errorf("Build() should return error when an inline step is invalid")
+	}
+}
+
 func TestStepBuilder_NewStepBuilder(t *testing.T) {
 	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
 		return map[string]interface{}{"result": "success"}, nil
@@ -282,6 +352,32 @@ func TestStepBuilder_WithAsync(t *testing.T) {
 	}
 }
 
+func TestStepBuilder_WithTimerUntil(t *testing.T) {
+	wakeAt := time.Now().Add(time.Hour)
+	builder := NewStepBuilder("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	builder.WithTimerUntil(func(input map[string]interface{}) time.Time { return wakeAt })
+
+	if builder.step.TimerUntil == nil {
+		t.Fatalf("WithTimerUntil() did not set timer")
+	}
+	if got := builder.step.TimerUntil(nil); !got.Equal(wakeAt) {
+		t.Errorf("WithTimerUntil() timer = %v, want %v", got, wakeAt)
+	}
+}
+
+func TestStepBuilder_WithDeadlineFromInput(t *testing.T) {
+	builder := NewStepBuilder("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	builder.WithDeadlineFromInput("ship_by")
+
+	if builder.step.DeadlineKey != "ship_by" {
+		t.Errorf("WithDeadlineFromInput() key = %v, want %v", builder.step.DeadlineKey, "ship_by")
+	}
+}
+
 func TestStepBuilder_Build(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -345,6 +441,60 @@ func TestStepBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestStepBuilder_BuildValidatesID(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"fetch_user", false},
+		{"fetch-user-2", false},
+		{"FetchUser", false},
+		{"fetch user", true},
+		{"fetch.user", true},
+		{"fetch\tuser", true},
+		{"items[0]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			_, err := NewStepBuilder(tt.id, "Step", executor).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidStepID) {
+				t.Errorf("Build() error = %v, want ErrInvalidStepID", err)
+			}
+		})
+	}
+}
+
+func TestWorkflowBuilder_BuildValidatesStepIDs(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	// Definitions added directly bypass StepBuilder, so the workflow checks them too
+	_, err := NewWorkflowBuilder("ids", "IDs").
+		AddStep(&StepDefinition{ID: "fetch user", Name: "Fetch User", Executor: executor}).
+		Build()
+	if !errors.Is(err, ErrInvalidStepID) {
+		t.Errorf("Build() error = %v, want ErrInvalidStepID for a step", err)
+	}
+
+	valid, _ := NewStepBuilder("notify", "Notify", executor).Build()
+	_, err = NewWorkflowBuilder("ids", "IDs").
+		AddStep(valid).
+		WithFinalizer(&StepDefinition{ID: "clean.up", Name: "Clean Up", Executor: executor}).
+		Build()
+	if !errors.Is(err, ErrInvalidStepID) {
+		t.Errorf("Build() error = %v, want ErrInvalidStepID for the finalizer", err)
+	}
+}
+
 func TestRetryPolicyBuilder_NewRetryPolicyBuilder(t *testing.T) {
 	builder := NewRetryPolicyBuilder()
 
@@ -415,7 +565,10 @@ func TestRetryPolicyBuilder_WithRetryableErrors(t *testing.T) {
 
 func TestRetryPolicyBuilder_Build(t *testing.T) {
 	builder := NewRetryPolicyBuilder()
-	policy := builder.Build()
+	policy, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
 
 	if policy == nil {
 		t.Errorf("Build() returned nil policy")
@@ -424,3 +577,226 @@ func TestRetryPolicyBuilder_Build(t *testing.T) {
 		t.Errorf("Build() MaxAttempts = %v, want %v", policy.MaxAttempts, 3)
 	}
 }
+
+func TestRetryPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset *RetryPolicy
+		want   RetryPolicy
+	}{
+		{"aggressive", AggressiveRetry(), RetryPolicy{MaxAttempts: 10, InitialInterval: 100 * time.Millisecond, MaxInterval: 5 * time.Second, Multiplier: 1.5, RetryableErrors: []string{}}},
+		{"conservative", ConservativeRetry(), RetryPolicy{MaxAttempts: 3, InitialInterval: 5 * time.Second, MaxInterval: time.Minute, Multiplier: 3.0, RetryableErrors: []string{}}},
+		{"network", NetworkRetry(), RetryPolicy{MaxAttempts: 5, InitialInterval: 500 * time.Millisecond, MaxInterval: 30 * time.Second, Multiplier: 2.0,
+			RetryableErrors: []string{"connection refused", "connection reset", "broken pipe", "timeout", "unexpected EOF"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(*tt.preset, tt.want) {
+				t.Errorf("preset = %+v, want %+v", *tt.preset, tt.want)
+			}
+			if err := tt.preset.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBuilder_From(t *testing.T) {
+	preset := NetworkRetry()
+	policy := NewRetryPolicyBuilder().From(preset).WithMaxAttempts(8).MustBuild()
+
+	if policy.MaxAttempts != 8 || policy.InitialInterval != 500*time.Millisecond || len(policy.RetryableErrors) != 5 {
+		t.Errorf("From() policy = %+v, want NetworkRetry with 8 attempts", policy)
+	}
+
+	// The builder works on a copy, so the preset is left unchanged
+	policy.RetryableErrors[0] = "changed"
+	if preset.MaxAttempts != 5 || preset.RetryableErrors[0] != "connection refused" {
+		t.Errorf("preset = %+v, want it unchanged", preset)
+	}
+}
+
+func TestRetryPolicyBuilder_BuildRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *RetryPolicyBuilder
+	}{
+		{"zero attempts", NewRetryPolicyBuilder().WithMaxAttempts(0)},
+		{"negative initial interval", NewRetryPolicyBuilder().WithInitialInterval(-time.Second)},
+		{"negative max interval", NewRetryPolicyBuilder().WithMaxInterval(-time.Second)},
+		{"zero multiplier", NewRetryPolicyBuilder().WithMultiplier(0)},
+		{"negative multiplier", NewRetryPolicyBuilder().WithMultiplier(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := tt.builder.Build()
+			if !errors.Is(err, ErrInvalidRetryPolicy) {
+				t.Errorf("Build() error = %v, want %v", err, ErrInvalidRetryPolicy)
+			}
+			if policy != nil {
+				t.Errorf("Build() policy = %v, want nil", policy)
+			}
+		})
+	}
+
+	t.Run("MustBuild panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("MustBuild() should panic on an invalid policy")
+			}
+		}()
+		NewRetryPolicyBuilder().WithMaxAttempts(0).MustBuild()
+	})
+}
+
+func TestWorkflowBuilder_ThenStep(t *testing.T) {
+	newStep := func(id string) *StepDefinition {
+		step, _ := NewStepBuilder(id, id, func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).Build()
+		return step
+	}
+
+	workflow, err := NewWorkflowBuilder("chain", "Chain").
+		ThenStep(newStep("a")).
+		ThenStep(newStep("b")).
+		AddStepAfter(newStep("c"), "a").
+		ThenStep(newStep("d")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := map[string][]string{"a": {}, "b": {"a"}, "c": {"a"}, "d": {"c"}}
+	for _, step := range workflow.Steps {
+		if len(step.Dependencies) != len(want[step.ID]) {
+			t.Errorf("step %s dependencies = %v, want %v", step.ID, step.Dependencies, want[step.ID])
+			continue
+		}
+		for i, dep := range step.Dependencies {
+			if dep != want[step.ID][i] {
+				t.Errorf("step %s dependencies = %v, want %v", step.ID, step.Dependencies, want[step.ID])
+			}
+		}
+	}
+
+	_, err = NewWorkflowBuilder("chain", "Chain").
+		AddStep(newStep("a")).
+		AddStepAfter(newStep("b"), "missing").
+		Build()
+	if err == nil {
+		t.Errorf("Build() should fail when AddStepAfter references an unknown step")
+	}
+}
+
+func TestWorkflowBuilder_WithFinalizer(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	step, _ := NewStepBuilder("step1", "Step 1", executor).Build()
+
+	finalizer, _ := NewStepBuilder("cleanup", "Cleanup", executor).Build()
+	workflow, err := NewWorkflowBuilder("wf", "Workflow").AddStep(step).WithFinalizer(finalizer).Build()
+	if err != nil || workflow.Finalizer != finalizer {
+		t.Errorf("WithFinalizer() finalizer = %v, err = %v", workflow, err)
+	}
+
+	conflicting, _ := NewStepBuilder("step1", "Cleanup", executor).Build()
+	if _, err := NewWorkflowBuilder("wf", "Workflow").AddStep(step).WithFinalizer(conflicting).Build(); err == nil {
+		t.Errorf("Build() should reject a finalizer that reuses a step ID")
+	}
+
+	dependent, _ := NewStepBuilder("cleanup", "Cleanup", executor).WithDependencies("step1").Build()
+	if _, err := NewWorkflowBuilder("wf", "Workflow").AddStep(step).WithFinalizer(dependent).Build(); err == nil {
+		t.Errorf("Build() should reject a finalizer with dependencies")
+	}
+}
+
+func TestWorkflowBuilder_WithGateStep(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	step, _ := NewStepBuilder("step1", "Step 1", executor).Build()
+
+	gate, _ := NewStepBuilder("validate", "Validate", executor).WithRequired(false).Build()
+	workflow, err := NewWorkflowBuilder("wf", "Workflow").WithGateStep(gate).AddStep(step).Build()
+	if err != nil || workflow.GateStep != "validate" || len(workflow.Steps) != 2 {
+		t.Fatalf("WithGateStep() workflow = %v, err = %v", workflow, err)
+	}
+	if !gate.Required {
+		t.Errorf("WithGateStep() should make the gate required")
+	}
+
+	dependent, _ := NewStepBuilder("validate", "Validate", executor).WithDependencies("step1").Build()
+	if _, err := NewWorkflowBuilder("wf", "Workflow").AddStep(step).WithGateStep(dependent).Build(); err == nil {
+		t.Errorf("Build() should reject a gate step with dependencies")
+	}
+
+	other, _ := NewStepBuilder("check", "Check", executor).Build()
+	if _, err := NewWorkflowBuilder("wf", "Workflow").WithGateStep(gate).WithGateStep(other).Build(); err == nil {
+		t.Errorf("Build() should reject a second gate step")
+	}
+}
+
+func TestWorkflowBuilder_WithSuccessStep(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	workflow, err := NewWorkflowBuilder("wf", "Workflow").
+		AddStepFunc("step1", "Step 1", executor).
+		WithSuccessStep("step1").
+		Build()
+	if err != nil || len(workflow.SuccessSteps) != 1 || workflow.SuccessSteps[0] != "step1" {
+		t.Errorf("WithSuccessStep() success steps = %v, err = %v", workflow, err)
+	}
+
+	if _, err := NewWorkflowBuilder("wf", "Workflow").
+		AddStepFunc("step1", "Step 1", executor).
+		WithSuccessStep("missing").
+		Build(); err == nil {
+		t.Errorf("Build() should reject an unknown success step")
+	}
+}
+
+func TestWorkflowBuilder_AddAlternatives(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	primary, _ := NewStepBuilder("primary", "Primary", executor).WithDependencies("load").WithPriority(1).Build()
+	backup, _ := NewStepBuilder("backup", "Backup", executor).WithDependencies("load").WithRequired(false).Build()
+	load, _ := NewStepBuilder("load", "Load", executor).Build()
+
+	workflow, err := NewWorkflowBuilder("wf", "Workflow").
+		AddStep(load).
+		AddAlternatives("send", backup, primary).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	group := workflow.Steps[1]
+	if group.ID != "send" || len(group.Alternatives) != 2 {
+		t.Fatalf("AddAlternatives() group = %+v", group)
+	}
+	if group.Alternatives[0].ID != "primary" {
+		t.Errorf("alternatives should be ordered by priority, got %s first", group.Alternatives[0].ID)
+	}
+	if len(group.Dependencies) != 1 || group.Dependencies[0] != "load" {
+		t.Errorf("group dependencies = %v, want [load]", group.Dependencies)
+	}
+	if !group.Required {
+		t.Errorf("group should be required when any alternative is required")
+	}
+
+	if _, err := NewWorkflowBuilder("wf", "Workflow").AddAlternatives("send").Build(); err == nil {
+		t.Errorf("Build() should reject an empty alternatives group")
+	}
+
+	clash, _ := NewStepBuilder("load", "Load Again", executor).Build()
+	if _, err := NewWorkflowBuilder("wf", "Workflow").AddStep(load).AddAlternatives("send", clash).Build(); err == nil {
+		t.Errorf("Build() should reject an alternative that reuses a step ID")
+	}
+}
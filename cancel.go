@@ -0,0 +1,180 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// activeRun is a workflow instance executing, or waiting to execute, in this process
+type activeRun struct {
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc // Cancels the context last bound to the run
+	cause  error                   // Set once the run is stopped
+
+	done   chan struct{}   // Closed when the run returns
+	result *WorkflowResult // Set when CancelWorkflow stopped the run
+}
+
+// startRun registers a run so CancelWorkflow can stop it
+func (o *Orchestrator) startRun(workflowInstID string) *activeRun {
+	run := &activeRun{done: make(chan struct{})}
+	o.runs.Store(workflowInstID, run)
+	return run
+}
+
+// bind returns a context derived from ctx that stopping the run cancels. A run stopped
+// before it is bound gets an already cancelled context, so a stop between an async
+// instance's queued wait and its steps is not lost.
+func (r *activeRun) bind(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancelCause(ctx)
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel(nil)
+	}
+	r.cancel = cancel
+	cause := r.cause
+	r.mu.Unlock()
+	if cause != nil {
+		cancel(cause)
+	}
+	return ctx
+}
+
+// stop cancels the run's context with cause
+func (r *activeRun) stop(cause error) {
+	r.mu.Lock()
+	if r.cause == nil {
+		r.cause = cause
+	}
+	cancel := r.cancel
+	r.mu.Unlock()
+	if cancel != nil {
+		cancel(cause)
+	}
+}
+
+// finishRun unregisters a run once it has returned
+func (o *Orchestrator) finishRun(workflowInstID string, run *activeRun) {
+	o.runs.CompareAndDelete(workflowInstID, run)
+	run.stop(context.Canceled)
+	close(run.done)
+}
+
+// cancelRequested reports whether CancelWorkflow stopped the steps running under ctx
+func cancelRequested(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrWorkflowCancelled)
+}
+
+// CancelWorkflow stops a workflow instance that has not finished and marks it cancelled.
+// A run executing in this process has its steps' context cancelled, starts no further
+// steps, and is waited for. An async instance still queued in this process has its wait
+// ended and is settled. Other pending, queued, waiting or paused instances are settled
+// directly. An instance running or retrying in another process cannot be stopped from here,
+// so it fails with ErrWorkflowNotLocal.
+// Workflows built WithCompensateOnCancel compensate their completed steps first; the
+// result's Compensation reports the outcome.
+func (o *Orchestrator) CancelWorkflow(ctx context.Context, workflowInstID string) (*WorkflowResult, error) {
+	if value, ok := o.runs.Load(workflowInstID); ok {
+		run := value.(*activeRun)
+		run.stop(ErrWorkflowCancelled)
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if run.result != nil {
+			return run.result, nil
+		}
+		// The run finished or started waiting before the cancellation took effect
+	}
+
+	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+	if instance.IsCompleted() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrWorkflowFinished, workflowInstID, instance.Status)
+	}
+	switch instance.Status {
+	case WorkflowStatusPending, WorkflowStatusQueued, WorkflowStatusWaiting, WorkflowStatusPaused:
+	default:
+		return nil, fmt.Errorf("%w: %s is %s", ErrWorkflowNotLocal, workflowInstID, instance.Status)
+	}
+
+	workflow, err := o.GetWorkflow(instance.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.settleCancelled(ctx, workflow, instance, instance.StartedAt), nil
+}
+
+// settledWhileWaiting reports whether an async instance was finished, such as by
+// CancelWorkflow, while it waited to start
+func (o *Orchestrator) settledWhileWaiting(ctx context.Context, instance *WorkflowInstance) bool {
+	saved, err := o.stateManager.GetWorkflow(ctx, instance.ID)
+	if err != nil {
+		o.logger.Printf("orchwf: failed to check workflow %s before starting it: %v", instance.ID, err)
+		return false
+	}
+	return saved.IsCompleted()
+}
+
+// settleQueuedWait ends an async instance whose wait for its concurrency key or slot
+// failed with err. When CancelWorkflow ended the wait, the instance is settled cancelled.
+func (o *Orchestrator) settleQueuedWait(ctx, waitCtx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, run *activeRun, err error) {
+	if cancelRequested(waitCtx) {
+		run.result = o.settleCancelled(ctx, workflow, instance, instance.StartedAt)
+		return
+	}
+	o.logger.Printf("orchwf: workflow %s: %v", instance.ID, err)
+}
+
+// settleCancelled skips the steps that have not run, compensates if the workflow asks
+// for it, runs the finalizer and marks the instance cancelled
+func (o *Orchestrator) settleCancelled(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, startTime time.Time) *WorkflowResult {
+	err := fmt.Errorf("%w: %s", ErrWorkflowCancelled, instance.ID)
+
+	for _, stepInst := range instance.Steps {
+		if stepInst.Status == StepStatusPending || stepInst.Status == StepStatusWaiting {
+			o.skipStep(ctx, stepInst, instance, SkipReasonCancelled)
+		}
+	}
+
+	o.flushCompletedSteps(ctx, workflow, instance)
+	var compensation *CompensationResult
+	if workflow.CompensateOnCancel {
+		compensation = o.compensate(ctx, workflow, instance)
+	}
+
+	finalizerErr := o.runFinalizer(ctx, workflow, instance, err)
+
+	instance.Status = WorkflowStatusCancelled
+	instance.Error = stringPtr(err.Error())
+	now := time.Now()
+	instance.CompletedAt = &now
+
+	// Saving the error marks the instance failed, so the status is saved after it
+	o.stateManager.UpdateWorkflowError(ctx, instance.ID, err)
+	o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusCancelled)
+
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowCancelled, map[string]interface{}{
+		"compensated": compensation != nil,
+	})
+	o.metrics.ObserveDuration("workflow.duration", time.Since(startTime), map[string]string{
+		"workflow_id": workflow.ID,
+		"status":      string(WorkflowStatusCancelled),
+	})
+
+	return &WorkflowResult{
+		Success:        false,
+		WorkflowInst:   instance.Clone(),
+		Error:          err,
+		FinalizerError: finalizerErr,
+		Compensation:   compensation,
+		Duration:       time.Since(startTime),
+	}
+}
@@ -0,0 +1,286 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_CancelWorkflow(t *testing.T) {
+	for _, compensateOnCancel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compensate %v", compensateOnCancel), func(t *testing.T) {
+			sm := NewInMemoryStateManager()
+			orchestrator := NewOrchestrator(sm)
+			started := make(chan string, 1)
+			var released []string
+			workflow, _ := NewWorkflowBuilder("cancellable", "Cancellable").
+				WithCompensateOnCancel(compensateOnCancel).
+				AddStepFunc("reserve", "Reserve", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return map[string]interface{}{"reservation": "r1"}, nil
+				}, WithStepCompensator(func(ctx context.Context, input map[string]interface{}) error {
+					released = append(released, input["reservation"].(string))
+					return nil
+				})).
+				AddStepFunc("approve", "Await Approval", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					info, _ := InstanceInfoFromContext(ctx)
+					started <- info.InstanceID
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}, WithStepDeps("reserve")).
+				AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					t.Error("ship ran after the workflow was cancelled")
+					return nil, nil
+				}, WithStepDeps("approve")).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			runErr := make(chan error, 1)
+			go func() {
+				_, err := orchestrator.StartWorkflow(context.Background(), "cancellable", nil, nil)
+				runErr <- err
+			}()
+
+			id := <-started
+			result, err := orchestrator.CancelWorkflow(context.Background(), id)
+			if err != nil {
+				t.Fatalf("CancelWorkflow() error = %v", err)
+			}
+			if err := <-runErr; !errors.Is(err, ErrWorkflowCancelled) {
+				t.Errorf("StartWorkflow() error = %v, want ErrWorkflowCancelled", err)
+			}
+
+			// Compensation runs only when the workflow asks for it
+			if compensateOnCancel {
+				if result.Compensation == nil || !result.Compensation.Succeeded() || len(released) != 1 {
+					t.Errorf("compensation = %+v with %v released, want reserve compensated", result.Compensation, released)
+				}
+			} else if result.Compensation != nil || len(released) != 0 {
+				t.Errorf("compensation = %+v with %v released, want none", result.Compensation, released)
+			}
+
+			stored, _ := sm.GetWorkflow(context.Background(), id)
+			if stored.Status != WorkflowStatusCancelled {
+				t.Errorf("stored status = %s, want cancelled", stored.Status)
+			}
+			for _, step := range stored.Steps {
+				if step.StepID == "ship" && step.Status != StepStatusSkipped {
+					t.Errorf("ship status = %s, want skipped", step.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_CancelWaitingWorkflow(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("reminder", "Reminder").
+		WithCompensateOnCancel(true).
+		AddStepFunc("schedule", "Schedule", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepCompensator(func(ctx context.Context, input map[string]interface{}) error {
+			return nil
+		})).
+		AddStepFunc("remind", "Remind", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("schedule"), WithStepTimerUntil(func(input map[string]interface{}) time.Time {
+			return time.Now().Add(time.Hour)
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	started, err := orchestrator.StartWorkflow(context.Background(), "reminder", nil, nil)
+	if err != nil || started.WorkflowInst.Status != WorkflowStatusWaiting {
+		t.Fatalf("StartWorkflow() = %v, %v, want the instance waiting on its timer", started.WorkflowInst.Status, err)
+	}
+	id := started.WorkflowInst.ID
+
+	// An instance that is not executing is settled directly
+	result, err := orchestrator.CancelWorkflow(context.Background(), id)
+	if err != nil {
+		t.Fatalf("CancelWorkflow() error = %v", err)
+	}
+	if result.Compensation == nil || len(result.Compensation.Steps) != 1 {
+		t.Errorf("compensation = %+v, want schedule compensated", result.Compensation)
+	}
+	stored, _ := sm.GetWorkflow(context.Background(), id)
+	for _, step := range stored.Steps {
+		if step.StepID == "remind" && step.Status != StepStatusSkipped {
+			t.Errorf("remind status = %s, want the waiting timer skipped", step.Status)
+		}
+	}
+
+	events, _ := sm.GetWorkflowEvents(context.Background(), id)
+	var cancelled int
+	for _, event := range events {
+		if event.EventType == EventWorkflowCancelled {
+			cancelled++
+		}
+	}
+	if cancelled != 1 {
+		t.Errorf("%s events = %d, want 1", EventWorkflowCancelled, cancelled)
+	}
+
+	if _, err := orchestrator.CancelWorkflow(context.Background(), id); !errors.Is(err, ErrWorkflowFinished) {
+		t.Errorf("second CancelWorkflow() error = %v, want ErrWorkflowFinished", err)
+	}
+}
+
+func TestOrchestrator_CancelQueuedWorkflow(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithMaxInFlight(1))
+
+	unblock := make(chan struct{})
+	var runs atomic.Int32
+	workflow, _ := NewWorkflowBuilder("queued-cancel", "Queued Cancel").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs.Add(1)
+			<-unblock
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	waitFor := func(inFlight, queued int) {
+		deadline := time.Now().Add(time.Second)
+		for orchestrator.InFlightWorkflows() != inFlight || orchestrator.QueuedWorkflows() != queued {
+			if time.Now().After(deadline) {
+				t.Fatalf("in flight = %d, queued = %d, want %d and %d", orchestrator.InFlightWorkflows(), orchestrator.QueuedWorkflows(), inFlight, queued)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if _, err := orchestrator.StartWorkflowAsync(ctx, "queued-cancel", nil, nil); err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	waitFor(1, 0)
+	queued, err := orchestrator.StartWorkflowAsync(ctx, "queued-cancel", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	waitFor(1, 1)
+
+	result, err := orchestrator.CancelWorkflow(ctx, queued)
+	if err != nil {
+		t.Fatalf("CancelWorkflow() error = %v", err)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusCancelled {
+		t.Errorf("result status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusCancelled)
+	}
+
+	// The cancelled instance gives up its place in the queue at once
+	waitFor(1, 0)
+
+	close(unblock)
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("executor runs = %d, want 1: the cancelled instance must not run", n)
+	}
+	if instance, _ := sm.GetWorkflow(ctx, queued); instance.Status != WorkflowStatusCancelled {
+		t.Errorf("cancelled instance status = %v, want %v", instance.Status, WorkflowStatusCancelled)
+	}
+}
+
+func TestOrchestrator_CancelDuringRetryBackoff(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	failed := make(chan string, 1)
+	workflow, _ := NewWorkflowBuilder("backoff-cancel", "Backoff Cancel").
+		AddStepFunc("flaky", "Flaky", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			info, _ := InstanceInfoFromContext(ctx)
+			failed <- info.InstanceID
+			return nil, errors.New("transient failure")
+		}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialInterval: 10 * time.Second, MaxInterval: 10 * time.Second, Multiplier: 1})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	runErr := make(chan error, 1)
+	go func() {
+		_, err := orchestrator.StartWorkflow(context.Background(), "backoff-cancel", nil, nil)
+		runErr <- err
+	}()
+
+	// The step is now waiting out its backoff, which must not hold up the cancel
+	id := <-failed
+	start := time.Now()
+	if _, err := orchestrator.CancelWorkflow(context.Background(), id); err != nil {
+		t.Fatalf("CancelWorkflow() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("CancelWorkflow() took %v, want it to end the backoff", elapsed)
+	}
+	if err := <-runErr; !errors.Is(err, ErrWorkflowCancelled) {
+		t.Errorf("StartWorkflow() error = %v, want ErrWorkflowCancelled", err)
+	}
+	if instance, _ := sm.GetWorkflow(context.Background(), id); instance.Status != WorkflowStatusCancelled {
+		t.Errorf("stored status = %s, want cancelled", instance.Status)
+	}
+}
+
+func TestOrchestrator_CancelJustStartedAsyncWorkflow(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("async-cancel", "Async Cancel").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	// However far the instance got, a cancel right after the start settles it for good
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 50; i++ {
+		id, err := orchestrator.StartWorkflowAsync(ctx, "async-cancel", nil, nil)
+		if err != nil {
+			t.Fatalf("StartWorkflowAsync() error = %v", err)
+		}
+		if _, err := orchestrator.CancelWorkflow(ctx, id); err != nil {
+			t.Fatalf("CancelWorkflow() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	for _, id := range ids {
+		if instance, _ := sm.GetWorkflow(ctx, id); instance.Status != WorkflowStatusCancelled {
+			t.Errorf("instance %s status = %v, want %v", id, instance.Status, WorkflowStatusCancelled)
+		}
+	}
+}
+
+func TestOrchestrator_CancelWorkflowRunningElsewhere(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("remote", "Remote").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	// Another process is running the instance, so this one cannot stop its steps
+	ctx := context.Background()
+	instance := &WorkflowInstance{ID: "remote-1", WorkflowID: "remote", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+	sm.SaveWorkflow(ctx, instance)
+
+	if _, err := orchestrator.CancelWorkflow(ctx, instance.ID); !errors.Is(err, ErrWorkflowNotLocal) {
+		t.Errorf("CancelWorkflow() error = %v, want ErrWorkflowNotLocal", err)
+	}
+	if stored, _ := sm.GetWorkflow(ctx, instance.ID); stored.Status != WorkflowStatusRunning {
+		t.Errorf("stored status = %s, want it left running", stored.Status)
+	}
+}
@@ -0,0 +1,69 @@
+package orchwf
+
+import "context"
+
+// WithMaxInFlight caps how many workflow instances execute at once across the orchestrator.
+// Starts, resumes and redrives beyond the cap queue until a running instance
+// returns: StartWorkflow blocks until a slot frees or its context is done, and
+// StartWorkflowAsync returns at once, leaving the instance WorkflowStatusQueued while it
+// waits. Child workflows run within their parent's slot, so a parent never waits on a
+// child that waits on it. Queued instances are served by WorkflowBuilder.WithPriority,
+// then in FIFO order. A zero value leaves the number unlimited. This is separate from
+// WithAsyncWorkers, which sizes the pool for async steps within a run.
+func WithMaxInFlight(n int) Option {
+	return func(o *Orchestrator) {
+		if n > 0 {
+			o.inFlight = newResourcePool("max-in-flight", n)
+		}
+	}
+}
+
+// acquireSlot waits for an in-flight slot, ahead of queued workflows with a lower priority,
+// and returns the func that frees it. Child workflows, which have a parent instance, take
+// no slot of their own.
+func (o *Orchestrator) acquireSlot(ctx context.Context, workflow *WorkflowDefinition, parentInstID string) (func(), error) {
+	if o.inFlight == nil || parentInstID != "" {
+		return func() {}, nil
+	}
+	if err := o.inFlight.acquirePriority(ctx, 1, workflow.Priority); err != nil {
+		return nil, err
+	}
+	return func() { o.inFlight.release(1) }, nil
+}
+
+// acquireQueuedSlot is acquireSlot for an instance that has already been saved. When no
+// slot is free, the instance is marked WorkflowStatusQueued while it waits, and
+// executeWorkflow moves it to running once it has one.
+func (o *Orchestrator) acquireQueuedSlot(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) (func(), error) {
+	if o.inFlight == nil || instance.ParentInstID != "" {
+		return func() {}, nil
+	}
+	if !o.inFlight.tryAcquire(1) {
+		instance.Status = WorkflowStatusQueued
+		if err := o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusQueued); err != nil {
+			o.logger.Printf("orchwf: failed to mark workflow %s queued: %v", instance.ID, err)
+		}
+		if err := o.inFlight.acquirePriority(ctx, 1, workflow.Priority); err != nil {
+			return nil, err
+		}
+	}
+	return func() { o.inFlight.release(1) }, nil
+}
+
+// InFlightWorkflows returns the number of workflow instances holding a slot under WithMaxInFlight
+func (o *Orchestrator) InFlightWorkflows() int {
+	if o.inFlight == nil {
+		return 0
+	}
+	used, _ := o.inFlight.usage()
+	return used
+}
+
+// QueuedWorkflows returns the number of workflow executions waiting for a slot under WithMaxInFlight
+func (o *Orchestrator) QueuedWorkflows() int {
+	if o.inFlight == nil {
+		return 0
+	}
+	_, waiting := o.inFlight.usage()
+	return waiting
+}
@@ -0,0 +1,167 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_MaxInFlightQueuesStarts(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithMaxInFlight(1))
+
+	unblock := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("in-flight", "In Flight").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-unblock
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	first, err := orchestrator.StartWorkflowAsync(ctx, "in-flight", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	second, err := orchestrator.StartWorkflowAsync(ctx, "in-flight", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for orchestrator.InFlightWorkflows() != 1 || orchestrator.QueuedWorkflows() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("in flight = %d, queued = %d, want 1 and 1", orchestrator.InFlightWorkflows(), orchestrator.QueuedWorkflows())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A synchronous start blocks behind the queue until its context gives up
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := orchestrator.StartWorkflow(timeoutCtx, "in-flight", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StartWorkflow() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Only one instance has started running; the other is queued
+	statuses := make(map[WorkflowStatus]int)
+	for _, id := range []string{first, second} {
+		instance, _ := sm.GetWorkflow(ctx, id)
+		statuses[instance.Status]++
+	}
+	if statuses[WorkflowStatusRunning] != 1 || statuses[WorkflowStatusQueued] != 1 {
+		t.Errorf("instance statuses = %v, want one running and one queued", statuses)
+	}
+	queued, _, _ := sm.ListWorkflows(ctx, map[string]interface{}{"status": WorkflowStatusQueued}, 10, 0)
+	if len(queued) != 1 {
+		t.Errorf("ListWorkflows(queued) = %d instances, want 1", len(queued))
+	}
+
+	close(unblock)
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, time.Second)
+	defer cancelShutdown()
+	if err := orchestrator.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	for _, id := range []string{first, second} {
+		if instance, _ := sm.GetWorkflow(ctx, id); instance.Status != WorkflowStatusCompleted {
+			t.Errorf("workflow %s status = %v, want completed", id, instance.Status)
+		}
+	}
+	if orchestrator.InFlightWorkflows() != 0 || orchestrator.QueuedWorkflows() != 0 {
+		t.Errorf("in flight = %d, queued = %d after shutdown, want 0 and 0", orchestrator.InFlightWorkflows(), orchestrator.QueuedWorkflows())
+	}
+}
+
+func TestOrchestrator_MaxInFlightChildUsesParentSlot(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithMaxInFlight(1))
+
+	child, _ := NewWorkflowBuilder("child", "Child").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"child": true}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(child)
+
+	parent, _ := NewWorkflowBuilder("parent", "Parent").
+		AddStepFunc("spawn", "Spawn", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			info, _ := InstanceInfoFromContext(ctx)
+			_, err := orchestrator.StartWorkflow(ctx, "child", nil, map[string]interface{}{"parent_workflow_inst_id": info.InstanceID})
+			return nil, err
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(parent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := orchestrator.StartWorkflow(ctx, "parent", nil, nil); err != nil {
+		t.Fatalf("StartWorkflow() error = %v, want the child to run in the parent's slot", err)
+	}
+}
+
+func TestOrchestrator_MaxInFlightServesHigherPriorityFirst(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithMaxInFlight(1))
+
+	unblock := make(chan struct{})
+	blocker, _ := NewWorkflowBuilder("blocker", "Blocker").
+		AddStepFunc("wait", "Wait", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-unblock
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(blocker)
+
+	var mu sync.Mutex
+	var completed []string
+	record := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, input["order"].(string))
+		return nil, nil
+	}
+	standard, _ := NewWorkflowBuilder("standard-order", "Standard Order").
+		AddStepFunc("process", "Process", record).
+		Build()
+	vip, _ := NewWorkflowBuilder("vip-order", "VIP Order").
+		WithPriority(10).
+		AddStepFunc("process", "Process", record).
+		Build()
+	orchestrator.RegisterWorkflow(standard)
+	orchestrator.RegisterWorkflow(vip)
+
+	ctx := context.Background()
+	waitQueued := func(n int) {
+		deadline := time.Now().Add(time.Second)
+		for orchestrator.InFlightWorkflows() != 1 || orchestrator.QueuedWorkflows() != n {
+			if time.Now().After(deadline) {
+				t.Fatalf("in flight = %d, queued = %d, want 1 and %d", orchestrator.InFlightWorkflows(), orchestrator.QueuedWorkflows(), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	orchestrator.StartWorkflowAsync(ctx, "blocker", nil, nil)
+	waitQueued(0)
+	for i, order := range []string{"standard-1", "standard-2"} {
+		orchestrator.StartWorkflowAsync(ctx, "standard-order", map[string]interface{}{"order": order}, nil)
+		waitQueued(i + 1)
+	}
+	orchestrator.StartWorkflowAsync(ctx, "vip-order", map[string]interface{}{"order": "vip"}, nil)
+	waitQueued(3)
+
+	close(unblock)
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{"vip", "standard-1", "standard-2"}
+	if !reflect.DeepEqual(completed, want) {
+		t.Errorf("completion order = %v, want %v", completed, want)
+	}
+}
@@ -0,0 +1,156 @@
+package orchwf
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// StepTrace records one executor call made during a captured run
+type StepTrace struct {
+	StepID     string                 `json:"step_id"`
+	Attempt    int                    `json:"attempt"`
+	Input      map[string]interface{} `json:"input"`
+	Output     map[string]interface{} `json:"output,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// WorkflowTrace is a portable record of a workflow run: the workflow input, every
+// step's input and output in call order, and the final status and output.
+type WorkflowTrace struct {
+	WorkflowID     string                 `json:"workflow_id"`
+	WorkflowInstID string                 `json:"workflow_inst_id"`
+	Status         WorkflowStatus         `json:"status"`
+	Input          map[string]interface{} `json:"input"`
+	Output         map[string]interface{} `json:"output,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	Steps          []StepTrace            `json:"steps"`
+	StartedAt      time.Time              `json:"started_at"`
+	CompletedAt    *time.Time             `json:"completed_at,omitempty"`
+}
+
+// CaptureSink receives a trace each time a captured workflow run returns
+type CaptureSink interface {
+	WriteTrace(ctx context.Context, trace *WorkflowTrace) error
+}
+
+// JSONCaptureSink writes each trace as one JSON line to an io.Writer
+type JSONCaptureSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONCaptureSink creates a capture sink that writes JSON lines to w
+func NewJSONCaptureSink(w io.Writer) *JSONCaptureSink {
+	return &JSONCaptureSink{w: w}
+}
+
+// WriteTrace encodes the trace as a single JSON line
+func (s *JSONCaptureSink) WriteTrace(ctx context.Context, trace *WorkflowTrace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(trace)
+}
+
+// ReadWorkflowTraces decodes the JSON lines written by a JSONCaptureSink
+func ReadWorkflowTraces(r io.Reader) ([]*WorkflowTrace, error) {
+	var traces []*WorkflowTrace
+	dec := json.NewDecoder(r)
+	for {
+		var trace WorkflowTrace
+		if err := dec.Decode(&trace); err == io.EOF {
+			return traces, nil
+		} else if err != nil {
+			return nil, err
+		}
+		traces = append(traces, &trace)
+	}
+}
+
+// WithCapture records every workflow run into a WorkflowTrace and hands it to sink.
+// Capture only observes: executor inputs and outputs are copied, never modified.
+func WithCapture(sink CaptureSink) Option {
+	return func(o *Orchestrator) {
+		o.capture = sink
+	}
+}
+
+// traceRecorder collects step traces for one run; async steps record concurrently
+type traceRecorder struct {
+	mu       sync.Mutex
+	steps    []StepTrace
+	attempts map[string]int
+}
+
+// recordStep appends an executor call to the trace
+func (r *traceRecorder) recordStep(stepID string, input, output map[string]interface{}, err error, startedAt time.Time, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	step := StepTrace{
+		StepID:     stepID,
+		Attempt:    r.attempts[stepID],
+		Input:      copyMap(input),
+		Output:     copyMap(output),
+		StartedAt:  startedAt,
+		DurationMs: duration.Milliseconds(),
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	r.attempts[stepID]++
+	r.steps = append(r.steps, step)
+}
+
+// startCapture attaches a trace recorder to ctx when capture is enabled
+func (o *Orchestrator) startCapture(ctx context.Context) (context.Context, *traceRecorder) {
+	if o.capture == nil {
+		return ctx, nil
+	}
+	recorder := &traceRecorder{attempts: make(map[string]int)}
+	return context.WithValue(ctx, traceRecorderKey, recorder), recorder
+}
+
+// finishCapture builds the trace for a returned run and writes it to the sink.
+// Sink errors are logged; they never change the workflow result.
+func (o *Orchestrator) finishCapture(ctx context.Context, recorder *traceRecorder, instance *WorkflowInstance) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.mu.Lock()
+	steps := append([]StepTrace(nil), recorder.steps...)
+	recorder.mu.Unlock()
+
+	o.outputMu.Lock()
+	output := copyMap(instance.Output)
+	o.outputMu.Unlock()
+
+	trace := &WorkflowTrace{
+		WorkflowID:     instance.WorkflowID,
+		WorkflowInstID: instance.ID,
+		Status:         instance.Status,
+		Input:          copyMap(instance.Input),
+		Output:         output,
+		Steps:          steps,
+		StartedAt:      instance.StartedAt,
+		CompletedAt:    instance.CompletedAt,
+	}
+	if instance.Error != nil {
+		trace.Error = *instance.Error
+	}
+
+	if err := o.capture.WriteTrace(ctx, trace); err != nil {
+		o.logger.Printf("orchwf: failed to write trace for workflow %s: %v", instance.ID, err)
+	}
+}
+
+// traceRecorderFromContext returns the recorder of a captured run, or nil
+func traceRecorderFromContext(ctx context.Context) *traceRecorder {
+	recorder, _ := ctx.Value(traceRecorderKey).(*traceRecorder)
+	return recorder
+}
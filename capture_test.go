@@ -0,0 +1,108 @@
+package orchwf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_CaptureRecordsSteps(t *testing.T) {
+	var buf bytes.Buffer
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithCapture(NewJSONCaptureSink(&buf)))
+
+	attempts := 0
+	workflow, _ := NewWorkflowBuilder("capture-workflow", "Capture Workflow").
+		AddStepFunc("price", "Price", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"price": 42.0}, nil
+		}, WithStepAsync(true)).
+		AddStepFunc("stock", "Stock", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"in_stock": true}, nil
+		}, WithStepAsync(true)).
+		AddStepFunc("reserve", "Reserve", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("temporarily unavailable")
+			}
+			return map[string]interface{}{"reserved": true}, nil
+		}, WithStepDeps("price", "stock"), WithStepRetryPolicy(&RetryPolicy{
+			MaxAttempts:     2,
+			InitialInterval: time.Millisecond,
+			Multiplier:      1,
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "capture-workflow",
+		map[string]interface{}{"sku": "abc"}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	traces, err := ReadWorkflowTraces(&buf)
+	if err != nil {
+		t.Fatalf("ReadWorkflowTraces() error = %v", err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("captured %d traces, want 1", len(traces))
+	}
+
+	trace := traces[0]
+	if trace.WorkflowInstID != result.WorkflowInst.ID || trace.Status != WorkflowStatusCompleted {
+		t.Errorf("trace = %s/%s, want %s/%s", trace.WorkflowInstID, trace.Status, result.WorkflowInst.ID, WorkflowStatusCompleted)
+	}
+	if trace.Input["sku"] != "abc" {
+		t.Errorf("trace input = %v, want sku=abc", trace.Input)
+	}
+	if trace.Output["reserved"] != true {
+		t.Errorf("trace output = %v, want reserved=true", trace.Output)
+	}
+
+	// price and stock once each, reserve twice
+	if len(trace.Steps) != 4 {
+		t.Fatalf("captured %d step calls, want 4", len(trace.Steps))
+	}
+	var reserve []StepTrace
+	for _, step := range trace.Steps {
+		if step.StepID == "reserve" {
+			reserve = append(reserve, step)
+		}
+	}
+	if len(reserve) != 2 {
+		t.Fatalf("captured %d reserve calls, want 2", len(reserve))
+	}
+	if reserve[0].Attempt != 0 || reserve[0].Error != "temporarily unavailable" {
+		t.Errorf("first reserve call = %+v, want attempt 0 with error", reserve[0])
+	}
+	if reserve[1].Attempt != 1 || reserve[1].Output["reserved"] != true {
+		t.Errorf("second reserve call = %+v, want attempt 1 with output", reserve[1])
+	}
+	if reserve[1].Input["price"] != 42.0 || reserve[1].Input["in_stock"] != true {
+		t.Errorf("reserve input = %v, want dependency outputs", reserve[1].Input)
+	}
+}
+
+func TestOrchestrator_CaptureDoesNotAlterResult(t *testing.T) {
+	newWorkflow := func() *WorkflowDefinition {
+		workflow, _ := NewWorkflowBuilder("plain", "Plain").
+			AddStepFunc("double", "Double", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"value": input["value"].(int) * 2}, nil
+			}).
+			Build()
+		return workflow
+	}
+
+	plain := NewOrchestrator(NewInMemoryStateManager())
+	plain.RegisterWorkflow(newWorkflow())
+	captured := NewOrchestrator(NewInMemoryStateManager(), WithCapture(NewJSONCaptureSink(&bytes.Buffer{})))
+	captured.RegisterWorkflow(newWorkflow())
+
+	input := map[string]interface{}{"value": 21}
+	want, _ := plain.StartWorkflow(context.Background(), "plain", input, nil)
+	got, _ := captured.StartWorkflow(context.Background(), "plain", input, nil)
+
+	if got.Success != want.Success || got.Output["value"] != want.Output["value"] {
+		t.Errorf("captured result = %v/%v, want %v/%v", got.Success, got.Output, want.Success, want.Output)
+	}
+}
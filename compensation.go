@@ -0,0 +1,101 @@
+package orchwf
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// CompensationOutcome is the result of running one step's compensator
+type CompensationOutcome string
+
+const (
+	CompensationSucceeded CompensationOutcome = "success"
+	CompensationFailed    CompensationOutcome = "failed"
+)
+
+// CompensatedStep reports the compensator run for one completed step
+type CompensatedStep struct {
+	StepID   string
+	Outcome  CompensationOutcome
+	Error    error // Error returned by the compensator, if it failed
+	Duration time.Duration
+}
+
+// CompensationResult reports what was rolled back after a workflow failed
+type CompensationResult struct {
+	Steps []CompensatedStep // In the order the compensators ran
+}
+
+// Succeeded reports whether every compensator succeeded
+func (r *CompensationResult) Succeeded() bool {
+	for _, step := range r.Steps {
+		if step.Outcome != CompensationSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// compensate runs the compensators of the completed steps in reverse completion order.
+// A failing compensator is reported and does not stop the others. It returns nil when
+// no completed step has a compensator.
+func (o *Orchestrator) compensate(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) *CompensationResult {
+	stepDefMap := make(map[string]*StepDefinition)
+	for _, stepDef := range workflow.Steps {
+		stepDefMap[stepDef.ID] = stepDef
+	}
+
+	var completed []*StepInstance
+	for _, stepInst := range instance.Steps {
+		if stepDef := stepDefMap[stepInst.StepID]; stepDef != nil && stepDef.Compensator != nil && stepInst.Status == StepStatusCompleted {
+			completed = append(completed, stepInst)
+		}
+	}
+	if len(completed) == 0 {
+		return nil
+	}
+
+	// Undo the most recent work first
+	sort.SliceStable(completed, func(i, j int) bool {
+		a, b := completed[i], completed[j]
+		if a.CompletedAt != nil && b.CompletedAt != nil && !a.CompletedAt.Equal(*b.CompletedAt) {
+			return a.CompletedAt.After(*b.CompletedAt)
+		}
+		return a.ExecutionOrder > b.ExecutionOrder
+	})
+
+	// Compensators run even if the caller's context was cancelled, like the finalizer
+	compCtx := context.WithoutCancel(ctx)
+	result := &CompensationResult{}
+	for _, stepInst := range completed {
+		// The compensator sees what the step was given and what it produced
+		input := make(map[string]interface{}, len(stepInst.Input)+len(stepInst.Output))
+		for k, v := range stepInst.Input {
+			input[k] = v
+		}
+		for k, v := range stepInst.Output {
+			input[k] = v
+		}
+
+		startTime := time.Now()
+		err := stepDefMap[stepInst.StepID].Compensator(compCtx, input)
+		step := CompensatedStep{StepID: stepInst.StepID, Outcome: CompensationSucceeded, Duration: time.Since(startTime)}
+		if err != nil {
+			step.Outcome, step.Error = CompensationFailed, err
+			o.logger.Printf("orchwf: compensator of step %s failed for workflow %s: %v", stepInst.StepID, instance.ID, err)
+			o.emitEvent(compCtx, instance.ID, &stepInst.ID, EventCompensationFailed, map[string]interface{}{
+				"step_id": stepInst.StepID,
+				"error":   err.Error(),
+			})
+		} else {
+			o.emitEvent(compCtx, instance.ID, &stepInst.ID, EventStepCompensated, map[string]interface{}{
+				"step_id":     stepInst.StepID,
+				"duration_ms": step.Duration.Milliseconds(),
+			})
+		}
+		result.Steps = append(result.Steps, step)
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrchestrator_CompensationResult(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var cancelled []string
+	book := func(id string) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"booking": id + "-123"}, nil
+		}
+	}
+	cancel := func(err error) StepCompensator {
+		return func(ctx context.Context, input map[string]interface{}) error {
+			cancelled = append(cancelled, input["booking"].(string))
+			return err
+		}
+	}
+
+	workflow, _ := NewWorkflowBuilder("travel", "Travel").
+		AddStepFunc("hotel", "Hotel", book("hotel"), WithStepCompensator(cancel(nil))).
+		AddStepFunc("flight", "Flight", book("flight"), WithStepDeps("hotel"), WithStepCompensator(cancel(errors.New("airline unavailable")))).
+		AddStepFunc("car", "Car", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("no cars left")
+		}, WithStepDeps("flight"), WithStepCompensator(cancel(nil))).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "travel", nil, nil)
+	if err == nil {
+		t.Fatal("StartWorkflow() should fail")
+	}
+	if result.Compensation == nil {
+		t.Fatal("WorkflowResult.Compensation is nil, want the compensated steps")
+	}
+
+	// The failed step is not compensated and the most recent booking is undone first
+	steps := result.Compensation.Steps
+	if len(steps) != 2 || steps[0].StepID != "flight" || steps[1].StepID != "hotel" {
+		t.Fatalf("Compensation.Steps = %+v, want flight then hotel", steps)
+	}
+	if steps[0].Outcome != CompensationFailed || steps[0].Error == nil {
+		t.Errorf("flight compensation = %+v, want failed with its error", steps[0])
+	}
+	if steps[1].Outcome != CompensationSucceeded || steps[1].Error != nil {
+		t.Errorf("hotel compensation = %+v, want success", steps[1])
+	}
+	if result.Compensation.Succeeded() {
+		t.Errorf("Compensation.Succeeded() = true, want false")
+	}
+	if len(cancelled) != 2 || cancelled[0] != "flight-123" || cancelled[1] != "hotel-123" {
+		t.Errorf("compensators saw bookings %v, want [flight-123 hotel-123]", cancelled)
+	}
+}
+
+func TestOrchestrator_NoCompensationResult(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	compensated := false
+	workflow, _ := NewWorkflowBuilder("no-compensation", "No Compensation").
+		AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}, WithStepCompensator(func(ctx context.Context, input map[string]interface{}) error {
+			compensated = true
+			return nil
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "no-compensation", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if result.Compensation != nil || compensated {
+		t.Errorf("successful workflow ran compensation: %+v", result.Compensation)
+	}
+}
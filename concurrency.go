@@ -0,0 +1,101 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ConcurrencyKeyMetadata is the metadata key whose value serializes instances of the
+// same workflow: two instances with the same key never run at the same time
+const ConcurrencyKeyMetadata = "concurrency_key"
+
+// ConcurrencyPolicy decides what happens when an instance's concurrency key is held
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyPolicyQueue  ConcurrencyPolicy = "queue"  // Wait until the running instance finishes (default)
+	ConcurrencyPolicyReject ConcurrencyPolicy = "reject" // Fail immediately with ErrConcurrencyKeyBusy
+)
+
+// WithConcurrencyPolicy sets how instances sharing a concurrency key are serialized
+func WithConcurrencyPolicy(policy ConcurrencyPolicy) Option {
+	return func(o *Orchestrator) {
+		o.concurrencyPolicy = policy
+	}
+}
+
+// keyedMutex holds one lock per key, dropping it once nobody holds or waits on it
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is a single-slot semaphore with a count of its holders and waiters
+type keyedLock struct {
+	sem  chan struct{}
+	refs int
+}
+
+// lock acquires key, waiting until ctx is done if wait is set.
+// The returned function releases the lock.
+func (k *keyedMutex) lock(ctx context.Context, key string, wait bool) (func(), error) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{sem: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	if wait {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			k.unref(key, l)
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case l.sem <- struct{}{}:
+		default:
+			k.unref(key, l)
+			return nil, ErrConcurrencyKeyBusy
+		}
+	}
+
+	return func() {
+		<-l.sem
+		k.unref(key, l)
+	}, nil
+}
+
+// unref drops a reference to the lock and forgets it once unused
+func (k *keyedMutex) unref(key string, l *keyedLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// lockConcurrencyKey serializes instances of workflowID that share a concurrency key.
+// Without a key it returns a no-op release.
+func (o *Orchestrator) lockConcurrencyKey(ctx context.Context, workflowID string, metadata map[string]interface{}) (func(), error) {
+	key, _ := metadata[ConcurrencyKeyMetadata].(string)
+	if key == "" {
+		return func() {}, nil
+	}
+
+	release, err := o.concurrency.lock(ctx, workflowID+"/"+key, o.concurrencyPolicy != ConcurrencyPolicyReject)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %s concurrency key %q: %w", workflowID, key, err)
+	}
+	return release, nil
+}
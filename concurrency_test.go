@@ -0,0 +1,99 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_ConcurrencyKeyQueue(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var running, maxRunning atomic.Int32
+	workflow, _ := NewWorkflowBuilder("order", "Order").
+		AddStepFunc("process", "Process", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return map[string]interface{}{}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	run := func(keys ...string) {
+		var wg sync.WaitGroup
+		for _, key := range keys {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				metadata := map[string]interface{}{ConcurrencyKeyMetadata: key}
+				if _, err := orchestrator.StartWorkflow(context.Background(), "order", nil, metadata); err != nil {
+					t.Errorf("StartWorkflow() error = %v", err)
+				}
+			}(key)
+		}
+		wg.Wait()
+	}
+
+	run("order-1", "order-1", "order-1")
+	if got := maxRunning.Load(); got != 1 {
+		t.Errorf("instances sharing a key ran %d at a time, want 1", got)
+	}
+
+	maxRunning.Store(0)
+	run("order-1", "order-2")
+	if got := maxRunning.Load(); got != 2 {
+		t.Errorf("instances with different keys ran %d at a time, want 2", got)
+	}
+}
+
+func TestOrchestrator_ConcurrencyKeyReject(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithConcurrencyPolicy(ConcurrencyPolicyReject))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("order", "Order").
+		AddStepFunc("process", "Process", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			if input["block"] == true {
+				close(started)
+				<-release
+			}
+			return map[string]interface{}{}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	metadata := map[string]interface{}{ConcurrencyKeyMetadata: "order-1"}
+	done := make(chan error, 1)
+	go func() {
+		_, err := orchestrator.StartWorkflow(context.Background(), "order", map[string]interface{}{"block": true}, metadata)
+		done <- err
+	}()
+	<-started
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "order", nil, metadata); !errors.Is(err, ErrConcurrencyKeyBusy) {
+		t.Errorf("StartWorkflow() with a held key error = %v, want %v", err, ErrConcurrencyKeyBusy)
+	}
+	if _, err := orchestrator.StartWorkflowAsync(context.Background(), "order", nil, metadata); !errors.Is(err, ErrConcurrencyKeyBusy) {
+		t.Errorf("StartWorkflowAsync() with a held key error = %v, want %v", err, ErrConcurrencyKeyBusy)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first StartWorkflow() error = %v", err)
+	}
+
+	// The key is free again once the first instance finishes
+	if _, err := orchestrator.StartWorkflow(context.Background(), "order", nil, metadata); err != nil {
+		t.Errorf("StartWorkflow() after release error = %v", err)
+	}
+}
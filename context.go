@@ -0,0 +1,143 @@
+package orchwf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// contextKey is the type for context keys set by the orchestrator
+type contextKey string
+
+const (
+	idempotencyTokenKey contextKey = "idempotency_token"
+	workflowErrorKey    contextKey = "workflow_error"
+	traceRecorderKey    contextKey = "trace_recorder"
+	workflowRunKey      contextKey = "workflow_run"
+	stepRunKey          contextKey = "step_run"
+	instanceInfoKey     contextKey = "instance_info"
+	skipTotalKey        contextKey = "skip_total"
+	deferredMergeKey    contextKey = "deferred_merge"
+	completedOutputsKey contextKey = "completed_outputs"
+)
+
+// withIdempotencyToken returns a copy of ctx carrying the idempotency token
+func withIdempotencyToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, idempotencyTokenKey, token)
+}
+
+// IdempotencyTokenFromContext returns the idempotency token of the step being executed.
+// The token is stable across retries of the same step instance, so executors can pass it
+// to downstream services to deduplicate side effects.
+func IdempotencyTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(idempotencyTokenKey).(string)
+	return token, ok
+}
+
+// withWorkflowError returns a copy of ctx carrying the error that failed the workflow
+func withWorkflowError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, workflowErrorKey, err)
+}
+
+// WorkflowErrorFromContext returns the error that failed the workflow, for use in finalizers.
+// It returns nil when the workflow steps succeeded.
+func WorkflowErrorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(workflowErrorKey).(error)
+	return err
+}
+
+// WithoutTotal returns a copy of ctx that tells ListWorkflows not to count the matching
+// workflows. The total is then -1, and the database state manager saves the COUNT(*)
+// query. Use it when only the page is needed, not for paginated UIs.
+func WithoutTotal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTotalKey, true)
+}
+
+// skipTotal reports whether ctx was marked with WithoutTotal
+func skipTotal(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipTotalKey).(bool)
+	return skip
+}
+
+// InstanceInfo describes the workflow instance a step is executing for. It is a snapshot
+// taken when the step started; changing it does not change the instance.
+type InstanceInfo struct {
+	InstanceID    string
+	WorkflowID    string
+	TraceID       string
+	CorrelationID string
+	BusinessID    string
+	Metadata      map[string]interface{}
+}
+
+// withInstanceInfo returns a copy of ctx carrying a snapshot of the workflow instance
+func (o *Orchestrator) withInstanceInfo(ctx context.Context, instance *WorkflowInstance) context.Context {
+	o.outputMu.Lock()
+	metadata := copyMap(instance.Metadata)
+	o.outputMu.Unlock()
+
+	return context.WithValue(ctx, instanceInfoKey, InstanceInfo{
+		InstanceID:    instance.ID,
+		WorkflowID:    instance.WorkflowID,
+		TraceID:       instance.TraceID,
+		CorrelationID: instance.CorrelationID,
+		BusinessID:    instance.BusinessID,
+		Metadata:      metadata,
+	})
+}
+
+// InstanceInfoFromContext returns the workflow instance the current step is executing for,
+// so executors can use its correlation ID or metadata without threading them through the input
+func InstanceInfoFromContext(ctx context.Context) (InstanceInfo, bool) {
+	info, ok := ctx.Value(instanceInfoKey).(InstanceInfo)
+	return info, ok
+}
+
+// workflowRun identifies the workflow instance a step is executing for
+type workflowRun struct {
+	orchestrator *Orchestrator
+	instance     *WorkflowInstance
+	mu           sync.Mutex    // Guards backpressure
+	backpressure time.Duration // Longest delay requested during the current wave
+}
+
+// withWorkflowRun returns a copy of ctx carrying the running workflow instance
+func withWorkflowRun(ctx context.Context, o *Orchestrator, instance *WorkflowInstance) context.Context {
+	return context.WithValue(ctx, workflowRunKey, &workflowRun{orchestrator: o, instance: instance})
+}
+
+// stepRun identifies the step instance an executor is running for
+type stepRun struct {
+	orchestrator *Orchestrator
+	stepInst     *StepInstance
+	mu           sync.Mutex // Guards stepInst.Attachments and the progress fields
+
+	progressAt      time.Time       // When the last progress event was emitted
+	pendingProgress *progressUpdate // Latest update coalesced since then, if any
+}
+
+// withStepRun returns a copy of ctx carrying the step instance being executed
+func withStepRun(ctx context.Context, o *Orchestrator, stepInst *StepInstance) context.Context {
+	return context.WithValue(ctx, stepRunKey, &stepRun{orchestrator: o, stepInst: stepInst})
+}
+
+// UpdateWorkflowMetadataFromContext merges metadata into the workflow running the current
+// step and persists it. It returns ErrNoWorkflowContext when ctx does not come from a step.
+func UpdateWorkflowMetadataFromContext(ctx context.Context, metadata map[string]interface{}) error {
+	run, ok := ctx.Value(workflowRunKey).(*workflowRun)
+	if !ok {
+		return ErrNoWorkflowContext
+	}
+
+	o, instance := run.orchestrator, run.instance
+	o.outputMu.Lock()
+	if instance.Metadata == nil {
+		instance.Metadata = make(map[string]interface{})
+	}
+	for k, v := range metadata {
+		instance.Metadata[k] = v
+	}
+	o.outputMu.Unlock()
+
+	return o.UpdateWorkflowMetadata(ctx, instance.ID, metadata)
+}
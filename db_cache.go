@@ -0,0 +1,165 @@
+package orchwf
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// workflowCache is a size-bounded LRU of the instances read by DBStateManager.GetWorkflow.
+// Writes made through the manager invalidate the instance they touch before returning.
+type workflowCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration            // How long a non-terminal instance is served (0 = never cached)
+	entries map[string]*list.Element // Elements of order, by workflow instance ID
+	order   *list.List               // Cached entries, most recently used first
+	owners  map[string]string        // Workflow instance ID of each cached step, by step instance ID
+	version uint64                   // Bumped by every invalidation, so reads racing a write are not cached
+	now     func() time.Time
+}
+
+// cacheEntry is a cached workflow instance
+type cacheEntry struct {
+	workflow *WorkflowInstance
+	expires  time.Time // Zero for terminal instances, which stay until invalidated or evicted
+}
+
+func newWorkflowCache(size int, ttl time.Duration) *workflowCache {
+	return &workflowCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		owners:  make(map[string]string),
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the cached instance, if it is present and fresh
+func (c *workflowCache) get(workflowInstID string) (*WorkflowInstance, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[workflowInstID]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.workflow.Clone(), true
+}
+
+// begin returns the version a read must present to put its result
+func (c *workflowCache) begin() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// put caches a copy of an instance read at version, unless a write has happened since
+func (c *workflowCache) put(workflow *WorkflowInstance, version uint64) {
+	var expires time.Time
+	if !workflow.IsCompleted() {
+		if c.ttl <= 0 {
+			return
+		}
+		expires = c.now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		return
+	}
+	if elem, ok := c.entries[workflow.ID]; ok {
+		c.remove(elem)
+	}
+
+	entry := &cacheEntry{workflow: workflow.Clone(), expires: expires}
+	c.entries[workflow.ID] = c.order.PushFront(entry)
+	for _, step := range workflow.Steps {
+		c.owners[step.ID] = workflow.ID
+	}
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// invalidate drops an instance from the cache. An empty ID drops nothing but still
+// keeps reads in progress from being cached.
+func (c *workflowCache) invalidate(workflowInstID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	if elem, ok := c.entries[workflowInstID]; ok {
+		c.remove(elem)
+	}
+}
+
+// owner returns the instance a cached step belongs to
+func (c *workflowCache) owner(stepInstID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	workflowInstID, ok := c.owners[stepInstID]
+	return workflowInstID, ok
+}
+
+// remove drops a cached entry and its steps; the caller holds mu
+func (c *workflowCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.workflow.ID)
+	for _, step := range entry.workflow.Steps {
+		delete(c.owners, step.ID)
+	}
+}
+
+// dbTxCacheKey is the context key for the instances a transaction of a specific
+// DBStateManager wrote, invalidated again once it commits
+type dbTxCacheKey struct {
+	manager *DBStateManager
+}
+
+// txInvalidations lists the instances written inside a transaction
+type txInvalidations struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// invalidateWorkflow drops an instance from the cache after a write. Inside a
+// transaction, the instance is dropped again on commit, so a read that raced the
+// uncommitted write cannot leave the old state cached.
+func (m *DBStateManager) invalidateWorkflow(ctx context.Context, workflowInstID string) {
+	if m.cache == nil {
+		return
+	}
+
+	m.cache.invalidate(workflowInstID)
+	if pending, ok := ctx.Value(dbTxCacheKey{m}).(*txInvalidations); ok {
+		pending.mu.Lock()
+		pending.ids = append(pending.ids, workflowInstID)
+		pending.mu.Unlock()
+	}
+}
+
+// invalidateStep drops the instance owning a step from the cache after a write to the step.
+// When the owner is not cached, the write still bumps the version, so a read of the owner
+// that began before the write cannot cache the old steps.
+func (m *DBStateManager) invalidateStep(ctx context.Context, stepInstID string) {
+	if m.cache == nil {
+		return
+	}
+
+	workflowInstID, _ := m.cache.owner(stepInstID)
+	m.invalidateWorkflow(ctx, workflowInstID)
+}
@@ -5,35 +5,84 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
+// DefaultTablePrefix is the prefix used for OrchWF table names when none is configured
+const DefaultTablePrefix = "orchwf_"
+
+// DBOptions configures a DBStateManager
+type DBOptions struct {
+	// TablePrefix is prepended to every OrchWF table name (default "orchwf_")
+	TablePrefix string
+
+	// CacheSize is the number of instances GetWorkflow keeps in an LRU cache (0 = no cache).
+	// Writes made through this manager invalidate the cache; writes from other processes do not.
+	CacheSize int
+
+	// CacheTTL is how long a pending or running instance is served from the cache. Completed,
+	// failed and cancelled instances stay until written or evicted. 0 always reads unfinished
+	// instances from the database, so their progress is never stale.
+	CacheTTL time.Duration
+}
+
 // DBStateManager implements StateManager using database/sql
 type DBStateManager struct {
-	db *sql.DB
+	db            *sql.DB
+	workflowTable string
+	stepTable     string
+	eventTable    string
+
+	cache *workflowCache // Read-through cache for GetWorkflow (nil = off)
 }
 
 // NewDBStateManager creates a new database state manager
 func NewDBStateManager(db *sql.DB) *DBStateManager {
-	return &DBStateManager{
-		db: db,
+	return NewDBStateManagerWithOptions(db, DBOptions{})
+}
+
+// NewDBStateManagerWithOptions creates a new database state manager with custom options
+func NewDBStateManagerWithOptions(db *sql.DB, opts DBOptions) *DBStateManager {
+	prefix := opts.TablePrefix
+	if prefix == "" {
+		prefix = DefaultTablePrefix
+	}
+
+	m := &DBStateManager{
+		db:            db,
+		workflowTable: prefix + "workflow_instances",
+		stepTable:     prefix + "step_instances",
+		eventTable:    prefix + "workflow_events",
+	}
+	if opts.CacheSize > 0 {
+		m.cache = newWorkflowCache(opts.CacheSize, opts.CacheTTL)
 	}
+	return m
+}
+
+// NewDBStateManagerWithCache creates a database state manager whose GetWorkflow serves
+// finished instances from an LRU cache of the given size, sparing status polls the
+// workflow and step queries
+func NewDBStateManagerWithCache(db *sql.DB, size int) *DBStateManager {
+	return NewDBStateManagerWithOptions(db, DBOptions{CacheSize: size})
 }
 
 // SaveWorkflow saves a workflow instance to the database
 func (m *DBStateManager) SaveWorkflow(ctx context.Context, workflow *WorkflowInstance) error {
-	query := `
-		INSERT INTO orchwf_workflow_instances 
+	query := fmt.Sprintf(`
+		INSERT INTO %s 
 		(id, workflow_id, status, input, output, context, current_step_id, started_at, completed_at, 
-		 error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+		 error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, parent_workflow_inst_id,
+		 created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`, m.workflowTable)
 
 	inputJSON, _ := json.Marshal(workflow.Input)
 	outputJSON, _ := json.Marshal(workflow.Output)
 	contextJSON, _ := json.Marshal(workflow.Context)
 	metadataJSON, _ := json.Marshal(workflow.Metadata)
 
-	_, err := m.db.ExecContext(ctx, query,
+	_, err := m.conn(ctx).ExecContext(ctx, query,
 		workflow.ID,
 		workflow.WorkflowID,
 		string(workflow.Status),
@@ -50,30 +99,55 @@ func (m *DBStateManager) SaveWorkflow(ctx context.Context, workflow *WorkflowIns
 		workflow.TraceID,
 		workflow.CorrelationID,
 		workflow.BusinessID,
+		stringPtr(workflow.ParentInstID),
 		time.Now(),
 		time.Now(),
 	)
 
+	m.invalidateWorkflow(ctx, workflow.ID)
 	return err
 }
 
-// GetWorkflow retrieves a workflow instance by ID
+// GetWorkflow retrieves a workflow instance by ID, from the cache when one is configured.
+// Reads inside a transaction always go to the database.
 func (m *DBStateManager) GetWorkflow(ctx context.Context, workflowInstID string) (*WorkflowInstance, error) {
-	query := `
+	if m.cache == nil || m.inTransaction(ctx) {
+		return m.loadWorkflow(ctx, workflowInstID)
+	}
+
+	if workflow, ok := m.cache.get(workflowInstID); ok {
+		return workflow, nil
+	}
+	version := m.cache.begin()
+	workflow, err := m.loadWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.put(workflow, version)
+	return workflow, nil
+}
+
+// loadWorkflow reads a workflow instance and its steps from the database
+func (m *DBStateManager) loadWorkflow(ctx context.Context, workflowInstID string) (*WorkflowInstance, error) {
+	query := fmt.Sprintf(`
 		SELECT id, workflow_id, status, input, output, context, current_step_id, started_at, completed_at,
-		       error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, created_at, updated_at
-		FROM orchwf_workflow_instances 
-		WHERE id = $1`
+		       error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, parent_workflow_inst_id,
+		       created_at, updated_at
+		FROM %s 
+		WHERE id = $1`, m.workflowTable)
 
 	var w ORCHWorkflowInstance
 	var inputJSON, outputJSON, contextJSON, metadataJSON []byte
 
-	err := m.db.QueryRowContext(ctx, query, workflowInstID).Scan(
+	err := m.conn(ctx).QueryRowContext(ctx, query, workflowInstID).Scan(
 		&w.ID, &w.WorkflowID, &w.Status, &inputJSON, &outputJSON, &contextJSON, &w.CurrentStepID,
 		&w.StartedAt, &w.CompletedAt, &w.Error, &w.RetryCount, &w.LastRetryAt, &metadataJSON,
-		&w.TraceID, &w.CorrelationID, &w.BusinessID, &w.CreatedAt, &w.UpdatedAt,
+		&w.TraceID, &w.CorrelationID, &w.BusinessID, &w.ParentInstID, &w.CreatedAt, &w.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +175,9 @@ func (m *DBStateManager) GetWorkflow(ctx context.Context, workflowInstID string)
 
 // UpdateWorkflowStatus updates the status of a workflow
 func (m *DBStateManager) UpdateWorkflowStatus(ctx context.Context, workflowInstID string, status WorkflowStatus) error {
-	query := `
-		UPDATE orchwf_workflow_instances 
-		SET status = $1, updated_at = $2`
+	query := fmt.Sprintf(`
+		UPDATE %s 
+		SET status = $1, updated_at = $2`, m.workflowTable)
 
 	args := []interface{}{string(status), time.Now()}
 
@@ -115,8 +189,9 @@ func (m *DBStateManager) UpdateWorkflowStatus(ctx context.Context, workflowInstI
 	query += ` WHERE id = $` + fmt.Sprintf("%d", len(args)+1)
 	args = append(args, workflowInstID)
 
-	_, err := m.db.ExecContext(ctx, query, args...)
-	return err
+	result, err := m.conn(ctx).ExecContext(ctx, query, args...)
+	m.invalidateWorkflow(ctx, workflowInstID)
+	return checkRowsAffected(result, err, ErrWorkflowNotFound, workflowInstID)
 }
 
 // UpdateWorkflowOutput updates the output of a workflow
@@ -126,69 +201,155 @@ func (m *DBStateManager) UpdateWorkflowOutput(ctx context.Context, workflowInstI
 		return err
 	}
 
-	query := `UPDATE orchwf_workflow_instances SET output = $1, updated_at = $2 WHERE id = $3`
-	_, err = m.db.ExecContext(ctx, query, outputJSON, time.Now(), workflowInstID)
-	return err
+	query := fmt.Sprintf(`UPDATE %s SET output = $1, updated_at = $2 WHERE id = $3`, m.workflowTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, outputJSON, time.Now(), workflowInstID)
+	m.invalidateWorkflow(ctx, workflowInstID)
+	return checkRowsAffected(result, err, ErrWorkflowNotFound, workflowInstID)
+}
+
+// UpdateWorkflowMetadata merges metadata into the stored metadata of a workflow
+func (m *DBStateManager) UpdateWorkflowMetadata(ctx context.Context, workflowInstID string, metadata map[string]interface{}) error {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET metadata = COALESCE(metadata, '{}'::jsonb) || $1::jsonb, updated_at = $2 WHERE id = $3`, m.workflowTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, metadataJSON, time.Now(), workflowInstID)
+	m.invalidateWorkflow(ctx, workflowInstID)
+	return checkRowsAffected(result, err, ErrWorkflowNotFound, workflowInstID)
+}
+
+// UpdateWorkflowCurrentStep records the step a workflow most recently started
+func (m *DBStateManager) UpdateWorkflowCurrentStep(ctx context.Context, workflowInstID string, stepID string) error {
+	query := fmt.Sprintf(`UPDATE %s SET current_step_id = $1, updated_at = $2 WHERE id = $3`, m.workflowTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, stepID, time.Now(), workflowInstID)
+	m.invalidateWorkflow(ctx, workflowInstID)
+	return checkRowsAffected(result, err, ErrWorkflowNotFound, workflowInstID)
 }
 
 // UpdateWorkflowError updates the error of a workflow
 func (m *DBStateManager) UpdateWorkflowError(ctx context.Context, workflowInstID string, err error) error {
 	errorMsg := err.Error()
-	query := `
-		UPDATE orchwf_workflow_instances 
+	query := fmt.Sprintf(`
+		UPDATE %s 
 		SET error = $1, status = $2, updated_at = $3 
-		WHERE id = $4`
-	_, err = m.db.ExecContext(ctx, query, errorMsg, string(WorkflowStatusFailed), time.Now(), workflowInstID)
-	return err
+		WHERE id = $4`, m.workflowTable)
+	result, execErr := m.conn(ctx).ExecContext(ctx, query, errorMsg, string(WorkflowStatusFailed), time.Now(), workflowInstID)
+	m.invalidateWorkflow(ctx, workflowInstID)
+	return checkRowsAffected(result, execErr, ErrWorkflowNotFound, workflowInstID)
 }
 
 // ListWorkflows lists workflows with optional filters
 func (m *DBStateManager) ListWorkflows(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*WorkflowInstance, int64, error) {
-	// Build WHERE clause
-	whereClause := ""
-	args := []interface{}{}
-	argIndex := 1
+	whereClause, args := workflowWhereClause(filters)
+	argIndex := len(args) + 1
 
-	for key, value := range filters {
+	// Get total count, unless the caller only wants the page
+	total := int64(-1)
+	if !skipTotal(ctx) {
+		countQuery := "SELECT COUNT(*) FROM " + m.workflowTable
 		if whereClause != "" {
-			whereClause += " AND "
+			countQuery += " WHERE " + whereClause
+		}
+
+		err := m.conn(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, 0, err
 		}
-		whereClause += fmt.Sprintf("%s = $%d", key, argIndex)
-		args = append(args, value)
-		argIndex++
 	}
 
-	// Get total count
-	countQuery := "SELECT COUNT(*) FROM orchwf_workflow_instances"
+	// Get paginated results
+	query := fmt.Sprintf(`
+		SELECT id, workflow_id, status, input, output, context, current_step_id, started_at, completed_at,
+		       error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, parent_workflow_inst_id,
+		       created_at, updated_at
+		FROM %s`, m.workflowTable)
+
 	if whereClause != "" {
-		countQuery += " WHERE " + whereClause
+		query += " WHERE " + whereClause
 	}
 
-	var total int64
-	err := m.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
+	rows, err := m.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
+	defer rows.Close()
 
-	// Get paginated results
-	query := `
-		SELECT id, workflow_id, status, input, output, context, current_step_id, started_at, completed_at,
-		       error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, created_at, updated_at
-		FROM orchwf_workflow_instances`
+	workflows, err := m.scanWorkflows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
 
+	return workflows, total, nil
+}
+
+// CountWorkflowsByStatus counts the workflows matching filters, grouped by status
+func (m *DBStateManager) CountWorkflowsByStatus(ctx context.Context, filters map[string]interface{}) (map[WorkflowStatus]int64, error) {
+	whereClause, args := workflowWhereClause(filters)
+
+	query := "SELECT status, COUNT(*) FROM " + m.workflowTable
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
+	query += " GROUP BY status"
 
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	rows, err := m.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	rows, err := m.db.QueryContext(ctx, query, args...)
+	counts := make(map[WorkflowStatus]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[WorkflowStatus(status)] = count
+	}
+	return counts, rows.Err()
+}
+
+// workflowWhereClause builds the ListWorkflows WHERE clause and its arguments
+func workflowWhereClause(filters map[string]interface{}) (string, []interface{}) {
+	whereClause := ""
+	args := []interface{}{}
+	for key, value := range filters {
+		if whereClause != "" {
+			whereClause += " AND "
+		}
+		args = append(args, value)
+		whereClause += fmt.Sprintf("%s = $%d", key, len(args))
+	}
+	return whereClause, args
+}
+
+// GetChildWorkflows retrieves the workflows started by the given parent instance
+func (m *DBStateManager) GetChildWorkflows(ctx context.Context, parentInstID string) ([]*WorkflowInstance, error) {
+	query := fmt.Sprintf(`
+		SELECT id, workflow_id, status, input, output, context, current_step_id, started_at, completed_at,
+		       error, retry_count, last_retry_at, metadata, trace_id, correlation_id, business_id, parent_workflow_inst_id,
+		       created_at, updated_at
+		FROM %s 
+		WHERE parent_workflow_inst_id = $1 
+		ORDER BY started_at ASC`, m.workflowTable)
+
+	rows, err := m.conn(ctx).QueryContext(ctx, query, parentInstID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
+	return m.scanWorkflows(rows)
+}
+
+// scanWorkflows scans workflow instance rows selected with the standard workflow column list
+func (m *DBStateManager) scanWorkflows(rows *sql.Rows) ([]*WorkflowInstance, error) {
 	var workflows []*WorkflowInstance
 	for rows.Next() {
 		var w ORCHWorkflowInstance
@@ -197,10 +358,10 @@ func (m *DBStateManager) ListWorkflows(ctx context.Context, filters map[string]i
 		err := rows.Scan(
 			&w.ID, &w.WorkflowID, &w.Status, &inputJSON, &outputJSON, &contextJSON, &w.CurrentStepID,
 			&w.StartedAt, &w.CompletedAt, &w.Error, &w.RetryCount, &w.LastRetryAt, &metadataJSON,
-			&w.TraceID, &w.CorrelationID, &w.BusinessID, &w.CreatedAt, &w.UpdatedAt,
+			&w.TraceID, &w.CorrelationID, &w.BusinessID, &w.ParentInstID, &w.CreatedAt, &w.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
 		// Parse JSON fields
@@ -211,53 +372,136 @@ func (m *DBStateManager) ListWorkflows(ctx context.Context, filters map[string]i
 
 		workflow, err := modelToWorkflowInstance(&w)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
 		workflows = append(workflows, workflow)
 	}
 
-	return workflows, total, nil
+	return workflows, rows.Err()
 }
 
 // SaveStep saves a step instance to the database
 func (m *DBStateManager) SaveStep(ctx context.Context, step *StepInstance) error {
-	query := `
-		INSERT INTO orchwf_step_instances 
+	query := fmt.Sprintf(`
+		INSERT INTO %s 
 		(id, step_id, workflow_inst_id, status, input, output, started_at, completed_at,
-		 error, retry_count, last_retry_at, duration_ms, execution_order, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+		 error, retry_count, last_retry_at, duration_ms, execution_order, priority, wake_at, skip_reason, ready_at, wait_ms, attachments, input_provenance, failure_kind, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`, m.stepTable)
 
 	inputJSON, _ := json.Marshal(step.Input)
 	outputJSON, _ := json.Marshal(step.Output)
+	attachmentsJSON, _ := json.Marshal(attachmentsOrEmpty(step.Attachments))
+	provenanceJSON, _ := json.Marshal(step.Provenance)
 
-	_, err := m.db.ExecContext(ctx, query,
+	_, err := m.conn(ctx).ExecContext(ctx, query,
 		step.ID, step.StepID, step.WorkflowInstID, string(step.Status),
 		inputJSON, outputJSON, step.StartedAt, step.CompletedAt,
 		step.Error, step.RetryCount, step.LastRetryAt, step.DurationMs,
-		step.ExecutionOrder, time.Now(), time.Now(),
+		step.ExecutionOrder, step.Priority, step.WakeAt, stringPtr(string(step.SkipReason)),
+		step.ReadyAt, step.WaitMs, attachmentsJSON, provenanceJSON, stringPtr(string(step.FailureKind)), time.Now(), time.Now(),
 	)
 
+	m.invalidateWorkflow(ctx, step.WorkflowInstID)
+	return err
+}
+
+// SaveSteps saves a batch of step instances with a single multi-row insert. Workflows
+// of more than stepInsertBatchSize steps are inserted in batches within one transaction.
+func (m *DBStateManager) SaveSteps(ctx context.Context, steps []*StepInstance) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var err error
+	if len(steps) <= stepInsertBatchSize {
+		query, args := m.insertStepsQuery(steps, now)
+		_, err = m.conn(ctx).ExecContext(ctx, query, args...)
+	} else {
+		// Postgres caps a statement at 65535 bind parameters, so large workflows are
+		// inserted in batches, all or none
+		err = m.WithTransaction(ctx, func(ctx context.Context) error {
+			for start := 0; start < len(steps); start += stepInsertBatchSize {
+				query, args := m.insertStepsQuery(steps[start:min(start+stepInsertBatchSize, len(steps))], now)
+				if _, err := m.conn(ctx).ExecContext(ctx, query, args...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	for _, step := range steps {
+		m.invalidateWorkflow(ctx, step.WorkflowInstID)
+	}
 	return err
 }
 
+// stepInsertBatchSize is the most steps SaveSteps inserts in one statement, keeping
+// it well under Postgres's limit of 65535 bind parameters
+const stepInsertBatchSize = 1000
+
+// insertStepsQuery builds one multi-row INSERT of steps and its arguments
+func (m *DBStateManager) insertStepsQuery(steps []*StepInstance, now time.Time) (string, []interface{}) {
+	const columnCount = 23
+	var query strings.Builder
+	fmt.Fprintf(&query, `
+		INSERT INTO %s 
+		(id, step_id, workflow_inst_id, status, input, output, started_at, completed_at,
+		 error, retry_count, last_retry_at, duration_ms, execution_order, priority, wake_at, skip_reason, ready_at, wait_ms, attachments, input_provenance, failure_kind, created_at, updated_at)
+		VALUES `, m.stepTable)
+
+	args := make([]interface{}, 0, len(steps)*columnCount)
+	for i, step := range steps {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for j := 1; j <= columnCount; j++ {
+			if j > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*columnCount+j)
+		}
+		query.WriteString(")")
+
+		inputJSON, _ := json.Marshal(step.Input)
+		outputJSON, _ := json.Marshal(step.Output)
+		attachmentsJSON, _ := json.Marshal(attachmentsOrEmpty(step.Attachments))
+		provenanceJSON, _ := json.Marshal(step.Provenance)
+
+		args = append(args,
+			step.ID, step.StepID, step.WorkflowInstID, string(step.Status),
+			inputJSON, outputJSON, step.StartedAt, step.CompletedAt,
+			step.Error, step.RetryCount, step.LastRetryAt, step.DurationMs,
+			step.ExecutionOrder, step.Priority, step.WakeAt, stringPtr(string(step.SkipReason)),
+			step.ReadyAt, step.WaitMs, attachmentsJSON, provenanceJSON, stringPtr(string(step.FailureKind)), now, now,
+		)
+	}
+	return query.String(), args
+}
+
 // GetStep retrieves a step instance by ID
 func (m *DBStateManager) GetStep(ctx context.Context, stepInstID string) (*StepInstance, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, step_id, workflow_inst_id, status, input, output, started_at, completed_at,
-		       error, retry_count, last_retry_at, duration_ms, execution_order, created_at, updated_at
-		FROM orchwf_step_instances 
-		WHERE id = $1`
+		       error, retry_count, last_retry_at, duration_ms, execution_order, priority, wake_at, skip_reason, ready_at, wait_ms, attachments, input_provenance, failure_kind, created_at, updated_at
+		FROM %s 
+		WHERE id = $1`, m.stepTable)
 
 	var s ORCHStepInstance
-	var inputJSON, outputJSON []byte
+	var inputJSON, outputJSON, attachmentsJSON, provenanceJSON []byte
 
-	err := m.db.QueryRowContext(ctx, query, stepInstID).Scan(
+	err := m.conn(ctx).QueryRowContext(ctx, query, stepInstID).Scan(
 		&s.ID, &s.StepID, &s.WorkflowInstID, &s.Status, &inputJSON, &outputJSON,
 		&s.StartedAt, &s.CompletedAt, &s.Error, &s.RetryCount, &s.LastRetryAt,
-		&s.DurationMs, &s.ExecutionOrder, &s.CreatedAt, &s.UpdatedAt,
+		&s.DurationMs, &s.ExecutionOrder, &s.Priority, &s.WakeAt, &s.SkipReason,
+		&s.ReadyAt, &s.WaitMs, &attachmentsJSON, &provenanceJSON, &s.FailureKind, &s.CreatedAt, &s.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -265,34 +509,42 @@ func (m *DBStateManager) GetStep(ctx context.Context, stepInstID string) (*StepI
 	// Parse JSON fields
 	json.Unmarshal(inputJSON, &s.Input)
 	json.Unmarshal(outputJSON, &s.Output)
+	json.Unmarshal(attachmentsJSON, &s.Attachments)
+	json.Unmarshal(provenanceJSON, &s.Provenance)
 
 	return modelToStepInstance(&s)
 }
 
 // GetWorkflowSteps retrieves all steps for a workflow
 func (m *DBStateManager) GetWorkflowSteps(ctx context.Context, workflowInstID string) ([]*StepInstance, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, step_id, workflow_inst_id, status, input, output, started_at, completed_at,
-		       error, retry_count, last_retry_at, duration_ms, execution_order, created_at, updated_at
-		FROM orchwf_step_instances 
+		       error, retry_count, last_retry_at, duration_ms, execution_order, priority, wake_at, skip_reason, ready_at, wait_ms, attachments, input_provenance, failure_kind, created_at, updated_at
+		FROM %s 
 		WHERE workflow_inst_id = $1 
-		ORDER BY execution_order ASC`
+		ORDER BY execution_order ASC`, m.stepTable)
 
-	rows, err := m.db.QueryContext(ctx, query, workflowInstID)
+	rows, err := m.conn(ctx).QueryContext(ctx, query, workflowInstID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return m.scanSteps(rows)
+}
+
+// scanSteps scans step instance rows selected with the standard step column list
+func (m *DBStateManager) scanSteps(rows *sql.Rows) ([]*StepInstance, error) {
 	var steps []*StepInstance
 	for rows.Next() {
 		var s ORCHStepInstance
-		var inputJSON, outputJSON []byte
+		var inputJSON, outputJSON, attachmentsJSON, provenanceJSON []byte
 
 		err := rows.Scan(
 			&s.ID, &s.StepID, &s.WorkflowInstID, &s.Status, &inputJSON, &outputJSON,
 			&s.StartedAt, &s.CompletedAt, &s.Error, &s.RetryCount, &s.LastRetryAt,
-			&s.DurationMs, &s.ExecutionOrder, &s.CreatedAt, &s.UpdatedAt,
+			&s.DurationMs, &s.ExecutionOrder, &s.Priority, &s.WakeAt, &s.SkipReason,
+			&s.ReadyAt, &s.WaitMs, &attachmentsJSON, &provenanceJSON, &s.FailureKind, &s.CreatedAt, &s.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -301,6 +553,8 @@ func (m *DBStateManager) GetWorkflowSteps(ctx context.Context, workflowInstID st
 		// Parse JSON fields
 		json.Unmarshal(inputJSON, &s.Input)
 		json.Unmarshal(outputJSON, &s.Output)
+		json.Unmarshal(attachmentsJSON, &s.Attachments)
+		json.Unmarshal(provenanceJSON, &s.Provenance)
 
 		step, err := modelToStepInstance(&s)
 		if err != nil {
@@ -310,12 +564,12 @@ func (m *DBStateManager) GetWorkflowSteps(ctx context.Context, workflowInstID st
 		steps = append(steps, step)
 	}
 
-	return steps, nil
+	return steps, rows.Err()
 }
 
 // UpdateStepStatus updates the status of a step
 func (m *DBStateManager) UpdateStepStatus(ctx context.Context, stepInstID string, status StepStatus) error {
-	query := `UPDATE orchwf_step_instances SET status = $1, updated_at = $2`
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, updated_at = $2`, m.stepTable)
 	args := []interface{}{string(status), time.Now()}
 
 	if status == StepStatusRunning {
@@ -323,7 +577,7 @@ func (m *DBStateManager) UpdateStepStatus(ctx context.Context, stepInstID string
 		args = append(args, time.Now())
 	}
 
-	if status == StepStatusCompleted || status == StepStatusFailed || status == StepStatusSkipped {
+	if status == StepStatusCompleted || status == StepStatusFailed || status == StepStatusSkipped || status == StepStatusCancelled {
 		query += `, completed_at = $` + fmt.Sprintf("%d", len(args)+1)
 		args = append(args, time.Now())
 	}
@@ -331,8 +585,9 @@ func (m *DBStateManager) UpdateStepStatus(ctx context.Context, stepInstID string
 	query += ` WHERE id = $` + fmt.Sprintf("%d", len(args)+1)
 	args = append(args, stepInstID)
 
-	_, err := m.db.ExecContext(ctx, query, args...)
-	return err
+	result, err := m.conn(ctx).ExecContext(ctx, query, args...)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
 }
 
 // UpdateStepOutput updates the output of a step
@@ -342,32 +597,135 @@ func (m *DBStateManager) UpdateStepOutput(ctx context.Context, stepInstID string
 		return err
 	}
 
-	query := `UPDATE orchwf_step_instances SET output = $1, updated_at = $2 WHERE id = $3`
-	_, err = m.db.ExecContext(ctx, query, outputJSON, time.Now(), stepInstID)
-	return err
+	query := fmt.Sprintf(`UPDATE %s SET output = $1, updated_at = $2 WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, outputJSON, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
 }
 
 // UpdateStepError updates the error of a step
 func (m *DBStateManager) UpdateStepError(ctx context.Context, stepInstID string, err error) error {
 	errorMsg := err.Error()
-	query := `
-		UPDATE orchwf_step_instances 
+	query := fmt.Sprintf(`
+		UPDATE %s 
 		SET error = $1, status = $2, updated_at = $3 
-		WHERE id = $4`
-	_, err = m.db.ExecContext(ctx, query, errorMsg, string(StepStatusFailed), time.Now(), stepInstID)
-	return err
+		WHERE id = $4`, m.stepTable)
+	result, execErr := m.conn(ctx).ExecContext(ctx, query, errorMsg, string(StepStatusFailed), time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, execErr, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepFailureKind records why a failed step failed
+func (m *DBStateManager) UpdateStepFailureKind(ctx context.Context, stepInstID string, kind FailureKind) error {
+	query := fmt.Sprintf(`UPDATE %s SET failure_kind = $1, updated_at = $2 WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, string(kind), time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepSkipReason marks a step as skipped and records why
+func (m *DBStateManager) UpdateStepSkipReason(ctx context.Context, stepInstID string, reason SkipReason) error {
+	now := time.Now()
+	query := fmt.Sprintf(`
+		UPDATE %s 
+		SET skip_reason = $1, status = $2, completed_at = $3, updated_at = $4 
+		WHERE id = $5`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, string(reason), string(StepStatusSkipped), now, now, stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepWait records when a step became ready and how long it waited to start
+func (m *DBStateManager) UpdateStepWait(ctx context.Context, stepInstID string, readyAt time.Time, waitMs int64) error {
+	query := fmt.Sprintf(`UPDATE %s SET ready_at = $1, wait_ms = $2, updated_at = $3 WHERE id = $4`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, readyAt, waitMs, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepRetry records how many retries a step has used and when the last one started
+func (m *DBStateManager) UpdateStepRetry(ctx context.Context, stepInstID string, retryCount int, lastRetryAt *time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET retry_count = $1, last_retry_at = $2, updated_at = $3 WHERE id = $4`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, retryCount, lastRetryAt, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// ResetStep returns a step to pending and clears the results of its previous run
+func (m *DBStateManager) ResetStep(ctx context.Context, stepInstID string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = $1, output = '{}', error = NULL, skip_reason = NULL, failure_kind = NULL, started_at = NULL, completed_at = NULL,
+		    retry_count = 0, last_retry_at = NULL, duration_ms = 0, wake_at = NULL, ready_at = NULL, wait_ms = 0, updated_at = $2
+		WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, string(StepStatusPending), time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepAttachments replaces the attachments recorded on a step
+func (m *DBStateManager) UpdateStepAttachments(ctx context.Context, stepInstID string, attachments []Attachment) error {
+	attachmentsJSON, err := json.Marshal(attachmentsOrEmpty(attachments))
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET attachments = $1, updated_at = $2 WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, attachmentsJSON, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepProvenance records the source of each key in a step's input
+func (m *DBStateManager) UpdateStepProvenance(ctx context.Context, stepInstID string, provenance map[string]string) error {
+	provenanceJSON, err := json.Marshal(provenance)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET input_provenance = $1, updated_at = $2 WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, provenanceJSON, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// UpdateStepWakeAt updates the wake time of a timer step
+func (m *DBStateManager) UpdateStepWakeAt(ctx context.Context, stepInstID string, wakeAt time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET wake_at = $1, updated_at = $2 WHERE id = $3`, m.stepTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, wakeAt, time.Now(), stepInstID)
+	m.invalidateStep(ctx, stepInstID)
+	return checkRowsAffected(result, err, ErrStepNotFound, stepInstID)
+}
+
+// GetDueWaitingSteps retrieves waiting steps whose wake time is at or before the given time
+func (m *DBStateManager) GetDueWaitingSteps(ctx context.Context, before time.Time) ([]*StepInstance, error) {
+	query := fmt.Sprintf(`
+		SELECT id, step_id, workflow_inst_id, status, input, output, started_at, completed_at,
+		       error, retry_count, last_retry_at, duration_ms, execution_order, priority, wake_at, skip_reason, ready_at, wait_ms, attachments, input_provenance, failure_kind, created_at, updated_at
+		FROM %s 
+		WHERE status = $1 AND wake_at <= $2 
+		ORDER BY wake_at ASC`, m.stepTable)
+
+	rows, err := m.conn(ctx).QueryContext(ctx, query, string(StepStatusWaiting), before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return m.scanSteps(rows)
 }
 
 // SaveEvent saves a workflow event to the database
 func (m *DBStateManager) SaveEvent(ctx context.Context, event *WorkflowEvent) error {
-	query := `
-		INSERT INTO orchwf_workflow_events 
+	query := fmt.Sprintf(`
+		INSERT INTO %s 
 		(id, workflow_inst_id, step_inst_id, event_type, event_data, timestamp, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING`, m.eventTable)
 
 	eventDataJSON, _ := json.Marshal(event.EventData)
 
-	_, err := m.db.ExecContext(ctx, query,
+	_, err := m.conn(ctx).ExecContext(ctx, query,
 		event.ID, event.WorkflowInstID, event.StepInstID, event.EventType,
 		eventDataJSON, event.Timestamp, time.Now(),
 	)
@@ -377,13 +735,13 @@ func (m *DBStateManager) SaveEvent(ctx context.Context, event *WorkflowEvent) er
 
 // GetWorkflowEvents retrieves all events for a workflow
 func (m *DBStateManager) GetWorkflowEvents(ctx context.Context, workflowInstID string) ([]*WorkflowEvent, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, workflow_inst_id, step_inst_id, event_type, event_data, timestamp, created_at
-		FROM orchwf_workflow_events 
+		FROM %s 
 		WHERE workflow_inst_id = $1 
-		ORDER BY timestamp ASC`
+		ORDER BY timestamp ASC`, m.eventTable)
 
-	rows, err := m.db.QueryContext(ctx, query, workflowInstID)
+	rows, err := m.conn(ctx).QueryContext(ctx, query, workflowInstID)
 	if err != nil {
 		return nil, err
 	}
@@ -416,13 +774,47 @@ func (m *DBStateManager) GetWorkflowEvents(ctx context.Context, workflowInstID s
 	return events, nil
 }
 
-// WithTransaction executes a function within a database transaction
-func (m *DBStateManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+// DeleteEventsOlderThan deletes events recorded before cutoff and returns how many were deleted
+func (m *DBStateManager) DeleteEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE timestamp < $1`, m.eventTable)
+	result, err := m.conn(ctx).ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// checkRowsAffected maps an update that matched no rows to the given not-found error
+func checkRowsAffected(result sql.Result, err error, notFound error, id string) error {
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", notFound, id)
+	}
+
+	return nil
+}
+
+// WithTransaction executes a function within a database transaction.
+// State manager calls made with the context passed to fn run on the transaction.
+func (m *DBStateManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	// Join an enclosing transaction instead of nesting
+	if _, ok := ctx.Value(dbTxKey{m}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
 	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
+	pending := &txInvalidations{}
 	defer func() {
 		if p := recover(); p != nil {
 			tx.Rollback()
@@ -432,14 +824,45 @@ func (m *DBStateManager) WithTransaction(ctx context.Context, fn func(ctx contex
 		} else {
 			err = tx.Commit()
 		}
+
+		// Drop what reads cached while the writes were uncommitted
+		for _, id := range pending.ids {
+			m.cache.invalidate(id)
+		}
 	}()
 
-	// Create a new state manager with the transaction
-	// Note: We need to create a wrapper that implements the same interface
-	// For now, we'll use the original state manager but execute within transaction
-	txManager := m
-	// Store the transaction manager in context
-	txCtx := context.WithValue(ctx, "state_manager", txManager)
-	err = fn(txCtx)
-	return err
+	txCtx := context.WithValue(ctx, dbTxKey{m}, tx)
+	txCtx = context.WithValue(txCtx, dbTxCacheKey{m}, pending)
+	return fn(txCtx)
+}
+
+// Ping verifies the database connection is alive
+func (m *DBStateManager) Ping(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+// dbConn is the subset of *sql.DB and *sql.Tx used to run queries
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// dbTxKey is the context key for a transaction opened by a specific DBStateManager
+type dbTxKey struct {
+	manager *DBStateManager
+}
+
+// inTransaction reports whether ctx carries a transaction opened by this manager
+func (m *DBStateManager) inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(dbTxKey{m}).(*sql.Tx)
+	return ok
+}
+
+// conn returns the transaction stored in ctx by WithTransaction, or the database otherwise
+func (m *DBStateManager) conn(ctx context.Context) dbConn {
+	if tx, ok := ctx.Value(dbTxKey{m}).(*sql.Tx); ok {
+		return tx
+	}
+	return m.db
 }
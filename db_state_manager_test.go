@@ -1,6 +1,13 @@
 package orchwf
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -111,6 +118,7 @@ func TestDBStateManager_StepConversions(t *testing.T) {
 		LastRetryAt:    &now,
 		DurationMs:     1000,
 		ExecutionOrder: 1,
+		Attachments:    []Attachment{{Name: "export.csv", ContentType: "text/csv", Ref: "blob-1", Size: 42}},
 	}
 
 	// Test conversion to model
@@ -127,6 +135,9 @@ func TestDBStateManager_StepConversions(t *testing.T) {
 	if convertedStep.ID != "test-step" {
 		t.Errorf("modelToStepInstance() ID = %v, want %v", convertedStep.ID, "test-step")
 	}
+	if len(convertedStep.Attachments) != 1 || convertedStep.Attachments[0].Ref != "blob-1" {
+		t.Errorf("modelToStepInstance() Attachments = %v, want blob-1", convertedStep.Attachments)
+	}
 }
 
 func TestDBStateManager_EventConversions(t *testing.T) {
@@ -162,3 +173,191 @@ func TestDBStateManager_WithTransaction(t *testing.T) {
 	// The WithTransaction method will panic with nil db, so we'll test the interface compliance instead
 	t.Skip("Skipping WithTransaction test as it requires a real database connection")
 }
+
+func TestDBStateManager_TablePrefix(t *testing.T) {
+	manager := NewDBStateManager(nil)
+	if manager.workflowTable != "orchwf_workflow_instances" {
+		t.Errorf("NewDBStateManager() workflowTable = %v, want %v", manager.workflowTable, "orchwf_workflow_instances")
+	}
+
+	manager = NewDBStateManagerWithOptions(nil, DBOptions{TablePrefix: "myapp_"})
+	tables := map[string]string{
+		manager.workflowTable: "myapp_workflow_instances",
+		manager.stepTable:     "myapp_step_instances",
+		manager.eventTable:    "myapp_workflow_events",
+	}
+	for got, want := range tables {
+		if got != want {
+			t.Errorf("NewDBStateManagerWithOptions() table = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDBStateManager_ConnUsesOwnTransaction(t *testing.T) {
+	manager := NewDBStateManager(nil)
+	other := NewDBStateManager(nil)
+	tx := &sql.Tx{}
+
+	if conn := manager.conn(context.Background()); conn != dbConn(manager.db) {
+		t.Errorf("conn() without transaction should use the database")
+	}
+
+	ctx := context.WithValue(context.Background(), dbTxKey{manager}, tx)
+	if conn := manager.conn(ctx); conn != dbConn(tx) {
+		t.Errorf("conn() should use the transaction from context")
+	}
+	if conn := other.conn(ctx); conn != dbConn(other.db) {
+		t.Errorf("conn() should ignore transactions opened by another manager")
+	}
+}
+
+// recordingConnector is a database/sql connector that records the statements run on it
+type recordingConnector struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{c}, nil
+}
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func (c *recordingConnector) record(entry string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = append(c.log, entry)
+}
+
+type recordingConn struct{ c *recordingConnector }
+
+func (conn recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (conn recordingConn) Close() error { return nil }
+func (conn recordingConn) Begin() (driver.Tx, error) {
+	conn.c.record("begin")
+	return conn, nil
+}
+func (conn recordingConn) Commit() error   { conn.c.record("commit"); return nil }
+func (conn recordingConn) Rollback() error { conn.c.record("rollback"); return nil }
+func (conn recordingConn) ExecContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Result, error) {
+	conn.c.record(fmt.Sprintf("insert %d", len(args)))
+	return driver.RowsAffected(0), nil
+}
+
+func TestDBStateManager_SaveStepsBatches(t *testing.T) {
+	steps := func(n int) []*StepInstance {
+		steps := make([]*StepInstance, n)
+		for i := range steps {
+			steps[i] = &StepInstance{ID: fmt.Sprintf("step-%d", i), StepID: "step", WorkflowInstID: "wf1", Status: StepStatusPending}
+		}
+		return steps
+	}
+	tests := []struct {
+		name  string
+		steps int
+		want  []string
+	}{
+		{"one batch fits in a statement", stepInsertBatchSize, []string{"insert 23000"}},
+		{"past the batch size in one transaction", 2*stepInsertBatchSize + 1, []string{"begin", "insert 23000", "insert 23000", "insert 23", "commit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &recordingConnector{}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			if err := NewDBStateManager(db).SaveSteps(context.Background(), steps(tt.steps)); err != nil {
+				t.Fatalf("SaveSteps() error = %v", err)
+			}
+			if !reflect.DeepEqual(connector.log, tt.want) {
+				t.Errorf("statements = %v, want %v", connector.log, tt.want)
+			}
+		})
+	}
+}
+
+func TestDBStateManager_WorkflowCache(t *testing.T) {
+	now := time.Now()
+	cache := newWorkflowCache(2, time.Second)
+	cache.now = func() time.Time { return now }
+	workflow := func(id string, status WorkflowStatus) *WorkflowInstance {
+		return &WorkflowInstance{ID: id, Status: status, Steps: []*StepInstance{{ID: id + "-step", WorkflowInstID: id}}}
+	}
+
+	// Finished instances stay cached; unfinished ones expire after the TTL
+	cache.put(workflow("done", WorkflowStatusCompleted), cache.begin())
+	cache.put(workflow("running", WorkflowStatusRunning), cache.begin())
+	now = now.Add(2 * time.Second)
+	if _, ok := cache.get("done"); !ok {
+		t.Errorf("get() should serve a completed instance until it is written")
+	}
+	if _, ok := cache.get("running"); ok {
+		t.Errorf("get() should not serve a running instance after the TTL")
+	}
+
+	// Callers get copies they can change freely
+	got, _ := cache.get("done")
+	got.Status = WorkflowStatusFailed
+	if again, _ := cache.get("done"); again.Status != WorkflowStatusCompleted {
+		t.Errorf("get() returned the cached instance itself")
+	}
+
+	// The least recently used instance is evicted past the size
+	cache.put(workflow("a", WorkflowStatusFailed), cache.begin())
+	cache.get("done")
+	cache.put(workflow("b", WorkflowStatusFailed), cache.begin())
+	if _, ok := cache.get("a"); ok {
+		t.Errorf("get() should miss the least recently used instance")
+	}
+	if _, ok := cache.owner("a-step"); ok {
+		t.Errorf("owner() should forget the steps of evicted instances")
+	}
+
+	// A read that raced a write is not cached
+	version := cache.begin()
+	cache.invalidate("c")
+	cache.put(workflow("c", WorkflowStatusCompleted), version)
+	if _, ok := cache.get("c"); ok {
+		t.Errorf("put() should drop a read that started before a write")
+	}
+}
+
+func TestDBStateManager_CacheInvalidation(t *testing.T) {
+	manager := NewDBStateManagerWithCache(nil, 10)
+	manager.cache.put(&WorkflowInstance{
+		ID:     "wf1",
+		Status: WorkflowStatusCompleted,
+		Steps:  []*StepInstance{{ID: "step1", WorkflowInstID: "wf1"}},
+	}, manager.cache.begin())
+
+	// A step write drops the instance the step belongs to
+	manager.invalidateStep(context.Background(), "step1")
+	if _, ok := manager.cache.get("wf1"); ok {
+		t.Errorf("invalidateStep() should drop the owning instance")
+	}
+
+	// A step write keeps a read of its uncached owner that raced it from being cached
+	version := manager.cache.begin()
+	manager.invalidateStep(context.Background(), "step2")
+	manager.cache.put(&WorkflowInstance{
+		ID:     "wf3",
+		Status: WorkflowStatusCompleted,
+		Steps:  []*StepInstance{{ID: "step2", WorkflowInstID: "wf3"}},
+	}, version)
+	if _, ok := manager.cache.get("wf3"); ok {
+		t.Errorf("put() should drop a read that started before a write to one of its steps")
+	}
+
+	// Writes inside a transaction are recorded to be dropped again on commit
+	pending := &txInvalidations{}
+	ctx := context.WithValue(context.Background(), dbTxCacheKey{manager}, pending)
+	manager.invalidateWorkflow(ctx, "wf2")
+	if len(pending.ids) != 1 || pending.ids[0] != "wf2" {
+		t.Errorf("transaction invalidations = %v, want [wf2]", pending.ids)
+	}
+
+	if NewDBStateManager(nil).cache != nil {
+		t.Errorf("NewDBStateManager() should not cache")
+	}
+}
@@ -0,0 +1,131 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BlockedStep is a step that has not run, with the dependencies it is still waiting on
+type BlockedStep struct {
+	StepID            string
+	Status            StepStatus
+	UnmetDependencies []string   // Dependencies that have not completed or been skipped
+	WakeAt            *time.Time // Set for timer steps waiting for their wake time
+}
+
+// Diagnosis explains which steps of a workflow instance have not run and why
+type Diagnosis struct {
+	WorkflowInstID string
+	Status         WorkflowStatus
+	Error          *string
+	BlockedSteps   []BlockedStep
+}
+
+// DiagnoseWorkflow reports the pending and waiting steps of a workflow instance and the
+// dependencies each is blocked on, to find out why a workflow is stuck
+func (o *Orchestrator) DiagnoseWorkflow(ctx context.Context, workflowInstID string) (*Diagnosis, error) {
+	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := o.GetWorkflow(instance.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := o.stateManager.GetWorkflowSteps(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnose(instance, workflow, steps), nil
+}
+
+// diagnose lists the steps that have not run along with their unmet dependencies
+func diagnose(instance *WorkflowInstance, workflow *WorkflowDefinition, steps []*StepInstance) *Diagnosis {
+	stepInstMap := make(map[string]*StepInstance)
+	for _, stepInst := range steps {
+		stepInstMap[stepInst.StepID] = stepInst
+	}
+
+	diagnosis := &Diagnosis{
+		WorkflowInstID: instance.ID,
+		Status:         instance.Status,
+		Error:          instance.Error,
+	}
+
+	for _, stepDef := range workflow.Steps {
+		status := StepStatusPending
+		var wakeAt *time.Time
+		if stepInst, ok := stepInstMap[stepDef.ID]; ok {
+			status, wakeAt = stepInst.Status, stepInst.WakeAt
+		}
+		if status != StepStatusPending && status != StepStatusWaiting {
+			continue
+		}
+
+		diagnosis.BlockedSteps = append(diagnosis.BlockedSteps, BlockedStep{
+			StepID:            stepDef.ID,
+			Status:            status,
+			UnmetDependencies: unmetDependencies(workflow, stepDef, instance.Input, stepInstMap),
+			WakeAt:            wakeAt,
+		})
+	}
+
+	return diagnosis
+}
+
+// unmetDependencies lists the dependencies of a step that have not completed or been skipped
+func unmetDependencies(workflow *WorkflowDefinition, stepDef *StepDefinition, input map[string]interface{}, stepInstMap map[string]*StepInstance) []string {
+	var unmet []string
+	for _, dep := range stepDependencies(workflow, stepDef, input) {
+		depInst, ok := stepInstMap[dep]
+		if !ok || (depInst.Status != StepStatusCompleted && depInst.Status != StepStatusSkipped) {
+			unmet = append(unmet, dep)
+		}
+	}
+	return unmet
+}
+
+// unsatisfiableSteps describes each step that has not run and never can, with the dependencies
+// it is missing. Steps held back by a waiting timer step, directly or through their
+// dependencies, are left out since they run once the timer fires.
+func unsatisfiableSteps(workflow *WorkflowDefinition, graph map[string][]string, executed, waiting map[string]bool) []string {
+	held := make(map[string]bool)
+	for stepID := range waiting {
+		held[stepID] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, step := range workflow.Steps {
+			if executed[step.ID] || held[step.ID] {
+				continue
+			}
+			for _, dep := range graph[step.ID] {
+				if held[dep] {
+					held[step.ID] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var stuck []string
+	for _, step := range workflow.Steps {
+		if executed[step.ID] || held[step.ID] {
+			continue
+		}
+		var missing []string
+		for _, dep := range graph[step.ID] {
+			if !executed[dep] {
+				missing = append(missing, dep)
+			}
+		}
+		stuck = append(stuck, fmt.Sprintf("%s (missing %s)", step.ID, strings.Join(missing, ", ")))
+	}
+	return stuck
+}
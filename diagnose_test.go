@@ -0,0 +1,128 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_DeadlockFailsWorkflow(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	// ping and pong depend on each other, so neither can ever become ready
+	workflow, _ := NewWorkflowBuilder("cyclic", "Cyclic").
+		AddStepFunc("start", "Start", executor).
+		AddStepFunc("ping", "Ping", executor, WithStepDeps("start", "pong")).
+		AddStepFunc("pong", "Pong", executor, WithStepDeps("ping")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "cyclic", nil, nil)
+	if !errors.Is(err, ErrWorkflowDeadlock) {
+		t.Fatalf("StartWorkflow() error = %v, want ErrWorkflowDeadlock", err)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusFailed {
+		t.Errorf("workflow status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusFailed)
+	}
+
+	diagnosis, err := orchestrator.DiagnoseWorkflow(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("DiagnoseWorkflow() error = %v", err)
+	}
+	if len(diagnosis.BlockedSteps) != 2 {
+		t.Fatalf("DiagnoseWorkflow() blocked steps = %+v, want ping and pong", diagnosis.BlockedSteps)
+	}
+
+	unmet := make(map[string][]string)
+	for _, blocked := range diagnosis.BlockedSteps {
+		unmet[blocked.StepID] = blocked.UnmetDependencies
+	}
+	if len(unmet["ping"]) != 1 || unmet["ping"][0] != "pong" {
+		t.Errorf("ping unmet dependencies = %v, want [pong]", unmet["ping"])
+	}
+	if len(unmet["pong"]) != 1 || unmet["pong"][0] != "ping" {
+		t.Errorf("pong unmet dependencies = %v, want [ping]", unmet["pong"])
+	}
+}
+
+func TestOrchestrator_DeadlockReportsMissingDependencies(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name      string
+		steps     []*StepDefinition
+		wantError string
+	}{
+		{
+			// A definition registered without Build can name a dependency that is not a step
+			name: "dependency never added as a step",
+			steps: []*StepDefinition{
+				{ID: "notify", Executor: executor, Required: true},
+				{ID: "ship", Executor: executor, Required: true, Dependencies: []string{"notify", "pack"}},
+			},
+			wantError: "ship (missing pack) can never become ready",
+		},
+		{
+			// A waiting timer step does not hide steps that are stuck regardless of it
+			name: "stuck next to a waiting timer",
+			steps: []*StepDefinition{
+				{ID: "sleep", Executor: executor, Required: true, TimerUntil: func(input map[string]interface{}) time.Time {
+					return time.Now().Add(time.Hour)
+				}},
+				{ID: "after", Executor: executor, Required: true, Dependencies: []string{"sleep"}},
+				{ID: "ship", Executor: executor, Required: true, Dependencies: []string{"pack"}},
+			},
+			wantError: "ship (missing pack) can never become ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+			orchestrator.RegisterWorkflow(&WorkflowDefinition{ID: "unsatisfiable", Name: "Unsatisfiable", Steps: tt.steps})
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "unsatisfiable", nil, nil)
+			if !errors.Is(err, ErrWorkflowDeadlock) || !strings.Contains(err.Error(), tt.wantError) {
+				t.Fatalf("StartWorkflow() error = %v, want ErrWorkflowDeadlock naming %q", err, tt.wantError)
+			}
+			if result.WorkflowInst.Status != WorkflowStatusFailed {
+				t.Errorf("workflow status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusFailed)
+			}
+			if strings.Contains(err.Error(), "after") {
+				t.Errorf("StartWorkflow() error = %v, should not name steps held back by a timer", err)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_DiagnoseCompletedWorkflow(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	workflow, _ := NewWorkflowBuilder("simple", "Simple").
+		AddStepFunc("only", "Only", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, _ := orchestrator.StartWorkflow(context.Background(), "simple", nil, nil)
+	diagnosis, err := orchestrator.DiagnoseWorkflow(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("DiagnoseWorkflow() error = %v", err)
+	}
+	if diagnosis.Status != WorkflowStatusCompleted || len(diagnosis.BlockedSteps) != 0 {
+		t.Errorf("DiagnoseWorkflow() = %+v, want completed with no blocked steps", diagnosis)
+	}
+
+	if _, err := orchestrator.DiagnoseWorkflow(context.Background(), "missing"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("DiagnoseWorkflow() on missing instance error = %v, want ErrWorkflowNotFound", err)
+	}
+}
@@ -0,0 +1,161 @@
+package orchwf
+
+import "time"
+
+// WorkflowInstanceDTO is the stable JSON representation of a WorkflowInstance.
+// Its snake_case keys do not change when WorkflowInstance gains fields.
+type WorkflowInstanceDTO struct {
+	ID                   string                 `json:"id"`
+	WorkflowID           string                 `json:"workflow_id"`
+	Status               WorkflowStatus         `json:"status"`
+	Input                map[string]interface{} `json:"input"`
+	Output               map[string]interface{} `json:"output"`
+	Context              map[string]interface{} `json:"context"`
+	CurrentStepID        string                 `json:"current_step_id,omitempty"`
+	Steps                []StepInstanceDTO      `json:"steps"`
+	StartedAt            time.Time              `json:"started_at"`
+	CompletedAt          *time.Time             `json:"completed_at,omitempty"`
+	Error                string                 `json:"error,omitempty"`
+	RetryCount           int                    `json:"retry_count"`
+	LastRetryAt          *time.Time             `json:"last_retry_at,omitempty"`
+	Metadata             map[string]interface{} `json:"metadata,omitempty"`
+	TraceID              string                 `json:"trace_id,omitempty"`
+	CorrelationID        string                 `json:"correlation_id,omitempty"`
+	BusinessID           string                 `json:"business_id,omitempty"`
+	ParentWorkflowInstID string                 `json:"parent_workflow_inst_id,omitempty"`
+}
+
+// StepInstanceDTO is the stable JSON representation of a StepInstance
+type StepInstanceDTO struct {
+	ID             string                 `json:"id"`
+	StepID         string                 `json:"step_id"`
+	WorkflowInstID string                 `json:"workflow_inst_id"`
+	Status         StepStatus             `json:"status"`
+	Input          map[string]interface{} `json:"input,omitempty"`
+	Output         map[string]interface{} `json:"output,omitempty"`
+	StartedAt      *time.Time             `json:"started_at,omitempty"`
+	CompletedAt    *time.Time             `json:"completed_at,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	RetryCount     int                    `json:"retry_count"`
+	LastRetryAt    *time.Time             `json:"last_retry_at,omitempty"`
+	DurationMs     int64                  `json:"duration_ms"`
+	ExecutionOrder int                    `json:"execution_order"`
+	Priority       int                    `json:"priority"`
+	WakeAt         *time.Time             `json:"wake_at,omitempty"`
+	SkipReason     SkipReason             `json:"skip_reason,omitempty"`
+	FailureKind    FailureKind            `json:"failure_kind,omitempty"`
+	ReadyAt        *time.Time             `json:"ready_at,omitempty"`
+	WaitMs         int64                  `json:"wait_ms"`
+	Attachments    []Attachment           `json:"attachments,omitempty"`
+	Provenance     map[string]string      `json:"provenance,omitempty"`
+}
+
+// ToDTO converts the workflow instance and its steps to their wire format
+func (w *WorkflowInstance) ToDTO() *WorkflowInstanceDTO {
+	dto := &WorkflowInstanceDTO{
+		ID:                   w.ID,
+		WorkflowID:           w.WorkflowID,
+		Status:               w.Status,
+		Input:                w.Input,
+		Output:               w.Output,
+		Context:              w.Context,
+		CurrentStepID:        w.CurrentStepID,
+		Steps:                make([]StepInstanceDTO, 0, len(w.Steps)),
+		StartedAt:            w.StartedAt,
+		CompletedAt:          w.CompletedAt,
+		Error:                stringValue(w.Error),
+		RetryCount:           w.RetryCount,
+		LastRetryAt:          w.LastRetryAt,
+		Metadata:             w.Metadata,
+		TraceID:              w.TraceID,
+		CorrelationID:        w.CorrelationID,
+		BusinessID:           w.BusinessID,
+		ParentWorkflowInstID: w.ParentInstID,
+	}
+	for _, step := range w.Steps {
+		dto.Steps = append(dto.Steps, *step.ToDTO())
+	}
+	return dto
+}
+
+// FromDTO converts a wire-format workflow instance back into a WorkflowInstance
+func FromDTO(dto *WorkflowInstanceDTO) *WorkflowInstance {
+	w := &WorkflowInstance{
+		ID:            dto.ID,
+		WorkflowID:    dto.WorkflowID,
+		Status:        dto.Status,
+		Input:         dto.Input,
+		Output:        dto.Output,
+		Context:       dto.Context,
+		CurrentStepID: dto.CurrentStepID,
+		Steps:         make([]*StepInstance, 0, len(dto.Steps)),
+		StartedAt:     dto.StartedAt,
+		CompletedAt:   dto.CompletedAt,
+		Error:         stringPtr(dto.Error),
+		RetryCount:    dto.RetryCount,
+		LastRetryAt:   dto.LastRetryAt,
+		Metadata:      dto.Metadata,
+		TraceID:       dto.TraceID,
+		CorrelationID: dto.CorrelationID,
+		BusinessID:    dto.BusinessID,
+		ParentInstID:  dto.ParentWorkflowInstID,
+	}
+	for i := range dto.Steps {
+		w.Steps = append(w.Steps, stepFromDTO(&dto.Steps[i]))
+	}
+	return w
+}
+
+// ToDTO converts the step instance to its wire format
+func (s *StepInstance) ToDTO() *StepInstanceDTO {
+	return &StepInstanceDTO{
+		ID:             s.ID,
+		StepID:         s.StepID,
+		WorkflowInstID: s.WorkflowInstID,
+		Status:         s.Status,
+		Input:          s.Input,
+		Output:         s.Output,
+		StartedAt:      s.StartedAt,
+		CompletedAt:    s.CompletedAt,
+		Error:          stringValue(s.Error),
+		RetryCount:     s.RetryCount,
+		LastRetryAt:    s.LastRetryAt,
+		DurationMs:     s.DurationMs,
+		ExecutionOrder: s.ExecutionOrder,
+		Priority:       s.Priority,
+		WakeAt:         s.WakeAt,
+		SkipReason:     s.SkipReason,
+		FailureKind:    s.FailureKind,
+		ReadyAt:        s.ReadyAt,
+		WaitMs:         s.WaitMs,
+		Attachments:    s.Attachments,
+		Provenance:     s.Provenance,
+	}
+}
+
+// stepFromDTO converts a wire-format step instance back into a StepInstance
+func stepFromDTO(dto *StepInstanceDTO) *StepInstance {
+	return &StepInstance{
+		ID:             dto.ID,
+		StepID:         dto.StepID,
+		WorkflowInstID: dto.WorkflowInstID,
+		Status:         dto.Status,
+		Input:          dto.Input,
+		Output:         dto.Output,
+		StartedAt:      dto.StartedAt,
+		CompletedAt:    dto.CompletedAt,
+		Error:          stringPtr(dto.Error),
+		RetryCount:     dto.RetryCount,
+		LastRetryAt:    dto.LastRetryAt,
+		DurationMs:     dto.DurationMs,
+		ExecutionOrder: dto.ExecutionOrder,
+		Priority:       dto.Priority,
+		WakeAt:         dto.WakeAt,
+		SkipReason:     dto.SkipReason,
+		FailureKind:    dto.FailureKind,
+		ReadyAt:        dto.ReadyAt,
+		WaitMs:         dto.WaitMs,
+		Attachments:    dto.Attachments,
+		Provenance:     dto.Provenance,
+	}
+}
@@ -0,0 +1,110 @@
+package orchwf
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// dtoFixture is a workflow instance with every field set
+func dtoFixture() *WorkflowInstance {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	completed := started.Add(2 * time.Second)
+	return &WorkflowInstance{
+		ID:            "inst-1",
+		WorkflowID:    "order",
+		Status:        WorkflowStatusFailed,
+		Input:         map[string]interface{}{"order_id": "o-1"},
+		Output:        map[string]interface{}{"charged": true},
+		Context:       map[string]interface{}{"charged": true},
+		CurrentStepID: "ship",
+		StartedAt:     started,
+		CompletedAt:   &completed,
+		Error:         stringPtr("ship: carrier unavailable"),
+		RetryCount:    1,
+		Metadata:      map[string]interface{}{"tenant": "acme"},
+		TraceID:       "trace-1",
+		CorrelationID: "corr-1",
+		BusinessID:    "o-1",
+		ParentInstID:  "parent-1",
+		Steps: []*StepInstance{
+			{
+				ID:             "step-1",
+				StepID:         "charge",
+				WorkflowInstID: "inst-1",
+				Status:         StepStatusCompleted,
+				Output:         map[string]interface{}{"charged": true},
+				StartedAt:      &started,
+				CompletedAt:    &completed,
+				DurationMs:     2000,
+				ExecutionOrder: 1,
+				Priority:       5,
+			},
+			{
+				ID:             "step-2",
+				StepID:         "ship",
+				WorkflowInstID: "inst-1",
+				Status:         StepStatusFailed,
+				Error:          stringPtr("carrier unavailable"),
+				RetryCount:     2,
+				ExecutionOrder: 2,
+			},
+		},
+	}
+}
+
+func TestWorkflowInstanceDTO_Golden(t *testing.T) {
+	got, err := json.MarshalIndent(dtoFixture().ToDTO(), "", "  ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error = %v", err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "workflow_instance_dto.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("DTO JSON changed; run go test -run TestWorkflowInstanceDTO_Golden -update if intended\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWorkflowInstanceDTO_RoundTrip(t *testing.T) {
+	original := dtoFixture()
+
+	data, err := json.Marshal(original.ToDTO())
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var dto WorkflowInstanceDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	restored := FromDTO(&dto)
+	if !reflect.DeepEqual(restored, original) {
+		t.Errorf("FromDTO(ToDTO()) = %+v, want %+v", restored, original)
+	}
+
+	// The instance's own tags produce the same shape as the DTO
+	direct, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(direct) != string(data) {
+		t.Errorf("WorkflowInstance JSON = %s, want DTO JSON %s", direct, data)
+	}
+}
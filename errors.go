@@ -0,0 +1,36 @@
+package orchwf
+
+import "errors"
+
+// Sentinel errors returned by the orchestrator and state managers.
+// Use errors.Is to check for them, as they are usually wrapped with the offending ID.
+var (
+	ErrWorkflowNotFound      = errors.New("workflow not found")
+	ErrStepNotFound          = errors.New("step not found")
+	ErrWorkflowAlreadyExists = errors.New("workflow already exists")
+	ErrLimitExceeded         = errors.New("limit exceeded")
+	ErrExecutorNotFound      = errors.New("executor not registered")
+	ErrNoWorkflowContext     = errors.New("context does not belong to a running workflow")
+	ErrInvalidTransition     = errors.New("invalid step status transition")
+	ErrWorkflowDeadlock      = errors.New("workflow deadlocked")
+	ErrNoBlobStore           = errors.New("no blob store configured")
+	ErrBlobNotFound          = errors.New("blob not found")
+	ErrInvalidRetryPolicy    = errors.New("invalid retry policy")
+	ErrShutdown              = errors.New("orchestrator is shut down")
+	ErrConcurrencyKeyBusy    = errors.New("concurrency key is held by a running instance")
+	ErrStateManagerDown      = errors.New("state manager writes keep failing")
+	ErrInvalidDependency     = errors.New("invalid step dependency")
+	ErrInvalidStepID         = errors.New("invalid step ID")
+	ErrInsufficientBudget    = errors.New("insufficient time budget")
+	ErrMaxDurationExceeded   = errors.New("step exceeded max duration")
+	ErrWorkflowCancelled     = errors.New("workflow cancelled")
+	ErrWorkflowFinished      = errors.New("workflow already finished")
+	ErrWorkflowNotLocal      = errors.New("workflow is running in another process")
+	ErrStartRateLimited      = errors.New("workflow start rate limited")
+	ErrStrictOrderViolated   = errors.New("steps of a strictly ordered workflow overlapped")
+	ErrInputTooLarge         = errors.New("step input too large")
+	ErrOptionalStepsFailed   = errors.New("optional steps failed")
+	ErrStepUnreachable       = errors.New("step can no longer reach the status")
+	ErrStepPanicked          = errors.New("step executor panicked")
+	ErrMissingOutputKeys     = errors.New("step output is missing required keys")
+)
@@ -0,0 +1,132 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// estimateSampleSize is how many completed instances EstimateDuration reads
+const estimateSampleSize = 100
+
+// EstimatedDuration is the expected duration of a workflow, derived from the step
+// durations of its completed instances and its dependency graph
+type EstimatedDuration struct {
+	WorkflowID   string
+	Samples      int           // Completed instances the estimate is based on (0 = no history)
+	Mean         time.Duration // Critical path through the mean duration of each step
+	P50          time.Duration // Median critical path across the sampled instances
+	P95          time.Duration // 95th percentile critical path across the sampled instances
+	CriticalPath []string      // Steps on the Mean critical path, in execution order
+}
+
+// EstimateDuration estimates how long a registered workflow takes from the persisted
+// DurationMs of the steps of up to 100 of its completed instances, as listed by the state
+// manager. Steps in parallel branches overlap, so each estimate is the longest path
+// through the dependency graph rather than the sum of the steps. Time a step spends
+// waiting, queued or between retries is not counted. Without history the estimate is
+// zero with Samples 0, not an error.
+func (o *Orchestrator) EstimateDuration(ctx context.Context, workflowID string) (EstimatedDuration, error) {
+	estimate := EstimatedDuration{WorkflowID: workflowID}
+	workflow, err := o.GetWorkflow(workflowID)
+	if err != nil {
+		return estimate, err
+	}
+
+	instances, _, err := o.stateManager.ListWorkflows(WithoutTotal(ctx), map[string]interface{}{
+		"workflow_id": workflowID,
+		"status":      WorkflowStatusCompleted,
+	}, estimateSampleSize, 0)
+	if err != nil {
+		return estimate, fmt.Errorf("failed to list completed instances: %w", err)
+	}
+
+	var paths []time.Duration
+	var graph map[string][]string
+	totals := make(map[string]time.Duration) // Step ID -> summed duration across samples
+	for _, instance := range instances {
+		steps, err := o.stateManager.GetWorkflowSteps(ctx, instance.ID)
+		if err != nil {
+			return estimate, fmt.Errorf("failed to load steps of %s: %w", instance.ID, err)
+		}
+		// Dynamic dependencies resolve against each instance's own input
+		runGraph, err := o.buildDependencyGraph(workflow, instance.Input)
+		if err != nil {
+			continue
+		}
+
+		durations := make(map[string]time.Duration, len(steps))
+		for _, stepInst := range steps {
+			durations[stepInst.StepID] = time.Duration(stepInst.DurationMs) * time.Millisecond
+		}
+		length, _ := criticalPath(workflow, runGraph, durations)
+		paths = append(paths, length)
+		for stepID, d := range durations {
+			totals[stepID] += d
+		}
+		if graph == nil {
+			graph = runGraph
+		}
+	}
+	if len(paths) == 0 {
+		return estimate, nil
+	}
+
+	means := make(map[string]time.Duration, len(totals))
+	for stepID, total := range totals {
+		means[stepID] = total / time.Duration(len(paths))
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+
+	estimate.Samples = len(paths)
+	estimate.Mean, estimate.CriticalPath = criticalPath(workflow, graph, means)
+	estimate.P50 = percentileDuration(paths, 0.50)
+	estimate.P95 = percentileDuration(paths, 0.95)
+	return estimate, nil
+}
+
+// criticalPath returns the length of the longest path through the dependency graph,
+// weighting each step by its duration, and the steps on it in execution order
+func criticalPath(workflow *WorkflowDefinition, graph map[string][]string, durations map[string]time.Duration) (time.Duration, []string) {
+	finish := make(map[string]time.Duration, len(graph)) // Step ID -> end of its longest path
+	via := make(map[string]string, len(graph))           // Step ID -> dependency on that path
+	var visit func(stepID string) time.Duration
+	visit = func(stepID string) time.Duration {
+		if end, ok := finish[stepID]; ok {
+			return end
+		}
+		var start time.Duration
+		for _, dep := range graph[stepID] {
+			if end := visit(dep); end > start || via[stepID] == "" {
+				start, via[stepID] = end, dep
+			}
+		}
+		finish[stepID] = start + durations[stepID]
+		return finish[stepID]
+	}
+
+	var length time.Duration
+	var last string
+	for _, step := range workflow.Steps {
+		if end := visit(step.ID); end > length || last == "" {
+			length, last = end, step.ID
+		}
+	}
+
+	var path []string
+	for stepID := last; stepID != ""; stepID = via[stepID] {
+		path = append([]string{stepID}, path...)
+	}
+	return length, path
+}
+
+// percentileDuration returns the nearest-rank percentile p of sorted durations
+func percentileDuration(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,98 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_EstimateDuration(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	noop := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	workflow, _ := NewWorkflowBuilder("estimated", "Estimated").
+		AddStepFunc("fetch", "Fetch", noop).
+		AddStepFunc("score", "Score", noop).
+		AddStepFunc("decide", "Decide", noop, WithStepDeps("fetch", "score")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	estimate, err := orchestrator.EstimateDuration(ctx, "estimated")
+	if err != nil || estimate.Samples != 0 || estimate.P95 != 0 {
+		t.Fatalf("EstimateDuration() without history = %+v, %v, want zero samples and no error", estimate, err)
+	}
+
+	// fetch and score run in parallel before decide; score is usually the slower branch
+	runs := []map[string]int64{
+		{"fetch": 100, "score": 300, "decide": 50},
+		{"fetch": 100, "score": 300, "decide": 50},
+		{"fetch": 100, "score": 900, "decide": 50},
+	}
+	for i, durations := range runs {
+		saveEstimateRun(t, sm, fmt.Sprintf("run-%d", i), WorkflowStatusCompleted, durations)
+	}
+	saveEstimateRun(t, sm, "failed-run", WorkflowStatusFailed, map[string]int64{"fetch": 5000, "score": 1, "decide": 1})
+
+	estimate, err = orchestrator.EstimateDuration(ctx, "estimated")
+	if err != nil {
+		t.Fatalf("EstimateDuration() error = %v", err)
+	}
+	want := EstimatedDuration{
+		WorkflowID:   "estimated",
+		Samples:      3,
+		Mean:         550 * time.Millisecond,
+		P50:          350 * time.Millisecond,
+		P95:          950 * time.Millisecond,
+		CriticalPath: []string{"score", "decide"},
+	}
+	if !reflect.DeepEqual(estimate, want) {
+		t.Errorf("EstimateDuration() = %+v, want %+v", estimate, want)
+	}
+
+	if _, err := orchestrator.EstimateDuration(ctx, "unregistered"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("EstimateDuration(unregistered) error = %v, want %v", err, ErrWorkflowNotFound)
+	}
+}
+
+// saveEstimateRun saves an instance of the estimated workflow whose steps took the given milliseconds
+func saveEstimateRun(t *testing.T, sm StateManager, id string, status WorkflowStatus, durations map[string]int64) {
+	t.Helper()
+	ctx := context.Background()
+	if err := sm.SaveWorkflow(ctx, &WorkflowInstance{ID: id, WorkflowID: "estimated", Status: status, StartedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveWorkflow() error = %v", err)
+	}
+	var steps []*StepInstance
+	for stepID, ms := range durations {
+		steps = append(steps, &StepInstance{ID: id + "-" + stepID, StepID: stepID, WorkflowInstID: id, Status: StepStatusCompleted, DurationMs: ms})
+	}
+	if err := sm.SaveSteps(ctx, steps); err != nil {
+		t.Fatalf("SaveSteps() error = %v", err)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	noop := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	workflow, _ := NewWorkflowBuilder("diamond", "Diamond").
+		AddStepFunc("a", "A", noop).
+		AddStepFunc("b", "B", noop, WithStepDeps("a")).
+		AddStepFunc("c", "C", noop, WithStepDeps("a")).
+		AddStepFunc("d", "D", noop, WithStepDeps("b", "c")).
+		AddStepFunc("e", "E", noop).
+		Build()
+	graph, _ := NewOrchestrator(NewInMemoryStateManager()).buildDependencyGraph(workflow, nil)
+
+	ms := time.Millisecond
+	length, path := criticalPath(workflow, graph, map[string]time.Duration{"a": 10 * ms, "b": 5 * ms, "c": 20 * ms, "d": 1 * ms, "e": 25 * ms})
+	if length != 31*ms || !reflect.DeepEqual(path, []string{"a", "c", "d"}) {
+		t.Errorf("criticalPath() = %v %v, want 31ms [a c d]", length, path)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,8 +15,10 @@ func main() {
 	// Create in-memory state manager
 	stateManager := orchwf.NewInMemoryStateManager()
 
-	// Create orchestrator with custom async workers
-	orchestrator := orchwf.NewOrchestratorWithAsyncWorkers(stateManager, 5)
+	// Create orchestrator with custom async workers and a store for step attachments
+	orchestrator := orchwf.NewOrchestrator(stateManager,
+		orchwf.WithAsyncWorkers(5),
+		orchwf.WithBlobStore(orchwf.NewInMemoryBlobStore()))
 
 	// Define a long-running workflow
 	step1, err := orchwf.NewStepBuilder("data_processing", "Data Processing", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
@@ -29,7 +32,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(2 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -44,7 +47,6 @@ func main() {
 			"invalid_items": 50,
 		}, nil
 	}).WithDescription("Validate processed data").
-		WithDependencies("data_processing").
 		Build()
 
 	if err != nil {
@@ -54,12 +56,17 @@ func main() {
 	step3, err := orchwf.NewStepBuilder("data_export", "Data Export", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
 		fmt.Printf("Step 3: Exporting data for workflow %s...\n", input["workflow_id"])
 		time.Sleep(2 * time.Second)
+
+		// Record the export as an attachment instead of a file path in the output
+		csv := strings.NewReader("id,status\n1,validated\n")
+		attachment, err := orchwf.AddAttachment(ctx, fmt.Sprintf("export_%s.csv", input["workflow_id"]), "text/csv", csv)
+		if err != nil {
+			return nil, err
+		}
 		return map[string]interface{}{
-			"exported_file": fmt.Sprintf("export_%s.csv", input["workflow_id"]),
-			"file_size":     "2.5MB",
+			"export_ref": attachment.Ref,
 		}, nil
 	}).WithDescription("Export validated data").
-		WithDependencies("data_validation").
 		Build()
 
 	if err != nil {
@@ -71,8 +78,8 @@ func main() {
 		WithDescription("Demonstrates asynchronous workflow execution").
 		WithVersion("1.0.0").
 		AddStep(step1).
-		AddStep(step2).
-		AddStep(step3).
+		ThenStep(step2).
+		ThenStep(step3).
 		Build()
 
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -84,7 +85,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(500 * time.Millisecond).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -111,7 +112,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -221,17 +222,12 @@ func main() {
 	// Demonstrate workflow status monitoring
 	fmt.Println("\n=== Workflow Status Monitoring ===")
 
-	// Get all workflow instances using ListWorkflows
-	allWorkflows, total, err := stateManager.ListWorkflows(context.Background(), map[string]interface{}{}, 1000, 0)
+	// Tally statuses with a single aggregate query
+	statusCounts, err := stateManager.CountWorkflowsByStatus(context.Background(), map[string]interface{}{})
 	if err != nil {
 		log.Printf("Failed to get workflow statuses: %v", err)
 	} else {
-		statusCounts := make(map[orchwf.WorkflowStatus]int)
-		for _, wf := range allWorkflows {
-			statusCounts[wf.Status]++
-		}
-
-		fmt.Printf("Workflow status distribution (showing %d of %d total):\n", len(allWorkflows), total)
+		fmt.Println("Workflow status distribution:")
 		for status, count := range statusCounts {
 			fmt.Printf("  %s: %d\n", status, count)
 		}
@@ -257,4 +253,54 @@ func main() {
 		fmt.Printf("  Processed %d items in %v (%.2f items/second)\n",
 			len(testDataSmall), duration, float64(len(testDataSmall))/duration.Seconds())
 	}
+
+	// Demonstrate VIP orders jumping the queue when instances wait for a slot
+	fmt.Println("\n=== VIP Priority ===")
+
+	priorityOrchestrator := orchwf.NewOrchestrator(stateManager, orchwf.WithMaxInFlight(2))
+	vipWorkflow, err := orchwf.NewWorkflowBuilder("vip_data_processing", "VIP Data Processing Workflow").
+		WithDescription("Process VIP items ahead of the standard batch").
+		WithPriority(10).
+		AddStep(step1).
+		AddStep(step2).
+		AddStep(step3).
+		Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, wf := range []*orchwf.WorkflowDefinition{workflow, vipWorkflow} {
+		if err := priorityOrchestrator.RegisterWorkflow(wf); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Queue the standard items first; the VIP items, started last, take the next free slots
+	var ids []string
+	for _, item := range testData[:6] {
+		id, _ := priorityOrchestrator.StartWorkflowAsync(context.Background(), "data_processing", item, nil)
+		ids = append(ids, id)
+	}
+	for i := 1; i <= 2; i++ {
+		item := map[string]interface{}{"item_id": fmt.Sprintf("vip_%d", i), "value": 1000.0}
+		id, _ := priorityOrchestrator.StartWorkflowAsync(context.Background(), "vip_data_processing", item, nil)
+		ids = append(ids, id)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := priorityOrchestrator.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Priority batch still running: %v", err)
+	}
+
+	var finished []*orchwf.WorkflowInstance
+	for _, id := range ids {
+		if instance, err := stateManager.GetWorkflow(context.Background(), id); err == nil && instance.CompletedAt != nil {
+			finished = append(finished, instance)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CompletedAt.Before(*finished[j].CompletedAt) })
+	fmt.Println("Completion order:")
+	for _, instance := range finished {
+		fmt.Printf("  %s (%s)\n", instance.Input["item_id"], instance.WorkflowID)
+	}
 }
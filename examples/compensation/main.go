@@ -44,7 +44,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -80,7 +80,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -122,7 +122,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(1). // Don't retry for age restriction
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -212,7 +212,14 @@ func main() {
 
 		if err != nil {
 			fmt.Printf("Travel booking failed: %v\n", err)
-			fmt.Println("Compensation steps should have been executed automatically.")
+			if result != nil && result.Compensation != nil {
+				for _, step := range result.Compensation.Steps {
+					fmt.Printf("Compensated %s: %s (%v)\n", step.StepID, step.Outcome, step.Duration)
+				}
+				if !result.Compensation.Succeeded() {
+					fmt.Println("Some cancellations failed and need manual follow-up.")
+				}
+			}
 		} else {
 			fmt.Printf("Travel booking completed successfully!\n")
 			fmt.Printf("Duration: %v\n", result.Duration)
@@ -40,9 +40,14 @@ func main() {
 	// Create orchestrator
 	orchestrator := orchwf.NewOrchestrator(stateManager)
 
+	// Verify the state store is reachable before serving traffic
+	if err := orchestrator.HealthCheck(context.Background()); err != nil {
+		log.Fatal("Health check failed:", err)
+	}
+
 	// Define workflow steps
 	step1, err := orchwf.NewStepBuilder("create_order", "Create Order", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-		fmt.Println("Step 1: Creating order in database...")
+		fmt.Println("Step 2: Creating order in database...")
 		time.Sleep(1 * time.Second)
 
 		orderID := fmt.Sprintf("order_%d", time.Now().Unix())
@@ -57,7 +62,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(3).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -65,7 +70,7 @@ func main() {
 	}
 
 	step2, err := orchwf.NewStepBuilder("validate_payment", "Validate Payment", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-		fmt.Println("Step 2: Validating payment...")
+		fmt.Println("Step 1: Validating payment...")
 		time.Sleep(2 * time.Second)
 
 		amount := input["amount"].(float64)
@@ -77,15 +82,14 @@ func main() {
 
 		return map[string]interface{}{
 			"payment_valid": true,
-			"payment_id":    fmt.Sprintf("pay_%s", input["order_id"]),
+			"payment_id":    fmt.Sprintf("pay_%v", input["customer_id"]),
 			"processed_at":  time.Now().Unix(),
 		}, nil
-	}).WithDescription("Validate payment for the order").
-		WithDependencies("create_order").
+	}).WithDescription("Validate payment before the order is created").
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(2 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -102,7 +106,6 @@ func main() {
 			"reserved_at":       time.Now().Unix(),
 		}, nil
 	}).WithDescription("Update inventory for ordered items").
-		WithDependencies("validate_payment").
 		Build()
 
 	if err != nil {
@@ -121,21 +124,21 @@ func main() {
 			"recipient":  input["customer_email"],
 		}, nil
 	}).WithDescription("Send order confirmation email").
-		WithDependencies("update_inventory").
 		Build()
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Build workflow
+	// Build workflow; payment validation gates the run, so a rejected payment
+	// fails the order before anything is written
 	workflow, err := orchwf.NewWorkflowBuilder("order_processing", "Order Processing Workflow").
 		WithDescription("Process customer orders with database persistence").
 		WithVersion("1.0.0").
+		WithGateStep(step2).
 		AddStep(step1).
-		AddStep(step2).
-		AddStep(step3).
-		AddStep(step4).
+		ThenStep(step3).
+		ThenStep(step4).
 		Build()
 
 	if err != nil {
@@ -188,12 +191,13 @@ func main() {
 	// Demonstrate workflow status persistence
 	fmt.Println("\n=== Checking Workflow Statuses ===")
 
-	// Get all workflow instances from database using ListWorkflows
-	workflows, total, err := stateManager.ListWorkflows(context.Background(), map[string]interface{}{}, 100, 0)
+	// Get all workflow instances from database using ListWorkflows, skipping the
+	// COUNT(*) query since only the page is iterated
+	workflows, _, err := stateManager.ListWorkflows(orchwf.WithoutTotal(context.Background()), map[string]interface{}{}, 100, 0)
 	if err != nil {
 		log.Printf("Failed to get workflows: %v", err)
 	} else {
-		fmt.Printf("Found %d total workflows\n", total)
+		fmt.Printf("Found %d workflows\n", len(workflows))
 
 		completedCount := 0
 		failedCount := 0
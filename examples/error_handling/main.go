@@ -38,7 +38,7 @@ func main() {
 			WithMaxInterval(10*time.Second).
 			WithMultiplier(2.0).
 			WithRetryableErrors("network timeout", "connection refused").
-			Build()).
+			MustBuild()).
 		WithTimeout(30 * time.Second).
 		Build()
 
@@ -57,7 +57,7 @@ func main() {
 			WithInitialInterval(500 * time.Millisecond).
 			WithMaxInterval(2 * time.Second).
 			WithMultiplier(1.5).
-			Build()).
+			MustBuild()).
 		WithDependencies("unreliable_api_call").
 		WithRequired(false). // This step is not required, so workflow continues
 		Build()
@@ -87,7 +87,7 @@ func main() {
 			WithMaxInterval(5 * time.Second).
 			WithMultiplier(1.8).
 			WithRetryableErrors("temporary processing error").
-			Build()).
+			MustBuild()).
 		WithDependencies("unreliable_api_call").
 		Build()
 
@@ -161,6 +161,16 @@ func main() {
 
 		if err != nil {
 			fmt.Printf("Workflow failed: %v\n", err)
+			if result != nil {
+				for _, step := range result.WorkflowInst.Steps {
+					switch step.FailureKind {
+					case orchwf.FailureKindTimeout, orchwf.FailureKindMaxDuration:
+						fmt.Printf("  %s timed out and may succeed if retried later\n", step.StepID)
+					case orchwf.FailureKindExhaustedRetries:
+						fmt.Printf("  %s failed permanently after %d retries\n", step.StepID, step.RetryCount)
+					}
+				}
+			}
 		} else {
 			fmt.Printf("Workflow completed successfully!\n")
 			fmt.Printf("Result: %+v\n", result.Output)
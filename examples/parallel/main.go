@@ -29,7 +29,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(3).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -48,7 +48,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(500 * time.Millisecond).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -68,7 +68,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"time"
@@ -27,7 +28,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(3).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -41,7 +42,6 @@ func main() {
 			"validation_result": "valid",
 		}, nil
 	}).WithDescription("Validate processed data").
-		WithDependencies("step1").
 		WithTimeout(5 * time.Second).
 		Build()
 
@@ -56,7 +56,6 @@ func main() {
 			"saved": true,
 		}, nil
 	}).WithDescription("Save validated data").
-		WithDependencies("step2").
 		Build()
 
 	if err != nil {
@@ -68,8 +67,8 @@ func main() {
 		WithDescription("A simple workflow that processes, validates, and saves data").
 		WithVersion("1.0.0").
 		AddStep(step1).
-		AddStep(step2).
-		AddStep(step3).
+		ThenStep(step2).
+		ThenStep(step3).
 		Build()
 
 	if err != nil {
@@ -95,6 +94,10 @@ func main() {
 		log.Fatal(err)
 	}
 
-	fmt.Printf("Workflow completed successfully: %+v\n", result)
+	instanceJSON, err := json.MarshalIndent(result.WorkflowInst.ToDTO(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Workflow completed successfully:\n%s\n", instanceJSON)
 	fmt.Printf("Duration: %v\n", result.Duration)
 }
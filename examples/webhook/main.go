@@ -42,6 +42,11 @@ func (w *WebhookClient) SendWebhook(ctx context.Context, endpoint string, payloa
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "orchwf-webhook-client/1.0")
 
+	// Reuse the same key on every retry so the receiver can deduplicate
+	if token, ok := orchwf.IdempotencyTokenFromContext(ctx); ok {
+		req.Header.Set("Idempotency-Key", token)
+	}
+
 	resp, err := w.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("webhook request failed: %v", err)
@@ -97,7 +102,7 @@ func main() {
 			WithMaxInterval(10*time.Second).
 			WithMultiplier(2.0).
 			WithRetryableErrors("webhook request failed", "timeout").
-			Build()).
+			MustBuild()).
 		WithTimeout(30 * time.Second).
 		Build()
 
@@ -136,7 +141,7 @@ func main() {
 			WithInitialInterval(1 * time.Second).
 			WithMaxInterval(5 * time.Second).
 			WithMultiplier(1.5).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -175,7 +180,7 @@ func main() {
 			WithInitialInterval(1 * time.Second).
 			WithMaxInterval(5 * time.Second).
 			WithMultiplier(1.5).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -212,7 +217,7 @@ func main() {
 		WithRetryPolicy(orchwf.NewRetryPolicyBuilder().
 			WithMaxAttempts(2).
 			WithInitialInterval(1 * time.Second).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
@@ -227,6 +232,16 @@ func main() {
 		AddStep(step2).
 		AddStep(step3).
 		AddStep(step4).
+		WithOutputMapping(func(stepOutputs map[string]map[string]interface{}) map[string]interface{} {
+			// Report only the webhooks that were sent, by step
+			webhooks := make(map[string]interface{})
+			for stepID, output := range stepOutputs {
+				if sent, _ := output["webhook_sent"].(bool); sent {
+					webhooks[stepID] = output["webhook_type"]
+				}
+			}
+			return map[string]interface{}{"webhooks": webhooks}
+		}).
 		Build()
 
 	if err != nil {
@@ -279,17 +294,12 @@ func main() {
 			fmt.Printf("Webhook workflow completed successfully!\n")
 			fmt.Printf("Duration: %v\n", result.Duration)
 
-			// Count successful webhooks
-			webhookCount := 0
-			for stepName, stepOutput := range result.Output {
-				if stepData, ok := stepOutput.(map[string]interface{}); ok {
-					if sent, exists := stepData["webhook_sent"]; exists && sent.(bool) {
-						webhookCount++
-						fmt.Printf("  ✓ %s: %s\n", stepName, stepData["webhook_type"])
-					}
-				}
+			// The output mapping lists the webhooks that were sent
+			webhooks, _ := result.Output["webhooks"].(map[string]interface{})
+			for stepName, webhookType := range webhooks {
+				fmt.Printf("  ✓ %s: %s\n", stepName, webhookType)
 			}
-			fmt.Printf("Total webhooks sent: %d\n", webhookCount)
+			fmt.Printf("Total webhooks sent: %d\n", len(webhooks))
 		}
 
 		// Add delay between orders to avoid rate limiting
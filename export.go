@@ -0,0 +1,185 @@
+package orchwf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ExportDefinitions returns the registered workflow definitions in their portable form,
+// sorted by workflow ID, for documentation or for moving them to another environment.
+// Executors are exported as their executor key, or the step ID when the step has none.
+// Definitions that use features a spec cannot carry, such as compensators or finalizers,
+// fail the export rather than losing those features on import.
+func (o *Orchestrator) ExportDefinitions() ([]WorkflowDefinitionSpec, error) {
+	o.mu.RLock()
+	workflows := make([]*WorkflowDefinition, 0, len(o.workflows))
+	for _, workflow := range o.workflows {
+		workflows = append(workflows, workflow)
+	}
+	o.mu.RUnlock()
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].ID < workflows[j].ID
+	})
+
+	specs := make([]WorkflowDefinitionSpec, 0, len(workflows))
+	for _, workflow := range workflows {
+		spec, err := workflowSpec(workflow)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// ImportDefinitions builds workflow definitions from specs, binding each step to the
+// executor registered under its executor key, and registers them. Every spec is built
+// and checked against the registered workflows and limits before any is registered, so
+// a missing executor, a duplicate ID or a full registry registers nothing.
+func (o *Orchestrator) ImportDefinitions(specs []WorkflowDefinitionSpec, registry *ExecutorRegistry) error {
+	workflows := make([]*WorkflowDefinition, 0, len(specs))
+	for _, spec := range specs {
+		workflow, err := spec.build(registry)
+		if err != nil {
+			return fmt.Errorf("failed to import workflow %s: %w", spec.ID, err)
+		}
+		if err := o.validateWorkflow(workflow); err != nil {
+			return err
+		}
+		workflows = append(workflows, workflow)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	seen := make(map[string]bool, len(workflows))
+	for i, workflow := range workflows {
+		if seen[workflow.ID] {
+			return fmt.Errorf("%w: %s", ErrWorkflowAlreadyExists, workflow.ID)
+		}
+		seen[workflow.ID] = true
+		if err := o.checkRegistrableLocked(workflow, i); err != nil {
+			return err
+		}
+	}
+	for _, workflow := range workflows {
+		o.addWorkflowLocked(workflow)
+	}
+	return nil
+}
+
+// workflowSpec converts a workflow definition into its portable form
+func workflowSpec(workflow *WorkflowDefinition) (WorkflowDefinitionSpec, error) {
+	if feature := unexportableWorkflowFeature(workflow); feature != "" {
+		return WorkflowDefinitionSpec{}, fmt.Errorf("workflow %s cannot be exported: it uses %s", workflow.ID, feature)
+	}
+
+	spec := WorkflowDefinitionSpec{
+		ID:            workflow.ID,
+		Name:          workflow.Name,
+		Description:   workflow.Description,
+		Version:       workflow.Version,
+		FailurePolicy: workflow.FailurePolicy,
+		Priority:      workflow.Priority,
+		DeepMerge:     workflow.DeepMerge,
+		PersistPolicy: workflow.PersistPolicy,
+		Metadata:      copyMap(workflow.Metadata),
+	}
+	for _, step := range workflow.Steps {
+		if feature := unexportableStepFeature(step); feature != "" {
+			return WorkflowDefinitionSpec{}, fmt.Errorf("workflow %s cannot be exported: step %s uses %s", workflow.ID, step.ID, feature)
+		}
+		spec.Steps = append(spec.Steps, stepSpec(step))
+	}
+	return spec, nil
+}
+
+// stepSpec converts a step definition into its portable form
+func stepSpec(step *StepDefinition) StepSpec {
+	required := step.Required
+	spec := StepSpec{
+		ID:                 step.ID,
+		Name:               step.Name,
+		Description:        step.Description,
+		ExecutorKey:        step.ExecutorKey,
+		Dependencies:       append([]string(nil), step.Dependencies...),
+		Required:           &required,
+		Async:              step.Async,
+		Priority:           step.Priority,
+		DeadlineKey:        step.DeadlineKey,
+		Tags:               append([]string(nil), step.Tags...),
+		RequiredOutputKeys: append([]string(nil), step.RequiredOutputKeys...),
+	}
+	if spec.ExecutorKey == "" {
+		spec.ExecutorKey = step.ID
+	}
+	if step.Timeout > 0 {
+		spec.Timeout = step.Timeout.String()
+	}
+	if step.MaxDuration > 0 {
+		spec.MaxDuration = step.MaxDuration.String()
+	}
+	if policy := step.RetryPolicy; policy != nil {
+		spec.RetryPolicy = &RetryPolicySpec{
+			MaxAttempts:     policy.MaxAttempts,
+			InitialInterval: policy.InitialInterval.String(),
+			MaxInterval:     policy.MaxInterval.String(),
+			Multiplier:      policy.Multiplier,
+			RetryableErrors: append([]string(nil), policy.RetryableErrors...),
+		}
+	}
+	return spec
+}
+
+// unexportableWorkflowFeature names a workflow-level setting a spec cannot carry, if any
+func unexportableWorkflowFeature(workflow *WorkflowDefinition) string {
+	switch {
+	case workflow.Finalizer != nil:
+		return "a finalizer"
+	case workflow.OutputMapping != nil:
+		return "an output mapping"
+	case workflow.GateStep != "":
+		return "a gate step"
+	case len(workflow.SuccessSteps) > 0:
+		return "success steps"
+	case len(workflow.InputDefaults) > 0:
+		return "input defaults"
+	case workflow.WaveStrategy != WaveStrategySequential:
+		return "a wave strategy"
+	case workflow.MaxConcurrentSteps > 0:
+		return "max concurrent steps"
+	case len(workflow.RedactedKeys) > 0:
+		return "redacted keys"
+	case workflow.StrictOrdering:
+		return "strict ordering"
+	case workflow.FailOnOptionalFailure:
+		return "failing on optional failures"
+	}
+	return ""
+}
+
+// unexportableStepFeature names a step setting a spec cannot carry, if any
+func unexportableStepFeature(step *StepDefinition) string {
+	switch {
+	case step.Compensator != nil:
+		return "a compensator"
+	case step.TimerUntil != nil:
+		return "a timer"
+	case step.Resource != "":
+		return "a resource pool"
+	case len(step.Alternatives) > 0:
+		return "alternatives"
+	case step.FanIn:
+		return "fan-in"
+	case step.RetryableOutput != nil:
+		return "a retryable output predicate"
+	case step.DynamicDependencies != nil:
+		return "dynamic dependencies"
+	case step.InputInterceptor != nil:
+		return "an input interceptor"
+	case step.AdvancedExecutor != nil:
+		return "an advanced executor"
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package orchwf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_ExportImportDefinitions(t *testing.T) {
+	send := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"sent": true}, nil
+	}
+	audit := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"audited": input["sent"]}, nil
+	}
+
+	source := NewOrchestrator(NewInMemoryStateManager())
+	notify, _ := NewWorkflowBuilder("notify", "Notify").
+		WithVersion("2.0.0").
+		WithFailurePolicy(FailurePolicyCompleteWave).
+		WithMetadata("team", "growth").
+		AddStepFunc("send", "Send", send,
+			WithStepExecutorKey("email.send.v1"),
+			WithStepTimeout(30*time.Second),
+			WithStepMaxDuration(time.Minute),
+			WithStepTags("external-call"),
+			WithStepPriority(5),
+			WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 4, InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2})).
+		AddStepFunc("audit", "Audit", audit, WithStepDeps("send"), WithStepRequired(false), WithStepRequiredOutputKeys("audited")).
+		Build()
+	archive, _ := NewWorkflowBuilder("archive", "Archive").
+		AddStepFunc("store", "Store", send).
+		Build()
+	source.RegisterWorkflow(notify)
+	source.RegisterWorkflow(archive)
+
+	specs, err := source.ExportDefinitions()
+	if err != nil {
+		t.Fatalf("ExportDefinitions() error = %v", err)
+	}
+	if len(specs) != 2 || specs[0].ID != "archive" || specs[1].ID != "notify" {
+		t.Fatalf("ExportDefinitions() = %+v, want archive and notify sorted by ID", specs)
+	}
+
+	sendSpec := specs[1].Steps[0]
+	if sendSpec.ExecutorKey != "email.send.v1" || sendSpec.Timeout != "30s" || sendSpec.MaxDuration != "1m0s" || sendSpec.Priority != 5 || len(sendSpec.Tags) != 1 {
+		t.Errorf("send spec = %+v, want its executor key, timeouts, priority and tags", sendSpec)
+	}
+	if sendSpec.RetryPolicy == nil || sendSpec.RetryPolicy.MaxAttempts != 4 || sendSpec.RetryPolicy.MaxInterval != "10s" {
+		t.Errorf("send retry policy = %+v, want the step's policy", sendSpec.RetryPolicy)
+	}
+	auditSpec := specs[1].Steps[1]
+	if auditSpec.ExecutorKey != "audit" || auditSpec.Required == nil || *auditSpec.Required || len(auditSpec.RequiredOutputKeys) != 1 {
+		t.Errorf("audit spec = %+v, want the step ID as executor key, required false and its output keys", auditSpec)
+	}
+
+	// The specs survive a trip through JSON, for example to another environment
+	data, err := json.Marshal(specs)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded []WorkflowDefinitionSpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	registry := NewExecutorRegistry()
+	registry.Register("email.send.v1", send)
+	registry.Register("audit", audit)
+	registry.Register("store", send)
+
+	target := NewOrchestrator(NewInMemoryStateManager())
+	if err := target.ImportDefinitions(decoded, registry); err != nil {
+		t.Fatalf("ImportDefinitions() error = %v", err)
+	}
+
+	reexported, err := target.ExportDefinitions()
+	if err != nil {
+		t.Fatalf("ExportDefinitions() after import error = %v", err)
+	}
+	if !reflect.DeepEqual(reexported, specs) {
+		t.Errorf("re-exported specs = %+v, want %+v", reexported, specs)
+	}
+
+	result, err := target.StartWorkflow(context.Background(), "notify", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() on imported workflow error = %v", err)
+	}
+	if result.Output["audited"] != true {
+		t.Errorf("imported workflow output = %v, want the bound executors to run", result.Output)
+	}
+
+	// An exported spec is also valid input for LoadWorkflowFromJSON
+	single, _ := json.Marshal(specs[0])
+	if _, err := LoadWorkflowFromJSON(bytes.NewReader(single), registry); err != nil {
+		t.Errorf("LoadWorkflowFromJSON() on an exported spec error = %v", err)
+	}
+}
+
+func TestOrchestrator_ExportRejectsUnexportableFeatures(t *testing.T) {
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	workflow, _ := NewWorkflowBuilder("refund", "Refund").
+		AddStepFunc("charge", "Charge", executor, WithStepCompensator(func(ctx context.Context, input map[string]interface{}) error {
+			return nil
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	_, err := orchestrator.ExportDefinitions()
+	if err == nil || !strings.Contains(err.Error(), "step charge uses a compensator") {
+		t.Errorf("ExportDefinitions() error = %v, want the compensator named", err)
+	}
+}
+
+func TestOrchestrator_ImportMissingExecutorRegistersNothing(t *testing.T) {
+	registry := NewExecutorRegistry()
+	registry.Register("store", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+
+	specs := []WorkflowDefinitionSpec{
+		{ID: "archive", Name: "Archive", Steps: []StepSpec{{ID: "store", Name: "Store", ExecutorKey: "store"}}},
+		{ID: "notify", Name: "Notify", Steps: []StepSpec{{ID: "send", Name: "Send", ExecutorKey: "email.send.v1"}}},
+	}
+
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	if err := orchestrator.ImportDefinitions(specs, registry); !errors.Is(err, ErrExecutorNotFound) {
+		t.Fatalf("ImportDefinitions() error = %v, want ErrExecutorNotFound", err)
+	}
+	if count := orchestrator.WorkflowCount(); count != 0 {
+		t.Errorf("WorkflowCount() = %d, want 0 after a failed import", count)
+	}
+}
+
+func TestOrchestrator_ImportRegistrationFailureRegistersNothing(t *testing.T) {
+	registry := NewExecutorRegistry()
+	registry.Register("store", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	spec := func(id string) WorkflowDefinitionSpec {
+		return WorkflowDefinitionSpec{ID: id, Name: id, Steps: []StepSpec{{ID: "store", Name: "Store", ExecutorKey: "store"}}}
+	}
+
+	t.Run("duplicate of a registered workflow", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		if err := orchestrator.ImportDefinitions([]WorkflowDefinitionSpec{spec("existing")}, registry); err != nil {
+			t.Fatalf("ImportDefinitions() error = %v", err)
+		}
+		err := orchestrator.ImportDefinitions([]WorkflowDefinitionSpec{spec("archive"), spec("existing")}, registry)
+		if !errors.Is(err, ErrWorkflowAlreadyExists) {
+			t.Fatalf("ImportDefinitions() error = %v, want ErrWorkflowAlreadyExists", err)
+		}
+		if _, err := orchestrator.GetWorkflow("archive"); !errors.Is(err, ErrWorkflowNotFound) {
+			t.Errorf("GetWorkflow(archive) error = %v, want it left unregistered", err)
+		}
+	})
+
+	t.Run("duplicate within the specs", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		err := orchestrator.ImportDefinitions([]WorkflowDefinitionSpec{spec("archive"), spec("archive")}, registry)
+		if !errors.Is(err, ErrWorkflowAlreadyExists) {
+			t.Fatalf("ImportDefinitions() error = %v, want ErrWorkflowAlreadyExists", err)
+		}
+		if count := orchestrator.WorkflowCount(); count != 0 {
+			t.Errorf("WorkflowCount() = %d, want 0 after a failed import", count)
+		}
+	})
+
+	t.Run("registration limit", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithLimits(0, 2))
+		err := orchestrator.ImportDefinitions([]WorkflowDefinitionSpec{spec("archive"), spec("notify"), spec("report")}, registry)
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("ImportDefinitions() error = %v, want ErrLimitExceeded", err)
+		}
+		if count := orchestrator.WorkflowCount(); count != 0 {
+			t.Errorf("WorkflowCount() = %d, want 0 after a failed import", count)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package orchwf
+
+// applyFanIn stores the outputs of the step's completed dependencies deps, in
+// dependency order, under the step's own ID and merges in the reducer's result,
+// which it returns
+func applyFanIn(stepDef *StepDefinition, deps []string, input map[string]interface{}, stepInstMap map[string]*StepInstance) map[string]interface{} {
+	outputs := make([]map[string]interface{}, 0, len(deps))
+	for _, depID := range deps {
+		depInst, ok := stepInstMap[depID]
+		if !ok || depInst.Status != StepStatusCompleted {
+			// Skipped or failed optional dependencies contribute nothing
+			continue
+		}
+		output := depInst.Output
+		if output == nil {
+			output = map[string]interface{}{}
+		}
+		outputs = append(outputs, output)
+	}
+	input[stepDef.ID] = outputs
+
+	if stepDef.Reducer == nil {
+		return nil
+	}
+	reduced := stepDef.Reducer(outputs)
+	for k, v := range reduced {
+		input[k] = v
+	}
+	return reduced
+}
+
+// FanInOutputs returns the dependency outputs collected for a fan-in step.
+// Call it from the step's executor with the step's own ID.
+func FanInOutputs(input map[string]interface{}, stepID string) []map[string]interface{} {
+	outputs, _ := input[stepID].([]map[string]interface{})
+	return outputs
+}
@@ -0,0 +1,117 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrchestrator_FanInReducer(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	count := func(n int) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"count": n}, nil
+		}
+	}
+	sum := func(outputs []map[string]interface{}) map[string]interface{} {
+		total := 0
+		for _, output := range outputs {
+			total += output["count"].(int)
+		}
+		return map[string]interface{}{"total": total}
+	}
+
+	var collected []map[string]interface{}
+	var total interface{}
+	workflow, err := NewWorkflowBuilder("fan-in", "Fan In").
+		AddStepFunc("a", "A", count(1), WithStepAsync(true)).
+		AddStepFunc("b", "B", count(2), WithStepAsync(true)).
+		AddStepFunc("c", "C", count(3), WithStepAsync(true)).
+		AddStepFunc("broken", "Broken", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("boom")
+		}, WithStepRequired(false)).
+		AddStepFunc("reduce", "Reduce", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			collected = FanInOutputs(input, "reduce")
+			total = input["total"]
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("a", "b", "c", "broken"), WithStepReducer(sum)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "fan-in", nil, nil); err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// The failed optional dependency is left out and order follows the dependency list
+	if len(collected) != 3 {
+		t.Fatalf("FanInOutputs() count = %d, want %d", len(collected), 3)
+	}
+	for i, want := range []int{1, 2, 3} {
+		if collected[i]["count"] != want {
+			t.Errorf("FanInOutputs()[%d] count = %v, want %v", i, collected[i]["count"], want)
+		}
+	}
+	if total != 6 {
+		t.Errorf("reduced total = %v, want %v", total, 6)
+	}
+}
+
+func TestOrchestrator_FanInDynamicDependencies(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithInputProvenance(true))
+
+	count := func(n int) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"count": n}, nil
+		}
+	}
+	sum := func(outputs []map[string]interface{}) map[string]interface{} {
+		total := 0
+		for _, output := range outputs {
+			total += output["count"].(int)
+		}
+		return map[string]interface{}{"total": total}
+	}
+
+	var collected []map[string]interface{}
+	var total interface{}
+	workflow, err := NewWorkflowBuilder("dynamic-fan-in", "Dynamic Fan In").
+		AddStepFunc("a", "A", count(1)).
+		AddStepFunc("b", "B", count(2)).
+		AddStepFunc("reduce", "Reduce", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			collected = FanInOutputs(input, "reduce")
+			total = input["total"]
+			return map[string]interface{}{}, nil
+		}, WithStepDynamicDependencies(func(input map[string]interface{}) []string {
+			return []string{"a", "b"}
+		}), WithStepReducer(sum)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "dynamic-fan-in", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// The resolved dependencies feed the reducer, just like static ones
+	if len(collected) != 2 || collected[0]["count"] != 1 || collected[1]["count"] != 2 {
+		t.Errorf("FanInOutputs() = %v, want the outputs of a and b", collected)
+	}
+	if total != 3 {
+		t.Errorf("reduced total = %v, want %v", total, 3)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	for _, step := range steps {
+		if step.StepID == "reduce" && step.Provenance["a"] != dependencyProvenance("a") {
+			t.Errorf("provenance of a = %q, want %q", step.Provenance["a"], dependencyProvenance("a"))
+		}
+	}
+}
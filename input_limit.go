@@ -0,0 +1,31 @@
+package orchwf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithMaxStepInputBytes caps the JSON-serialized size of the input prepared for a step.
+// A step whose merged input is larger fails with ErrInputTooLarge before its executor
+// runs. A zero value leaves the size unlimited.
+func WithMaxStepInputBytes(n int) Option {
+	return func(o *Orchestrator) {
+		o.maxInputBytes = n
+	}
+}
+
+// checkInputSize enforces WithMaxStepInputBytes on a prepared step input
+func (o *Orchestrator) checkInputSize(stepDef *StepDefinition, input map[string]interface{}) error {
+	if o.maxInputBytes <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to measure input of step %s: %w", stepDef.ID, err)
+	}
+	if len(data) > o.maxInputBytes {
+		return fmt.Errorf("%w: step %s input is %d bytes, maximum is %d", ErrInputTooLarge, stepDef.ID, len(data), o.maxInputBytes)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOrchestrator_MaxStepInputBytes(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithMaxStepInputBytes(1024))
+	var merged bool
+	workflow, _ := NewWorkflowBuilder("fan-in", "Fan In").
+		AddStepFunc("small", "Small", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		AddStepFunc("load", "Load", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"rows": strings.Repeat("x", 2000)}, nil
+		}).
+		AddStepFunc("merge", "Merge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			merged = true
+			return nil, nil
+		}, WithStepDeps("load")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "fan-in", nil, nil)
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Fatalf("StartWorkflow() error = %v, want ErrInputTooLarge", err)
+	}
+	if merged {
+		t.Errorf("merge executor ran with an oversized input")
+	}
+
+	for _, step := range result.WorkflowInst.Steps {
+		switch step.StepID {
+		case "merge":
+			if step.Status != StepStatusFailed || step.FailureKind != FailureKindValidation {
+				t.Errorf("merge = %s (%s), want failed with a validation failure", step.Status, step.FailureKind)
+			}
+		case "small":
+			if step.Status != StepStatusCompleted {
+				t.Errorf("small = %s, want completed under the limit", step.Status)
+			}
+		}
+	}
+}
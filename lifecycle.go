@@ -0,0 +1,79 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// goBackground runs fn in a goroutine that Shutdown waits for.
+// It returns ErrShutdown once Shutdown has been called.
+func (o *Orchestrator) goBackground(fn func()) error {
+	o.lifecycleMu.Lock()
+	defer o.lifecycleMu.Unlock()
+
+	if o.closed {
+		return ErrShutdown
+	}
+	o.background.Add(1)
+	go func() {
+		defer o.background.Done()
+		fn()
+	}()
+	return nil
+}
+
+// isShutdown reports whether Shutdown has been called
+func (o *Orchestrator) isShutdown() bool {
+	o.lifecycleMu.Lock()
+	defer o.lifecycleMu.Unlock()
+	return o.closed
+}
+
+// Shutdown stops the timer tickers, rejects new async workflows with ErrShutdown,
+// and waits for the running async workflows, including those a ticker resumed, to
+// finish. Events are saved as they are emitted, so once Shutdown returns nil every
+// event has been persisted. If ctx is done first, Shutdown returns its error, naming
+// the workflow instances still running, and the workflows keep running.
+func (o *Orchestrator) Shutdown(ctx context.Context) error {
+	o.lifecycleMu.Lock()
+	if !o.closed {
+		o.closed = true
+		close(o.shutdown)
+	}
+	o.lifecycleMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		o.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if ids := o.runningInstances(); len(ids) > 0 {
+			return fmt.Errorf("%w: workflows still running: %s", ctx.Err(), strings.Join(ids, ", "))
+		}
+		return ctx.Err()
+	}
+}
+
+// trackRunning records an instance run by background work until the returned func is called
+func (o *Orchestrator) trackRunning(instanceID string) func() {
+	o.running.Store(instanceID, struct{}{})
+	return func() { o.running.Delete(instanceID) }
+}
+
+// runningInstances returns the sorted IDs of instances run by background work
+func (o *Orchestrator) runningInstances() []string {
+	var ids []string
+	o.running.Range(func(key, _ interface{}) bool {
+		ids = append(ids, key.(string))
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
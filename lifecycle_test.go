@@ -0,0 +1,138 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_ShutdownWaitsForAsyncWorkflows(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return map[string]interface{}{}, nil
+	}
+	workflow, _ := NewWorkflowBuilder("burst", "Burst").
+		AddStepFunc("a", "A", executor).
+		AddStepFunc("b", "B", executor, WithStepDeps("a")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	var ids []string
+	for i := 0; i < 20; i++ {
+		id, err := orchestrator.StartWorkflowAsync(context.Background(), "burst", nil, nil)
+		if err != nil {
+			t.Fatalf("StartWorkflowAsync() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := orchestrator.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// Every workflow finished and its final event was persisted before Shutdown returned
+	for _, id := range ids {
+		events, err := sm.GetWorkflowEvents(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetWorkflowEvents() error = %v", err)
+		}
+		completed := 0
+		for _, event := range events {
+			if event.EventType == EventWorkflowCompleted || event.EventType == EventStepCompleted {
+				completed++
+			}
+		}
+		if completed != 3 {
+			t.Errorf("workflow %s has %d completion events, want %d", id, completed, 3)
+		}
+	}
+
+	if _, err := orchestrator.StartWorkflowAsync(context.Background(), "burst", nil, nil); !errors.Is(err, ErrShutdown) {
+		t.Errorf("StartWorkflowAsync() after Shutdown error = %v, want %v", err, ErrShutdown)
+	}
+
+	// The refused start saved nothing that a later resume could pick up
+	_, total, err := sm.ListWorkflows(context.Background(), nil, 100, 0)
+	if err != nil {
+		t.Fatalf("ListWorkflows() error = %v", err)
+	}
+	if total != int64(len(ids)) {
+		t.Errorf("saved instances = %d, want %d: a start after Shutdown must not save one", total, len(ids))
+	}
+}
+
+func TestOrchestrator_ShutdownHonoursContext(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	release := make(chan struct{})
+	defer close(release)
+	workflow, _ := NewWorkflowBuilder("blocked", "Blocked").
+		AddStepFunc("wait", "Wait", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-release
+			return map[string]interface{}{}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	orchestrator.StartTimerTicker(context.Background(), time.Millisecond)
+
+	id, err := orchestrator.StartWorkflowAsync(context.Background(), "blocked", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = orchestrator.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err == nil || !strings.Contains(err.Error(), id) {
+		t.Errorf("Shutdown() error = %v, want it to name the running instance %s", err, id)
+	}
+}
+
+func TestOrchestrator_ShutdownWaitsForTimerResumedRun(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	wakeAt := time.Now().Add(10 * time.Millisecond)
+	started := make(chan struct{})
+	wait, _ := NewStepBuilder("wait", "Wait", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	}).WithTimerUntil(func(input map[string]interface{}) time.Time { return wakeAt }).Build()
+	workflow, _ := NewWorkflowBuilder("scheduled", "Scheduled").
+		AddStep(wait).
+		AddStepFunc("report", "Report", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("wait")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "scheduled", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	orchestrator.StartTimerTicker(context.Background(), time.Millisecond)
+
+	// Drain while the ticker-fired run is mid-step
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("timer step was never resumed")
+	}
+	if err := orchestrator.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	instance, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if instance.Status != WorkflowStatusCompleted {
+		t.Errorf("workflow status after Shutdown = %v, want completed", instance.Status)
+	}
+}
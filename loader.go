@@ -0,0 +1,220 @@
+package orchwf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ExecutorRegistry maps executor keys to step executors for workflows loaded from JSON.
+// Binding by an explicit key keeps the persisted topology independent of Go function
+// identity and lets one executor back several steps.
+type ExecutorRegistry struct {
+	executors map[string]StepExecutor
+	mu        sync.RWMutex
+}
+
+// NewExecutorRegistry creates an empty executor registry
+func NewExecutorRegistry() *ExecutorRegistry {
+	return &ExecutorRegistry{
+		executors: make(map[string]StepExecutor),
+	}
+}
+
+// Register binds an executor to a key
+func (r *ExecutorRegistry) Register(key string, exec StepExecutor) error {
+	if key == "" {
+		return fmt.Errorf("executor key is required")
+	}
+	if exec == nil {
+		return fmt.Errorf("executor for key %s is nil", key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.executors[key]; exists {
+		return fmt.Errorf("executor key %s is already registered", key)
+	}
+	r.executors[key] = exec
+	return nil
+}
+
+// Lookup returns the executor bound to a key
+func (r *ExecutorRegistry) Lookup(key string) (StepExecutor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	exec, ok := r.executors[key]
+	return exec, ok
+}
+
+// WorkflowDefinitionSpec is the portable form of a workflow definition, read by
+// LoadWorkflowFromJSON and produced by ExportDefinitions. Steps name their executors by
+// registry key instead of holding them.
+type WorkflowDefinitionSpec struct {
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Version       string                 `json:"version"`
+	FailurePolicy FailurePolicy          `json:"failure_policy"`
+	Priority      int                    `json:"priority"`
+	DeepMerge     bool                   `json:"deep_merge"`
+	PersistPolicy PersistPolicy          `json:"persist_policy"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	Steps         []StepSpec             `json:"steps"`
+}
+
+// StepSpec is the portable form of a step definition
+type StepSpec struct {
+	ID                 string           `json:"id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description"`
+	ExecutorKey        string           `json:"executor_key"`
+	Dependencies       []string         `json:"dependencies"`
+	Timeout            string           `json:"timeout"` // Go duration string, e.g. "30s"
+	MaxDuration        string           `json:"max_duration"`
+	Required           *bool            `json:"required"`
+	Async              bool             `json:"async"`
+	Priority           int              `json:"priority"`
+	DeadlineKey        string           `json:"deadline_key"`
+	RetryPolicy        *RetryPolicySpec `json:"retry_policy"`
+	Tags               []string         `json:"tags"`
+	RequiredOutputKeys []string         `json:"required_output_keys"`
+}
+
+// RetryPolicySpec is the portable form of a retry policy
+type RetryPolicySpec struct {
+	MaxAttempts     int      `json:"max_attempts"`
+	InitialInterval string   `json:"initial_interval"`
+	MaxInterval     string   `json:"max_interval"`
+	Multiplier      float64  `json:"multiplier"`
+	RetryableErrors []string `json:"retryable_errors"`
+}
+
+// LoadWorkflowFromJSON reads a workflow definition from JSON, binding each step to the
+// executor registered under its executor_key
+func LoadWorkflowFromJSON(r io.Reader, registry *ExecutorRegistry) (*WorkflowDefinition, error) {
+	var spec WorkflowDefinitionSpec
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow JSON: %w", err)
+	}
+
+	return spec.build(registry)
+}
+
+// build converts a spec into a workflow definition, binding executors from the registry
+func (spec WorkflowDefinitionSpec) build(registry *ExecutorRegistry) (*WorkflowDefinition, error) {
+	builder := NewWorkflowBuilder(spec.ID, spec.Name).
+		WithDescription(spec.Description).
+		WithFailurePolicy(spec.FailurePolicy).
+		WithPriority(spec.Priority).
+		WithDeepMerge(spec.DeepMerge).
+		WithPersistPolicy(spec.PersistPolicy)
+	if spec.Version != "" {
+		builder.WithVersion(spec.Version)
+	}
+	for key, value := range spec.Metadata {
+		builder.WithMetadata(key, value)
+	}
+
+	for _, s := range spec.Steps {
+		step, err := s.build(registry)
+		if err != nil {
+			return nil, err
+		}
+		builder.AddStep(step)
+	}
+
+	return builder.Build()
+}
+
+// build converts a step spec into a step definition
+func (s StepSpec) build(registry *ExecutorRegistry) (*StepDefinition, error) {
+	if s.ExecutorKey == "" {
+		return nil, fmt.Errorf("step %s has no executor_key", s.ID)
+	}
+
+	exec, ok := registry.Lookup(s.ExecutorKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: step %s references executor_key %s", ErrExecutorNotFound, s.ID, s.ExecutorKey)
+	}
+
+	builder := NewStepBuilder(s.ID, s.Name, exec).
+		WithExecutorKey(s.ExecutorKey).
+		WithDescription(s.Description).
+		WithAsync(s.Async).
+		WithPriority(s.Priority)
+
+	if len(s.Dependencies) > 0 {
+		builder.WithDependencies(s.Dependencies...)
+	}
+	if s.Required != nil {
+		builder.WithRequired(*s.Required)
+	}
+	if s.DeadlineKey != "" {
+		builder.WithDeadlineFromInput(s.DeadlineKey)
+	}
+	if s.Timeout != "" {
+		timeout, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("step %s has invalid timeout: %w", s.ID, err)
+		}
+		builder.WithTimeout(timeout)
+	}
+	if len(s.Tags) > 0 {
+		builder.WithTags(s.Tags...)
+	}
+	if len(s.RequiredOutputKeys) > 0 {
+		builder.WithRequiredOutputKeys(s.RequiredOutputKeys...)
+	}
+	if s.MaxDuration != "" {
+		maxDuration, err := time.ParseDuration(s.MaxDuration)
+		if err != nil {
+			return nil, fmt.Errorf("step %s has invalid max_duration: %w", s.ID, err)
+		}
+		builder.WithMaxDuration(maxDuration)
+	}
+	if s.RetryPolicy != nil {
+		policy, err := s.RetryPolicy.toRetryPolicy()
+		if err != nil {
+			return nil, fmt.Errorf("step %s has invalid retry_policy: %w", s.ID, err)
+		}
+		builder.WithRetryPolicy(policy)
+	}
+
+	return builder.Build()
+}
+
+// toRetryPolicy converts a retry policy spec, filling unset fields from the builder defaults
+func (p *RetryPolicySpec) toRetryPolicy() (*RetryPolicy, error) {
+	builder := NewRetryPolicyBuilder()
+	if p.MaxAttempts > 0 {
+		builder.WithMaxAttempts(p.MaxAttempts)
+	}
+	if p.InitialInterval != "" {
+		interval, err := time.ParseDuration(p.InitialInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid initial_interval: %w", err)
+		}
+		builder.WithInitialInterval(interval)
+	}
+	if p.MaxInterval != "" {
+		interval, err := time.ParseDuration(p.MaxInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_interval: %w", err)
+		}
+		builder.WithMaxInterval(interval)
+	}
+	if p.Multiplier > 0 {
+		builder.WithMultiplier(p.Multiplier)
+	}
+	if len(p.RetryableErrors) > 0 {
+		builder.WithRetryableErrors(p.RetryableErrors...)
+	}
+	return builder.Build()
+}
@@ -0,0 +1,118 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecutorRegistry_Register(t *testing.T) {
+	registry := NewExecutorRegistry()
+	exec := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	if err := registry.Register("send_email.v1", exec); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register("send_email.v1", exec); err == nil {
+		t.Errorf("Register() should reject duplicate keys")
+	}
+	if err := registry.Register("", exec); err == nil {
+		t.Errorf("Register() should reject empty keys")
+	}
+	if err := registry.Register("nil", nil); err == nil {
+		t.Errorf("Register() should reject nil executors")
+	}
+	if _, ok := registry.Lookup("send_email.v1"); !ok {
+		t.Errorf("Lookup() did not find registered executor")
+	}
+}
+
+func TestLoadWorkflowFromJSON(t *testing.T) {
+	registry := NewExecutorRegistry()
+	registry.Register("notify.v2", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"notified": true}, nil
+	})
+
+	data := `{
+		"id": "order",
+		"name": "Order",
+		"version": "2.0.0",
+		"failure_policy": "complete_wave",
+		"steps": [
+			{"id": "notify_customer", "name": "Notify Customer", "executor_key": "notify.v2", "timeout": "5s"},
+			{
+				"id": "notify_warehouse",
+				"name": "Notify Warehouse",
+				"executor_key": "notify.v2",
+				"dependencies": ["notify_customer"],
+				"required": false,
+				"retry_policy": {"max_attempts": 5, "initial_interval": "10ms"}
+			}
+		]
+	}`
+
+	workflow, err := LoadWorkflowFromJSON(strings.NewReader(data), registry)
+	if err != nil {
+		t.Fatalf("LoadWorkflowFromJSON() error = %v", err)
+	}
+
+	if workflow.Version != "2.0.0" || workflow.FailurePolicy != FailurePolicyCompleteWave {
+		t.Errorf("LoadWorkflowFromJSON() workflow = %+v", workflow)
+	}
+	if len(workflow.Steps) != 2 {
+		t.Fatalf("LoadWorkflowFromJSON() steps = %d, want %d", len(workflow.Steps), 2)
+	}
+
+	first, second := workflow.Steps[0], workflow.Steps[1]
+	if first.Timeout != 5*time.Second || !first.Required {
+		t.Errorf("first step = %+v, want 5s timeout and required", first)
+	}
+	if second.Required || len(second.Dependencies) != 1 || second.Dependencies[0] != "notify_customer" {
+		t.Errorf("second step = %+v, want optional step after notify_customer", second)
+	}
+	if second.RetryPolicy == nil || second.RetryPolicy.MaxAttempts != 5 || second.RetryPolicy.InitialInterval != 10*time.Millisecond {
+		t.Errorf("second step retry policy = %+v", second.RetryPolicy)
+	}
+
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	orchestrator.RegisterWorkflow(workflow)
+	result, err := orchestrator.StartWorkflow(context.Background(), "order", nil, nil)
+	if err != nil || !result.Success {
+		t.Errorf("StartWorkflow() with loaded workflow error = %v", err)
+	}
+}
+
+func TestLoadWorkflowFromJSON_Errors(t *testing.T) {
+	registry := NewExecutorRegistry()
+	registry.Register("known", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"unbound executor key", `{"id": "w", "name": "W", "steps": [{"id": "s", "name": "S", "executor_key": "unknown"}]}`, ErrExecutorNotFound},
+		{"missing executor key", `{"id": "w", "name": "W", "steps": [{"id": "s", "name": "S"}]}`, nil},
+		{"invalid timeout", `{"id": "w", "name": "W", "steps": [{"id": "s", "name": "S", "executor_key": "known", "timeout": "soon"}]}`, nil},
+		{"invalid dependency", `{"id": "w", "name": "W", "steps": [{"id": "s", "name": "S", "executor_key": "known", "dependencies": ["x"]}]}`, nil},
+		{"unknown field", `{"id": "w", "name": "W", "steps": [{"id": "s", "name": "S", "executor": "known"}]}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWorkflowFromJSON(strings.NewReader(tt.data), registry)
+			if err == nil {
+				t.Fatalf("LoadWorkflowFromJSON() should fail")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadWorkflowFromJSON() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
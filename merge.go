@@ -0,0 +1,64 @@
+package orchwf
+
+import "context"
+
+// deepMerge reports whether the workflow merges nested step outputs recursively
+func (o *Orchestrator) deepMerge(workflowID string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if workflow, ok := o.workflows[workflowID]; ok {
+		return workflow.DeepMerge
+	}
+	return false
+}
+
+// withDeferredMerge returns a copy of ctx in which completing the given step leaves
+// merging its output into the workflow to the caller
+func withDeferredMerge(ctx context.Context, stepID string) context.Context {
+	return context.WithValue(ctx, deferredMergeKey, stepID)
+}
+
+// mergeDeferred reports whether ctx leaves merging the step's output to the caller
+func mergeDeferred(ctx context.Context, stepID string) bool {
+	deferred, _ := ctx.Value(deferredMergeKey).(string)
+	return deferred == stepID
+}
+
+// mergeAsyncOutputs merges the outputs of a wave's completed async steps once all of
+// them are done. asyncSteps is in scheduling order, by priority then declaration; it is
+// merged back to front, so when siblings write the same key the first of them wins.
+func (o *Orchestrator) mergeAsyncOutputs(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, asyncSteps []*StepDefinition, stepInstMap map[string]*StepInstance) {
+	var output map[string]interface{}
+	for i := len(asyncSteps) - 1; i >= 0; i-- {
+		stepInst := stepInstMap[asyncSteps[i].ID]
+		if stepInst.Status != StepStatusCompleted {
+			continue
+		}
+		output = o.mergeStepOutput(instance, stepInst.StepID, stepInst.Output)
+	}
+	if output == nil || workflow.PersistPolicy == PersistOnFailure {
+		return
+	}
+	o.notePersistence(o.stateManager.UpdateWorkflowOutput(ctx, instance.ID, redactMap(output, workflow.RedactedKeys)))
+}
+
+// mergeValue returns src merged over dst. When both are maps the result is a new map
+// holding the keys of both, merged recursively; otherwise src replaces dst. Neither
+// value is modified, since step outputs are shared with the step instances.
+func mergeValue(dst, src interface{}) interface{} {
+	dstMap, dstOK := dst.(map[string]interface{})
+	srcMap, srcOK := src.(map[string]interface{})
+	if !dstOK || !srcOK {
+		return src
+	}
+
+	merged := make(map[string]interface{}, len(dstMap)+len(srcMap))
+	for k, v := range dstMap {
+		merged[k] = v
+	}
+	for k, v := range srcMap {
+		merged[k] = mergeValue(merged[k], v)
+	}
+	return merged
+}
@@ -0,0 +1,138 @@
+package orchwf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newNestedOutputWorkflow builds a workflow whose two steps write into the same nested customer map
+func newNestedOutputWorkflow(deep bool) *WorkflowDefinition {
+	workflow, _ := NewWorkflowBuilder("nested", "Nested").
+		WithDeepMerge(deep).
+		AddStepFunc("billing", "Billing", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"customer": map[string]interface{}{"billing": map[string]interface{}{"card": "visa"}, "tier": "gold"},
+			}, nil
+		}).
+		AddStepFunc("shipping", "Shipping", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"customer": map[string]interface{}{"billing": map[string]interface{}{"zip": "10115"}, "address": "Main St"},
+			}, nil
+		}, WithStepDeps("billing")).
+		Build()
+	return workflow
+}
+
+func TestOrchestrator_DeepMerge(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	orchestrator.RegisterWorkflow(newNestedOutputWorkflow(true))
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "nested", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"billing": map[string]interface{}{"card": "visa", "zip": "10115"},
+		"tier":    "gold",
+		"address": "Main St",
+	}
+	if !reflect.DeepEqual(result.Output["customer"], want) {
+		t.Errorf("customer = %v, want %v", result.Output["customer"], want)
+	}
+	persisted, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if !reflect.DeepEqual(persisted.Output["customer"], want) {
+		t.Errorf("persisted customer = %v, want %v", persisted.Output["customer"], want)
+	}
+
+	// Merging builds new maps, so each step keeps its own output
+	billing, _ := result.StepOutput("billing")
+	wantBilling := map[string]interface{}{"billing": map[string]interface{}{"card": "visa"}, "tier": "gold"}
+	if !reflect.DeepEqual(billing["customer"], wantBilling) {
+		t.Errorf("billing step output = %v, want %v", billing["customer"], wantBilling)
+	}
+}
+
+func TestOrchestrator_ShallowMergeByDefault(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	orchestrator.RegisterWorkflow(newNestedOutputWorkflow(false))
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "nested", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	want := map[string]interface{}{"billing": map[string]interface{}{"zip": "10115"}, "address": "Main St"}
+	if !reflect.DeepEqual(result.Output["customer"], want) {
+		t.Errorf("customer = %v, want the later step's value %v", result.Output["customer"], want)
+	}
+}
+
+func TestOrchestrator_AsyncOutputsMergeInStableOrder(t *testing.T) {
+	// writer returns an executor that sets "winner" to name after sleeping for delay
+	writer := func(name string, delay time.Duration) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			time.Sleep(delay)
+			return map[string]interface{}{"winner": name, name: true}, nil
+		}
+	}
+
+	tests := []struct {
+		name  string
+		steps []*StepDefinition
+		want  string
+	}{
+		{
+			// The high-priority step finishes first, so merging on completion would let "low" win
+			name: "higher priority wins",
+			steps: []*StepDefinition{
+				{ID: "low", Name: "Low", Executor: writer("low", 20*time.Millisecond), Async: true, Priority: 1, Required: true},
+				{ID: "high", Name: "High", Executor: writer("high", 0), Async: true, Priority: 5, Required: true},
+			},
+			want: "high",
+		},
+		{
+			name: "earlier declared wins on equal priority",
+			steps: []*StepDefinition{
+				{ID: "first", Name: "First", Executor: writer("first", 0), Async: true, Required: true},
+				{ID: "second", Name: "Second", Executor: writer("second", 20*time.Millisecond), Async: true, Required: true},
+			},
+			want: "first",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for run := 0; run < 5; run++ {
+				sm := NewInMemoryStateManager()
+				orchestrator := NewOrchestrator(sm)
+				builder := NewWorkflowBuilder("siblings", "Siblings")
+				for _, step := range tt.steps {
+					builder.AddStep(step)
+				}
+				workflow, _ := builder.Build()
+				orchestrator.RegisterWorkflow(workflow)
+
+				result, err := orchestrator.StartWorkflow(context.Background(), "siblings", nil, nil)
+				if err != nil {
+					t.Fatalf("StartWorkflow() error = %v", err)
+				}
+				if result.Output["winner"] != tt.want {
+					t.Fatalf("run %d: output winner = %v, want %s", run, result.Output["winner"], tt.want)
+				}
+				// Keys only one sibling writes are kept
+				for _, step := range tt.steps {
+					if result.Output[step.ID] != true {
+						t.Errorf("output = %v, want the key written by %s", result.Output, step.ID)
+					}
+				}
+				stored, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+				if stored.Output["winner"] != tt.want {
+					t.Errorf("persisted output winner = %v, want %s", stored.Output["winner"], tt.want)
+				}
+			}
+		})
+	}
+}
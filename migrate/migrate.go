@@ -17,28 +17,54 @@ type Migration struct {
 	Down        string
 }
 
+// DefaultTablePrefix is the prefix used for OrchWF table names when none is configured
+const DefaultTablePrefix = "orchwf_"
+
+// Options configures a Migrator
+type Options struct {
+	// TablePrefix is prepended to every OrchWF table name (default "orchwf_")
+	TablePrefix string
+}
+
 // Migrator handles database migrations
 type Migrator struct {
-	db         *sql.DB
-	migrations []Migration
+	db              *sql.DB
+	migrations      []Migration
+	migrationsTable string
 }
 
 // NewMigrator creates a new migrator instance
 func NewMigrator(db *sql.DB) *Migrator {
+	return NewMigratorWithOptions(db, Options{})
+}
+
+// NewMigratorWithOptions creates a new migrator whose default migrations use the configured table prefix
+func NewMigratorWithOptions(db *sql.DB, opts Options) *Migrator {
+	prefix := tablePrefix(opts)
 	return &Migrator{
-		db:         db,
-		migrations: getDefaultMigrations(),
+		db:              db,
+		migrations:      getDefaultMigrations(prefix),
+		migrationsTable: prefix + "migrations",
 	}
 }
 
 // NewMigratorWithMigrations creates a new migrator with custom migrations
 func NewMigratorWithMigrations(db *sql.DB, migrations []Migration) *Migrator {
 	return &Migrator{
-		db:         db,
-		migrations: migrations,
+		db:              db,
+		migrations:      migrations,
+		migrationsTable: DefaultTablePrefix + "migrations",
 	}
 }
 
+// tablePrefix returns the configured table prefix or the default
+func tablePrefix(opts Options) string {
+	if opts.TablePrefix == "" {
+		return DefaultTablePrefix
+	}
+	return opts.TablePrefix
+}
+
 // Migrate runs all pending migrations
 func (m *Migrator) Migrate(ctx context.Context) error {
 	// Create migrations table if it doesn't exist
@@ -118,12 +144,12 @@ func (m *Migrator) Status(ctx context.Context) error {
 
 // createMigrationsTable creates the migrations tracking table
 func (m *Migrator) createMigrationsTable(ctx context.Context) error {
-	query := `
-	CREATE TABLE IF NOT EXISTS orchwf_migrations (
+	query := fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
 		version VARCHAR(255) PRIMARY KEY,
 		description TEXT,
 		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);`
+	);`, m.migrationsTable)
 
 	_, err := m.db.ExecContext(ctx, query)
 	return err
@@ -131,7 +157,7 @@ func (m *Migrator) createMigrationsTable(ctx context.Context) error {
 
 // getAppliedMigrations returns a map of applied migration versions
 func (m *Migrator) getAppliedMigrations(ctx context.Context) (map[string]bool, error) {
-	query := `SELECT version FROM orchwf_migrations ORDER BY applied_at`
+	query := fmt.Sprintf(`SELECT version FROM %s ORDER BY applied_at`, m.migrationsTable)
 	rows, err := m.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -164,7 +190,7 @@ func (m *Migrator) applyMigration(ctx context.Context, migration Migration) erro
 	}
 
 	// Record the migration
-	query := `INSERT INTO orchwf_migrations (version, description) VALUES ($1, $2)`
+	query := fmt.Sprintf(`INSERT INTO %s (version, description) VALUES ($1, $2)`, m.migrationsTable)
 	if _, err := tx.ExecContext(ctx, query, migration.Version, migration.Description); err != nil {
 		return err
 	}
@@ -190,7 +216,7 @@ func (m *Migrator) rollbackMigration(ctx context.Context, migration Migration) e
 	}
 
 	// Remove the migration record
-	query := `DELETE FROM orchwf_migrations WHERE version = $1`
+	query := fmt.Sprintf(`DELETE FROM %s WHERE version = $1`, m.migrationsTable)
 	if _, err := tx.ExecContext(ctx, query, migration.Version); err != nil {
 		return err
 	}
@@ -198,24 +224,72 @@ func (m *Migrator) rollbackMigration(ctx context.Context, migration Migration) e
 	return tx.Commit()
 }
 
-// getDefaultMigrations returns the default OrchWF migrations
-func getDefaultMigrations() []Migration {
+// getDefaultMigrations returns the default OrchWF migrations for the given table prefix
+func getDefaultMigrations(prefix string) []Migration {
 	return []Migration{
 		{
 			Version:     "001",
 			Description: "Create OrchWF tables",
-			Up:          getOrchWFTablesSQL(),
-			Down:        getOrchWFTablesRollbackSQL(),
+			Up:          getOrchWFTablesSQL(prefix),
+			Down:        getOrchWFTablesRollbackSQL(prefix),
+		},
+		{
+			Version:     "002",
+			Description: "Add step wake_at for durable timers",
+			Up:          getStepWakeAtSQL(prefix),
+			Down:        getStepWakeAtRollbackSQL(prefix),
+		},
+		{
+			Version:     "003",
+			Description: "Add parent workflow instance link",
+			Up:          getParentWorkflowInstIDSQL(prefix),
+			Down:        getParentWorkflowInstIDRollbackSQL(prefix),
+		},
+		{
+			Version:     "004",
+			Description: "Add step priority",
+			Up:          getStepPrioritySQL(prefix),
+			Down:        getStepPriorityRollbackSQL(prefix),
+		},
+		{
+			Version:     "005",
+			Description: "Add step skip reason",
+			Up:          getStepSkipReasonSQL(prefix),
+			Down:        getStepSkipReasonRollbackSQL(prefix),
+		},
+		{
+			Version:     "006",
+			Description: "Add step ready time and wait duration",
+			Up:          getStepWaitTimeSQL(prefix),
+			Down:        getStepWaitTimeRollbackSQL(prefix),
+		},
+		{
+			Version:     "007",
+			Description: "Add step attachments",
+			Up:          getStepAttachmentsSQL(prefix),
+			Down:        getStepAttachmentsRollbackSQL(prefix),
+		},
+		{
+			Version:     "008",
+			Description: "Add step input provenance",
+			Up:          getStepInputProvenanceSQL(prefix),
+			Down:        getStepInputProvenanceRollbackSQL(prefix),
+		},
+		{
+			Version:     "009",
+			Description: "Add step failure kind",
+			Up:          getStepFailureKindSQL(prefix),
+			Down:        getStepFailureKindRollbackSQL(prefix),
 		},
 	}
 }
 
 // getOrchWFTablesSQL returns the SQL for creating OrchWF tables
-func getOrchWFTablesSQL() string {
-	return `-- Create ORCHWF (Workflow Orchestration) tables
+func getOrchWFTablesSQL(prefix string) string {
+	return fmt.Sprintf(`-- Create ORCHWF (Workflow Orchestration) tables
 
 -- Workflow instances table
-CREATE TABLE IF NOT EXISTS orchwf_workflow_instances (
+CREATE TABLE IF NOT EXISTS %[1]sworkflow_instances (
     id VARCHAR(36) PRIMARY KEY,
     workflow_id VARCHAR(255) NOT NULL,
     status VARCHAR(50) NOT NULL,
@@ -237,15 +311,15 @@ CREATE TABLE IF NOT EXISTS orchwf_workflow_instances (
 );
 
 -- Create indexes for workflow instances
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_workflow_id ON orchwf_workflow_instances(workflow_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_status ON orchwf_workflow_instances(status);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_trace_id ON orchwf_workflow_instances(trace_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_correlation_id ON orchwf_workflow_instances(correlation_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_business_id ON orchwf_workflow_instances(business_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_created_at ON orchwf_workflow_instances(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_workflow_id ON %[1]sworkflow_instances(workflow_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_status ON %[1]sworkflow_instances(status);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_trace_id ON %[1]sworkflow_instances(trace_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_correlation_id ON %[1]sworkflow_instances(correlation_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_business_id ON %[1]sworkflow_instances(business_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_created_at ON %[1]sworkflow_instances(created_at DESC);
 
 -- Step instances table
-CREATE TABLE IF NOT EXISTS orchwf_step_instances (
+CREATE TABLE IF NOT EXISTS %[1]sstep_instances (
     id VARCHAR(36) PRIMARY KEY,
     step_id VARCHAR(255) NOT NULL,
     workflow_inst_id VARCHAR(36) NOT NULL,
@@ -261,17 +335,17 @@ CREATE TABLE IF NOT EXISTS orchwf_step_instances (
     execution_order INT NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (workflow_inst_id) REFERENCES orchwf_workflow_instances(id) ON DELETE CASCADE
+    FOREIGN KEY (workflow_inst_id) REFERENCES %[1]sworkflow_instances(id) ON DELETE CASCADE
 );
 
 -- Create indexes for step instances
-CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_step_id ON orchwf_step_instances(step_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_workflow_inst_id ON orchwf_step_instances(workflow_inst_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_status ON orchwf_step_instances(status);
-CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_execution_order ON orchwf_step_instances(workflow_inst_id, execution_order);
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_step_id ON %[1]sstep_instances(step_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_workflow_inst_id ON %[1]sstep_instances(workflow_inst_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_status ON %[1]sstep_instances(status);
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_execution_order ON %[1]sstep_instances(workflow_inst_id, execution_order);
 
 -- Workflow events table
-CREATE TABLE IF NOT EXISTS orchwf_workflow_events (
+CREATE TABLE IF NOT EXISTS %[1]sworkflow_events (
     id VARCHAR(36) PRIMARY KEY,
     workflow_inst_id VARCHAR(36) NOT NULL,
     step_inst_id VARCHAR(36),
@@ -279,18 +353,18 @@ CREATE TABLE IF NOT EXISTS orchwf_workflow_events (
     event_data JSONB DEFAULT '{}',
     timestamp TIMESTAMP NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (workflow_inst_id) REFERENCES orchwf_workflow_instances(id) ON DELETE CASCADE,
-    FOREIGN KEY (step_inst_id) REFERENCES orchwf_step_instances(id) ON DELETE CASCADE
+    FOREIGN KEY (workflow_inst_id) REFERENCES %[1]sworkflow_instances(id) ON DELETE CASCADE,
+    FOREIGN KEY (step_inst_id) REFERENCES %[1]sstep_instances(id) ON DELETE CASCADE
 );
 
 -- Create indexes for workflow events
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_events_workflow_inst_id ON orchwf_workflow_events(workflow_inst_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_events_step_inst_id ON orchwf_workflow_events(step_inst_id);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_events_event_type ON orchwf_workflow_events(event_type);
-CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_events_timestamp ON orchwf_workflow_events(timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_events_workflow_inst_id ON %[1]sworkflow_events(workflow_inst_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_events_step_inst_id ON %[1]sworkflow_events(step_inst_id);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_events_event_type ON %[1]sworkflow_events(event_type);
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_events_timestamp ON %[1]sworkflow_events(timestamp DESC);
 
 -- Create updated_at trigger function for workflow instances
-CREATE OR REPLACE FUNCTION update_orchwf_workflow_instances_updated_at()
+CREATE OR REPLACE FUNCTION update_%[1]sworkflow_instances_updated_at()
 RETURNS TRIGGER AS $$
 BEGIN
     NEW.updated_at = CURRENT_TIMESTAMP;
@@ -299,14 +373,14 @@ END;
 $$ LANGUAGE plpgsql;
 
 -- Create trigger for workflow instances
-DROP TRIGGER IF EXISTS trigger_update_orchwf_workflow_instances_updated_at ON orchwf_workflow_instances;
-CREATE TRIGGER trigger_update_orchwf_workflow_instances_updated_at
-    BEFORE UPDATE ON orchwf_workflow_instances
+DROP TRIGGER IF EXISTS trigger_update_%[1]sworkflow_instances_updated_at ON %[1]sworkflow_instances;
+CREATE TRIGGER trigger_update_%[1]sworkflow_instances_updated_at
+    BEFORE UPDATE ON %[1]sworkflow_instances
     FOR EACH ROW
-    EXECUTE FUNCTION update_orchwf_workflow_instances_updated_at();
+    EXECUTE FUNCTION update_%[1]sworkflow_instances_updated_at();
 
 -- Create updated_at trigger function for step instances
-CREATE OR REPLACE FUNCTION update_orchwf_step_instances_updated_at()
+CREATE OR REPLACE FUNCTION update_%[1]sstep_instances_updated_at()
 RETURNS TRIGGER AS $$
 BEGIN
     NEW.updated_at = CURRENT_TIMESTAMP;
@@ -315,29 +389,137 @@ END;
 $$ LANGUAGE plpgsql;
 
 -- Create trigger for step instances
-DROP TRIGGER IF EXISTS trigger_update_orchwf_step_instances_updated_at ON orchwf_step_instances;
-CREATE TRIGGER trigger_update_orchwf_step_instances_updated_at
-    BEFORE UPDATE ON orchwf_step_instances
+DROP TRIGGER IF EXISTS trigger_update_%[1]sstep_instances_updated_at ON %[1]sstep_instances;
+CREATE TRIGGER trigger_update_%[1]sstep_instances_updated_at
+    BEFORE UPDATE ON %[1]sstep_instances
     FOR EACH ROW
-    EXECUTE FUNCTION update_orchwf_step_instances_updated_at();`
+    EXECUTE FUNCTION update_%[1]sstep_instances_updated_at();`, prefix)
 }
 
 // getOrchWFTablesRollbackSQL returns the SQL for rolling back OrchWF tables
-func getOrchWFTablesRollbackSQL() string {
-	return `-- Rollback OrchWF tables
+func getOrchWFTablesRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`-- Rollback OrchWF tables
 
 -- Drop triggers
-DROP TRIGGER IF EXISTS trigger_update_orchwf_step_instances_updated_at ON orchwf_step_instances;
-DROP TRIGGER IF EXISTS trigger_update_orchwf_workflow_instances_updated_at ON orchwf_workflow_instances;
+DROP TRIGGER IF EXISTS trigger_update_%[1]sstep_instances_updated_at ON %[1]sstep_instances;
+DROP TRIGGER IF EXISTS trigger_update_%[1]sworkflow_instances_updated_at ON %[1]sworkflow_instances;
 
 -- Drop functions
-DROP FUNCTION IF EXISTS update_orchwf_step_instances_updated_at();
-DROP FUNCTION IF EXISTS update_orchwf_workflow_instances_updated_at();
+DROP FUNCTION IF EXISTS update_%[1]sstep_instances_updated_at();
+DROP FUNCTION IF EXISTS update_%[1]sworkflow_instances_updated_at();
 
 -- Drop tables (in reverse order due to foreign keys)
-DROP TABLE IF EXISTS orchwf_workflow_events;
-DROP TABLE IF EXISTS orchwf_step_instances;
-DROP TABLE IF EXISTS orchwf_workflow_instances;`
+DROP TABLE IF EXISTS %[1]sworkflow_events;
+DROP TABLE IF EXISTS %[1]sstep_instances;
+DROP TABLE IF EXISTS %[1]sworkflow_instances;`, prefix)
+}
+
+// getStepWakeAtSQL returns the SQL for adding durable timer support to step instances
+func getStepWakeAtSQL(prefix string) string {
+	return fmt.Sprintf(`-- Add durable timer support to step instances
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS wake_at TIMESTAMP;
+
+-- Index for polling waiting steps that are due
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_wake_at ON %[1]sstep_instances(status, wake_at);`, prefix)
+}
+
+// getStepWakeAtRollbackSQL returns the SQL for removing durable timer support
+func getStepWakeAtRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`DROP INDEX IF EXISTS idx_%[1]sstep_instances_wake_at;
+ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS wake_at;`, prefix)
+}
+
+// getParentWorkflowInstIDSQL returns the SQL for linking child workflow instances to their parent
+func getParentWorkflowInstIDSQL(prefix string) string {
+	return fmt.Sprintf(`-- Link child workflow instances to the instance that started them
+
+ALTER TABLE %[1]sworkflow_instances ADD COLUMN IF NOT EXISTS parent_workflow_inst_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS idx_%[1]sworkflow_instances_parent_workflow_inst_id ON %[1]sworkflow_instances(parent_workflow_inst_id);`, prefix)
+}
+
+// getParentWorkflowInstIDRollbackSQL returns the SQL for removing the parent workflow instance link
+func getParentWorkflowInstIDRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`DROP INDEX IF EXISTS idx_%[1]sworkflow_instances_parent_workflow_inst_id;
+ALTER TABLE %[1]sworkflow_instances DROP COLUMN IF EXISTS parent_workflow_inst_id;`, prefix)
+}
+
+// getStepPrioritySQL returns the SQL for persisting step priority on step instances
+func getStepPrioritySQL(prefix string) string {
+	return fmt.Sprintf(`-- Persist the effective step priority on step instances
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS priority INT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_%[1]sstep_instances_priority ON %[1]sstep_instances(workflow_inst_id, priority DESC);`, prefix)
+}
+
+// getStepPriorityRollbackSQL returns the SQL for removing step priority from step instances
+func getStepPriorityRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`DROP INDEX IF EXISTS idx_%[1]sstep_instances_priority;
+ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS priority;`, prefix)
+}
+
+// getStepSkipReasonSQL returns the SQL for adding the skip_reason column to step instances
+func getStepSkipReasonSQL(prefix string) string {
+	return fmt.Sprintf(`-- Record why a step instance was skipped
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS skip_reason VARCHAR(50);`, prefix)
+}
+
+// getStepSkipReasonRollbackSQL returns the SQL for removing the skip_reason column
+func getStepSkipReasonRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS skip_reason;`, prefix)
+}
+
+// getStepWaitTimeSQL returns the SQL for adding ready_at and wait_ms to step instances
+func getStepWaitTimeSQL(prefix string) string {
+	return fmt.Sprintf(`-- Record when a step became ready and how long it waited before starting
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS ready_at TIMESTAMP;
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS wait_ms BIGINT DEFAULT 0;`, prefix)
+}
+
+// getStepWaitTimeRollbackSQL returns the SQL for removing ready_at and wait_ms
+func getStepWaitTimeRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS wait_ms;
+ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS ready_at;`, prefix)
+}
+
+// getStepAttachmentsSQL returns the SQL for adding attachments to step instances
+func getStepAttachmentsSQL(prefix string) string {
+	return fmt.Sprintf(`-- Record artifacts produced by a step (name, content type and blob reference)
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS attachments JSONB DEFAULT '[]';`, prefix)
+}
+
+// getStepAttachmentsRollbackSQL returns the SQL for removing attachments
+func getStepAttachmentsRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS attachments;`, prefix)
+}
+
+// getStepInputProvenanceSQL returns the SQL for adding input provenance to step instances
+func getStepInputProvenanceSQL(prefix string) string {
+	return fmt.Sprintf(`-- Record where each key of a step's input came from (WithInputProvenance)
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS input_provenance JSONB;`, prefix)
+}
+
+// getStepInputProvenanceRollbackSQL returns the SQL for removing input provenance
+func getStepInputProvenanceRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS input_provenance;`, prefix)
+}
+
+// getStepFailureKindSQL returns the SQL for adding the failure_kind column to step instances
+func getStepFailureKindSQL(prefix string) string {
+	return fmt.Sprintf(`-- Record why a step instance failed
+
+ALTER TABLE %[1]sstep_instances ADD COLUMN IF NOT EXISTS failure_kind VARCHAR(50);`, prefix)
+}
+
+// getStepFailureKindRollbackSQL returns the SQL for removing the failure_kind column
+func getStepFailureKindRollbackSQL(prefix string) string {
+	return fmt.Sprintf(`ALTER TABLE %[1]sstep_instances DROP COLUMN IF EXISTS failure_kind;`, prefix)
 }
 
 // LoadMigrationsFromFile loads migrations from a SQL file
@@ -0,0 +1,6 @@
+-- Add durable timer support to step instances
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS wake_at TIMESTAMP;
+
+-- Index for polling waiting steps that are due
+CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_wake_at ON orchwf_step_instances(status, wake_at);
@@ -0,0 +1,5 @@
+-- Link child workflow instances to the instance that started them
+
+ALTER TABLE orchwf_workflow_instances ADD COLUMN IF NOT EXISTS parent_workflow_inst_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS idx_orchwf_workflow_instances_parent_workflow_inst_id ON orchwf_workflow_instances(parent_workflow_inst_id);
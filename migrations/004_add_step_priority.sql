@@ -0,0 +1,5 @@
+-- Persist the effective step priority on step instances
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS priority INT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_orchwf_step_instances_priority ON orchwf_step_instances(workflow_inst_id, priority DESC);
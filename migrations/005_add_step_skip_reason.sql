@@ -0,0 +1,3 @@
+-- Record why a step instance was skipped
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS skip_reason VARCHAR(50);
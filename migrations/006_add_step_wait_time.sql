@@ -0,0 +1,4 @@
+-- Record when a step became ready and how long it waited before starting
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS ready_at TIMESTAMP;
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS wait_ms BIGINT DEFAULT 0;
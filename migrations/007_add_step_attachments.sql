@@ -0,0 +1,3 @@
+-- Record artifacts produced by a step (name, content type and blob reference)
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS attachments JSONB DEFAULT '[]';
@@ -0,0 +1,3 @@
+-- Record where each key of a step's input came from (WithInputProvenance)
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS input_provenance JSONB;
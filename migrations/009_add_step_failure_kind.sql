@@ -0,0 +1,3 @@
+-- Record why a step instance failed
+
+ALTER TABLE orchwf_step_instances ADD COLUMN IF NOT EXISTS failure_kind VARCHAR(50);
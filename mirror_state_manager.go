@@ -0,0 +1,286 @@
+package orchwf
+
+import (
+	"context"
+	"time"
+)
+
+// MirrorStateManager writes to a primary and a secondary state manager, for example to
+// migrate between stores or keep a warm standby. Reads are served by the primary, which
+// is authoritative. Secondary writes are best effort: their errors are logged and never
+// fail the write, so the secondary can lag or miss writes the primary has.
+type MirrorStateManager struct {
+	primary   StateManager
+	secondary StateManager
+	logger    Logger
+}
+
+// NewMirrorStateManager creates a state manager that mirrors writes to secondary and
+// discards secondary write errors
+func NewMirrorStateManager(primary, secondary StateManager) *MirrorStateManager {
+	return NewMirrorStateManagerWithLogger(primary, secondary, nil)
+}
+
+// NewMirrorStateManagerWithLogger creates a state manager that mirrors writes to
+// secondary and logs secondary write errors to logger
+func NewMirrorStateManagerWithLogger(primary, secondary StateManager, logger Logger) *MirrorStateManager {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	return &MirrorStateManager{primary: primary, secondary: secondary, logger: logger}
+}
+
+// mirror logs the error of a secondary operation, if any
+func (m *MirrorStateManager) mirror(op string, err error) {
+	if err != nil {
+		m.logger.Printf("orchwf: mirroring %s to secondary state manager failed: %v", op, err)
+	}
+}
+
+// SaveWorkflow saves a workflow instance to both stores
+func (m *MirrorStateManager) SaveWorkflow(ctx context.Context, workflow *WorkflowInstance) error {
+	if err := m.primary.SaveWorkflow(ctx, workflow); err != nil {
+		return err
+	}
+	m.mirror("SaveWorkflow", m.secondary.SaveWorkflow(ctx, workflow))
+	return nil
+}
+
+// GetWorkflow retrieves a workflow instance from the primary
+func (m *MirrorStateManager) GetWorkflow(ctx context.Context, workflowInstID string) (*WorkflowInstance, error) {
+	return m.primary.GetWorkflow(ctx, workflowInstID)
+}
+
+// UpdateWorkflowStatus updates the workflow status in both stores
+func (m *MirrorStateManager) UpdateWorkflowStatus(ctx context.Context, workflowInstID string, status WorkflowStatus) error {
+	if err := m.primary.UpdateWorkflowStatus(ctx, workflowInstID, status); err != nil {
+		return err
+	}
+	m.mirror("UpdateWorkflowStatus", m.secondary.UpdateWorkflowStatus(ctx, workflowInstID, status))
+	return nil
+}
+
+// UpdateWorkflowOutput updates the workflow output in both stores
+func (m *MirrorStateManager) UpdateWorkflowOutput(ctx context.Context, workflowInstID string, output map[string]interface{}) error {
+	if err := m.primary.UpdateWorkflowOutput(ctx, workflowInstID, output); err != nil {
+		return err
+	}
+	m.mirror("UpdateWorkflowOutput", m.secondary.UpdateWorkflowOutput(ctx, workflowInstID, output))
+	return nil
+}
+
+// UpdateWorkflowError updates the workflow error in both stores
+func (m *MirrorStateManager) UpdateWorkflowError(ctx context.Context, workflowInstID string, err error) error {
+	if perr := m.primary.UpdateWorkflowError(ctx, workflowInstID, err); perr != nil {
+		return perr
+	}
+	m.mirror("UpdateWorkflowError", m.secondary.UpdateWorkflowError(ctx, workflowInstID, err))
+	return nil
+}
+
+// UpdateWorkflowMetadata updates the workflow metadata in both stores
+func (m *MirrorStateManager) UpdateWorkflowMetadata(ctx context.Context, workflowInstID string, metadata map[string]interface{}) error {
+	if err := m.primary.UpdateWorkflowMetadata(ctx, workflowInstID, metadata); err != nil {
+		return err
+	}
+	m.mirror("UpdateWorkflowMetadata", m.secondary.UpdateWorkflowMetadata(ctx, workflowInstID, metadata))
+	return nil
+}
+
+// UpdateWorkflowCurrentStep updates the current step in both stores
+func (m *MirrorStateManager) UpdateWorkflowCurrentStep(ctx context.Context, workflowInstID string, stepID string) error {
+	if err := m.primary.UpdateWorkflowCurrentStep(ctx, workflowInstID, stepID); err != nil {
+		return err
+	}
+	m.mirror("UpdateWorkflowCurrentStep", m.secondary.UpdateWorkflowCurrentStep(ctx, workflowInstID, stepID))
+	return nil
+}
+
+// ListWorkflows lists workflows from the primary
+func (m *MirrorStateManager) ListWorkflows(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*WorkflowInstance, int64, error) {
+	return m.primary.ListWorkflows(ctx, filters, limit, offset)
+}
+
+// CountWorkflowsByStatus counts workflows in the primary
+func (m *MirrorStateManager) CountWorkflowsByStatus(ctx context.Context, filters map[string]interface{}) (map[WorkflowStatus]int64, error) {
+	return m.primary.CountWorkflowsByStatus(ctx, filters)
+}
+
+// GetChildWorkflows retrieves child workflows from the primary
+func (m *MirrorStateManager) GetChildWorkflows(ctx context.Context, parentInstID string) ([]*WorkflowInstance, error) {
+	return m.primary.GetChildWorkflows(ctx, parentInstID)
+}
+
+// SaveStep saves a step instance to both stores
+func (m *MirrorStateManager) SaveStep(ctx context.Context, step *StepInstance) error {
+	if err := m.primary.SaveStep(ctx, step); err != nil {
+		return err
+	}
+	m.mirror("SaveStep", m.secondary.SaveStep(ctx, step))
+	return nil
+}
+
+// SaveSteps saves step instances to both stores
+func (m *MirrorStateManager) SaveSteps(ctx context.Context, steps []*StepInstance) error {
+	if err := m.primary.SaveSteps(ctx, steps); err != nil {
+		return err
+	}
+	m.mirror("SaveSteps", m.secondary.SaveSteps(ctx, steps))
+	return nil
+}
+
+// GetStep retrieves a step instance from the primary
+func (m *MirrorStateManager) GetStep(ctx context.Context, stepInstID string) (*StepInstance, error) {
+	return m.primary.GetStep(ctx, stepInstID)
+}
+
+// GetWorkflowSteps retrieves the steps of a workflow from the primary
+func (m *MirrorStateManager) GetWorkflowSteps(ctx context.Context, workflowInstID string) ([]*StepInstance, error) {
+	return m.primary.GetWorkflowSteps(ctx, workflowInstID)
+}
+
+// UpdateStepStatus updates the step status in both stores
+func (m *MirrorStateManager) UpdateStepStatus(ctx context.Context, stepInstID string, status StepStatus) error {
+	if err := m.primary.UpdateStepStatus(ctx, stepInstID, status); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepStatus", m.secondary.UpdateStepStatus(ctx, stepInstID, status))
+	return nil
+}
+
+// UpdateStepOutput updates the step output in both stores
+func (m *MirrorStateManager) UpdateStepOutput(ctx context.Context, stepInstID string, output map[string]interface{}) error {
+	if err := m.primary.UpdateStepOutput(ctx, stepInstID, output); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepOutput", m.secondary.UpdateStepOutput(ctx, stepInstID, output))
+	return nil
+}
+
+// UpdateStepError updates the step error in both stores
+func (m *MirrorStateManager) UpdateStepError(ctx context.Context, stepInstID string, err error) error {
+	if perr := m.primary.UpdateStepError(ctx, stepInstID, err); perr != nil {
+		return perr
+	}
+	m.mirror("UpdateStepError", m.secondary.UpdateStepError(ctx, stepInstID, err))
+	return nil
+}
+
+// UpdateStepSkipReason updates the step skip reason in both stores
+func (m *MirrorStateManager) UpdateStepSkipReason(ctx context.Context, stepInstID string, reason SkipReason) error {
+	if err := m.primary.UpdateStepSkipReason(ctx, stepInstID, reason); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepSkipReason", m.secondary.UpdateStepSkipReason(ctx, stepInstID, reason))
+	return nil
+}
+
+// UpdateStepFailureKind updates the step failure kind in both stores
+func (m *MirrorStateManager) UpdateStepFailureKind(ctx context.Context, stepInstID string, kind FailureKind) error {
+	if err := m.primary.UpdateStepFailureKind(ctx, stepInstID, kind); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepFailureKind", m.secondary.UpdateStepFailureKind(ctx, stepInstID, kind))
+	return nil
+}
+
+// UpdateStepWait updates the step wait in both stores
+func (m *MirrorStateManager) UpdateStepWait(ctx context.Context, stepInstID string, readyAt time.Time, waitMs int64) error {
+	if err := m.primary.UpdateStepWait(ctx, stepInstID, readyAt, waitMs); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepWait", m.secondary.UpdateStepWait(ctx, stepInstID, readyAt, waitMs))
+	return nil
+}
+
+// UpdateStepRetry updates the step retry information in both stores
+func (m *MirrorStateManager) UpdateStepRetry(ctx context.Context, stepInstID string, retryCount int, lastRetryAt *time.Time) error {
+	if err := m.primary.UpdateStepRetry(ctx, stepInstID, retryCount, lastRetryAt); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepRetry", m.secondary.UpdateStepRetry(ctx, stepInstID, retryCount, lastRetryAt))
+	return nil
+}
+
+// UpdateStepWakeAt updates the step wake time in both stores
+func (m *MirrorStateManager) UpdateStepWakeAt(ctx context.Context, stepInstID string, wakeAt time.Time) error {
+	if err := m.primary.UpdateStepWakeAt(ctx, stepInstID, wakeAt); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepWakeAt", m.secondary.UpdateStepWakeAt(ctx, stepInstID, wakeAt))
+	return nil
+}
+
+// UpdateStepAttachments updates the step attachments in both stores
+func (m *MirrorStateManager) UpdateStepAttachments(ctx context.Context, stepInstID string, attachments []Attachment) error {
+	if err := m.primary.UpdateStepAttachments(ctx, stepInstID, attachments); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepAttachments", m.secondary.UpdateStepAttachments(ctx, stepInstID, attachments))
+	return nil
+}
+
+// UpdateStepProvenance updates the step provenance in both stores
+func (m *MirrorStateManager) UpdateStepProvenance(ctx context.Context, stepInstID string, provenance map[string]string) error {
+	if err := m.primary.UpdateStepProvenance(ctx, stepInstID, provenance); err != nil {
+		return err
+	}
+	m.mirror("UpdateStepProvenance", m.secondary.UpdateStepProvenance(ctx, stepInstID, provenance))
+	return nil
+}
+
+// ResetStep resets a step in both stores
+func (m *MirrorStateManager) ResetStep(ctx context.Context, stepInstID string) error {
+	if err := m.primary.ResetStep(ctx, stepInstID); err != nil {
+		return err
+	}
+	m.mirror("ResetStep", m.secondary.ResetStep(ctx, stepInstID))
+	return nil
+}
+
+// GetDueWaitingSteps retrieves due waiting steps from the primary
+func (m *MirrorStateManager) GetDueWaitingSteps(ctx context.Context, before time.Time) ([]*StepInstance, error) {
+	return m.primary.GetDueWaitingSteps(ctx, before)
+}
+
+// SaveEvent saves an event to both stores
+func (m *MirrorStateManager) SaveEvent(ctx context.Context, event *WorkflowEvent) error {
+	if err := m.primary.SaveEvent(ctx, event); err != nil {
+		return err
+	}
+	m.mirror("SaveEvent", m.secondary.SaveEvent(ctx, event))
+	return nil
+}
+
+// GetWorkflowEvents retrieves the events of a workflow from the primary
+func (m *MirrorStateManager) GetWorkflowEvents(ctx context.Context, workflowInstID string) ([]*WorkflowEvent, error) {
+	return m.primary.GetWorkflowEvents(ctx, workflowInstID)
+}
+
+// DeleteEventsOlderThan deletes old events from both stores and returns the number
+// deleted from the primary
+func (m *MirrorStateManager) DeleteEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	deleted, err := m.primary.DeleteEventsOlderThan(ctx, cutoff)
+	if err != nil {
+		return deleted, err
+	}
+	_, err = m.secondary.DeleteEventsOlderThan(ctx, cutoff)
+	m.mirror("DeleteEventsOlderThan", err)
+	return deleted, nil
+}
+
+// WithTransaction runs fn in a transaction on the primary. The secondary is not
+// transactional: writes made in fn reach it as they happen, even if the primary
+// transaction later rolls back.
+func (m *MirrorStateManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.primary.WithTransaction(ctx, fn)
+}
+
+// Ping checks the primary. An unreachable secondary is logged, not reported.
+func (m *MirrorStateManager) Ping(ctx context.Context) error {
+	if err := m.primary.Ping(ctx); err != nil {
+		return err
+	}
+	m.mirror("Ping", m.secondary.Ping(ctx))
+	return nil
+}
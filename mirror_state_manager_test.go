@@ -0,0 +1,92 @@
+package orchwf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newMirrorTestOrchestrator(sm StateManager) *Orchestrator {
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("mirrored", "Mirrored").
+		AddStepFunc("first", "First", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"value": 1}, nil
+		}).
+		AddStepFunc("second", "Second", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"value": 2}, nil
+		}, WithStepDeps("first")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	return orchestrator
+}
+
+func TestMirrorStateManager_WritesToBothStores(t *testing.T) {
+	primary, secondary := NewInMemoryStateManager(), NewInMemoryStateManager()
+	orchestrator := newMirrorTestOrchestrator(NewMirrorStateManager(primary, secondary))
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "mirrored", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	for name, sm := range map[string]*InMemoryStateManager{"primary": primary, "secondary": secondary} {
+		instance, err := sm.GetWorkflow(ctx, result.WorkflowInst.ID)
+		if err != nil {
+			t.Fatalf("%s GetWorkflow() error = %v", name, err)
+		}
+		if instance.Status != WorkflowStatusCompleted {
+			t.Errorf("%s status = %v, want %v", name, instance.Status, WorkflowStatusCompleted)
+		}
+		if len(instance.Steps) != 2 {
+			t.Fatalf("%s steps = %d, want 2", name, len(instance.Steps))
+		}
+		for _, step := range instance.Steps {
+			if step.Status != StepStatusCompleted {
+				t.Errorf("%s step %s status = %v, want %v", name, step.StepID, step.Status, StepStatusCompleted)
+			}
+		}
+		events, _ := sm.GetWorkflowEvents(ctx, result.WorkflowInst.ID)
+		if len(events) == 0 {
+			t.Errorf("%s has no events", name)
+		}
+	}
+}
+
+func TestMirrorStateManager_SecondaryFailuresAreLogged(t *testing.T) {
+	primary := NewInMemoryStateManager()
+	secondary := &flakyStateManager{InMemoryStateManager: NewInMemoryStateManager()}
+	secondary.down.Store(true)
+	logger := &recordingLogger{}
+	sm := NewMirrorStateManagerWithLogger(primary, secondary, logger)
+	orchestrator := newMirrorTestOrchestrator(sm)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "mirrored", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v, want nil despite the secondary failing", err)
+	}
+	if err := sm.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v, want nil while the primary is up", err)
+	}
+
+	instance, _ := sm.GetWorkflow(ctx, result.WorkflowInst.ID)
+	if instance.Status != WorkflowStatusCompleted {
+		t.Errorf("primary status = %v, want %v", instance.Status, WorkflowStatusCompleted)
+	}
+	if mirrored, _ := secondary.GetWorkflow(ctx, result.WorkflowInst.ID); mirrored == nil {
+		t.Error("secondary is missing the instance its SaveWorkflow accepted")
+	} else if mirrored.Status == WorkflowStatusCompleted {
+		t.Error("secondary status was updated while its writes were failing")
+	}
+
+	var failures int
+	for _, message := range logger.messages {
+		if strings.Contains(message, "secondary state manager failed") {
+			failures++
+		}
+	}
+	if failures == 0 {
+		t.Errorf("logged %v, want secondary write failures", logger.messages)
+	}
+}
@@ -27,6 +27,7 @@ type ORCHWorkflowInstance struct {
 	TraceID       string
 	CorrelationID string
 	BusinessID    string
+	ParentInstID  *string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	Steps         []ORCHStepInstance
@@ -48,6 +49,13 @@ type ORCHStepInstance struct {
 	DurationMs     int64
 	ExecutionOrder int
 	Priority       int
+	WakeAt         *time.Time
+	SkipReason     *string
+	FailureKind    *string
+	ReadyAt        *time.Time
+	WaitMs         int64
+	Attachments    []Attachment
+	Provenance     map[string]string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -111,6 +119,9 @@ func workflowInstanceToModel(w *WorkflowInstance) *ORCHWorkflowInstance {
 	if w.CurrentStepID != "" {
 		model.CurrentStepID = &w.CurrentStepID
 	}
+	if w.ParentInstID != "" {
+		model.ParentInstID = &w.ParentInstID
+	}
 
 	// Convert JSONB fields
 	if w.Input != nil {
@@ -151,6 +162,9 @@ func modelToWorkflowInstance(m *ORCHWorkflowInstance) (*WorkflowInstance, error)
 	if m.CurrentStepID != nil {
 		w.CurrentStepID = *m.CurrentStepID
 	}
+	if m.ParentInstID != nil {
+		w.ParentInstID = *m.ParentInstID
+	}
 
 	// Convert JSONB fields
 	if m.Input != nil {
@@ -207,6 +221,14 @@ func stepInstanceToModel(s *StepInstance) *ORCHStepInstance {
 		LastRetryAt:    s.LastRetryAt,
 		DurationMs:     s.DurationMs,
 		ExecutionOrder: s.ExecutionOrder,
+		Priority:       s.Priority,
+		WakeAt:         s.WakeAt,
+		SkipReason:     stringPtr(string(s.SkipReason)),
+		FailureKind:    stringPtr(string(s.FailureKind)),
+		ReadyAt:        s.ReadyAt,
+		WaitMs:         s.WaitMs,
+		Attachments:    s.Attachments,
+		Provenance:     s.Provenance,
 	}
 
 	// Convert JSONB fields
@@ -235,6 +257,18 @@ func modelToStepInstance(m *ORCHStepInstance) (*StepInstance, error) {
 		LastRetryAt:    m.LastRetryAt,
 		DurationMs:     m.DurationMs,
 		ExecutionOrder: m.ExecutionOrder,
+		Priority:       m.Priority,
+		WakeAt:         m.WakeAt,
+		ReadyAt:        m.ReadyAt,
+		WaitMs:         m.WaitMs,
+		Attachments:    m.Attachments,
+		Provenance:     m.Provenance,
+	}
+	if m.SkipReason != nil {
+		s.SkipReason = SkipReason(*m.SkipReason)
+	}
+	if m.FailureKind != nil {
+		s.FailureKind = FailureKind(*m.FailureKind)
 	}
 
 	// Convert JSONB fields
@@ -128,6 +128,7 @@ func TestWorkflowInstanceToModel(t *testing.T) {
 		TraceID:       "trace123",
 		CorrelationID: "corr123",
 		BusinessID:    "biz123",
+		ParentInstID:  "parent123",
 		Steps: []*StepInstance{
 			{ID: "step1", StepID: "s1", WorkflowInstID: "test-workflow", Status: StepStatusCompleted},
 		},
@@ -171,6 +172,9 @@ func TestWorkflowInstanceToModel(t *testing.T) {
 	if model.BusinessID != "biz123" {
 		t.Errorf("workflowInstanceToModel() BusinessID = %v, want %v", model.BusinessID, "biz123")
 	}
+	if model.ParentInstID == nil || *model.ParentInstID != "parent123" {
+		t.Errorf("workflowInstanceToModel() ParentInstID = %v, want %v", model.ParentInstID, "parent123")
+	}
 
 	// Test JSONB fields
 	if model.Input == nil {
@@ -294,6 +298,10 @@ func TestStepInstanceToModel(t *testing.T) {
 		LastRetryAt:    &now,
 		DurationMs:     1000,
 		ExecutionOrder: 1,
+		Priority:       5,
+		SkipReason:     SkipReasonOptionalFailure,
+		ReadyAt:        &now,
+		WaitMs:         250,
 	}
 
 	model := stepInstanceToModel(step)
@@ -331,6 +339,15 @@ func TestStepInstanceToModel(t *testing.T) {
 	if model.ExecutionOrder != 1 {
 		t.Errorf("stepInstanceToModel() ExecutionOrder = %v, want %v", model.ExecutionOrder, 1)
 	}
+	if model.Priority != 5 {
+		t.Errorf("stepInstanceToModel() Priority = %v, want %v", model.Priority, 5)
+	}
+	if model.SkipReason == nil || *model.SkipReason != "optional_failure" {
+		t.Errorf("stepInstanceToModel() SkipReason = %v, want %v", model.SkipReason, "optional_failure")
+	}
+	if model.ReadyAt == nil || *model.ReadyAt != now || model.WaitMs != 250 {
+		t.Errorf("stepInstanceToModel() ReadyAt/WaitMs = %v/%v, want %v/%v", model.ReadyAt, model.WaitMs, now, 250)
+	}
 }
 
 func TestModelToStepInstance(t *testing.T) {
@@ -352,6 +369,7 @@ func TestModelToStepInstance(t *testing.T) {
 		LastRetryAt:    &now,
 		DurationMs:     1000,
 		ExecutionOrder: 1,
+		SkipReason:     stringPtr("optional_failure"),
 	}
 
 	step, err := modelToStepInstance(model)
@@ -393,6 +411,9 @@ func TestModelToStepInstance(t *testing.T) {
 	if step.ExecutionOrder != 1 {
 		t.Errorf("modelToStepInstance() ExecutionOrder = %v, want %v", step.ExecutionOrder, 1)
 	}
+	if step.SkipReason != SkipReasonOptionalFailure {
+		t.Errorf("modelToStepInstance() SkipReason = %v, want %v", step.SkipReason, SkipReasonOptionalFailure)
+	}
 
 	// Test JSONB fields
 	if step.Input["key"] != "value" {
@@ -0,0 +1,155 @@
+package orchwf
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures an Orchestrator
+type Option func(*Orchestrator)
+
+// Logger receives diagnostic messages from the orchestrator.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// Metrics receives counters and timings from the orchestrator
+type Metrics interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveDuration(name string, duration time.Duration, labels map[string]string)
+}
+
+// Clock provides the current time and the retry backoff, start limit and backpressure waits.
+// Tests can inject a fake clock to observe backoff intervals without sleeping.
+// A clock that also has SleepContext(ctx, d) error lets waits end when their context does.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// contextSleeper is implemented by clocks whose waits can be cut short by a context.
+// Waits on other clocks are checked against the context only before and after.
+type contextSleeper interface {
+	SleepContext(ctx context.Context, d time.Duration) error
+}
+
+// WithAsyncWorkers sets the number of goroutines used for async execution
+func WithAsyncWorkers(workers int) Option {
+	return func(o *Orchestrator) {
+		o.asyncWorkers = workers
+	}
+}
+
+// WithLogger sets the logger used for diagnostic messages
+func WithLogger(logger Logger) Option {
+	return func(o *Orchestrator) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithMetrics sets the metrics sink used for counters and timings
+func WithMetrics(metrics Metrics) Option {
+	return func(o *Orchestrator) {
+		if metrics != nil {
+			o.metrics = metrics
+		}
+	}
+}
+
+// WithLimits caps the number of steps per workflow definition and the number of
+// registered workflows. RegisterWorkflow rejects definitions beyond either limit.
+// A zero value leaves that limit disabled.
+func WithLimits(maxSteps, maxWorkflows int) Option {
+	return func(o *Orchestrator) {
+		o.maxSteps = maxSteps
+		o.maxWorkflows = maxWorkflows
+	}
+}
+
+// WithStepSandbox runs each executor in its own goroutine and returns as soon as
+// the step context is done, even if the executor is blocked on a channel or lock
+// and ignores cancellation. The abandoned goroutine keeps running until the
+// executor returns; it is leaked rather than waited on so the workflow can fail
+// or retry. Abandoned goroutines are counted by the "step.abandoned" metric and
+// AbandonedSteps. Steps with their own WithTimeout always run this way.
+func WithStepSandbox(enabled bool) Option {
+	return func(o *Orchestrator) {
+		o.sandbox = enabled
+	}
+}
+
+// WithDefaultExecutorTimeout bounds every executor attempt that would otherwise run
+// without a deadline: it applies only when the step has no timeout and the workflow's
+// context has no deadline, so explicit timeouts always take precedence. A zero
+// value disables it. Recommended for shared deployments.
+func WithDefaultExecutorTimeout(timeout time.Duration) Option {
+	return func(o *Orchestrator) {
+		o.defaultTimeout = timeout
+	}
+}
+
+// WithMinChildBudget sets how much time must remain before the parent's deadline for a
+// child workflow to start. A child started with less fails at once with
+// ErrInsufficientBudget instead of being killed mid-step. A zero value only rejects
+// children whose parent deadline has already passed.
+func WithMinChildBudget(budget time.Duration) Option {
+	return func(o *Orchestrator) {
+		o.minChildBudget = budget
+	}
+}
+
+// WithClock sets the clock used for retry backoff
+func WithClock(clock Clock) Option {
+	return func(o *Orchestrator) {
+		if clock != nil {
+			o.clock = clock
+		}
+	}
+}
+
+// realClock uses the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// SleepContext waits for d or until ctx is done, returning ctx's error in the latter case
+func (realClock) SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sleep waits on the orchestrator's clock for d, returning early with ctx's error
+// once ctx is done if the clock allows it
+func (o *Orchestrator) sleep(ctx context.Context, d time.Duration) error {
+	if sleeper, ok := o.clock.(contextSleeper); ok {
+		return sleeper.SleepContext(ctx, d)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	o.clock.Sleep(d)
+	return ctx.Err()
+}
+
+// noopLogger discards all messages
+type noopLogger struct{}
+
+func (noopLogger) Printf(format string, args ...interface{}) {}
+
+// noopMetrics discards all measurements
+type noopMetrics struct{}
+
+func (noopMetrics) IncCounter(name string, labels map[string]string) {}
+
+func (noopMetrics) ObserveDuration(name string, duration time.Duration, labels map[string]string) {}
@@ -2,9 +2,13 @@ package orchwf
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,41 +19,133 @@ type Orchestrator struct {
 	stateManager StateManager
 	workflows    map[string]*WorkflowDefinition
 	mu           sync.RWMutex
-	asyncWorkers int // Number of goroutines for async execution
+	outputMu     sync.Mutex // Guards workflow instance context/output merges
+	asyncWorkers int        // Number of goroutines for async execution
+	logger       Logger
+	metrics      Metrics
+	clock        Clock
+	transport    ExecutorTransport
+	maxSteps     int // Maximum steps per registered workflow (0 = unlimited)
+	maxWorkflows int // Maximum number of registered workflows (0 = unlimited)
+
+	resources map[string]*resourcePool // Shared resource pools by name
+	sandbox   bool                     // Run executors in a goroutine that can be abandoned on timeout
+	abandoned atomic.Int64             // Sandboxed executor goroutines still running after their step gave up
+	capture   CaptureSink              // Receives a trace of every run when set
+	blobStore BlobStore                // Stores step attachment content
+
+	defaultTimeout time.Duration // Executor timeout when neither the step nor the caller sets one (0 = off)
+	minChildBudget time.Duration // Time a child workflow needs left before its parent's deadline to start
+	maxInputBytes  int           // Largest serialized step input an executor is given (0 = unlimited)
+
+	lifecycleMu sync.Mutex     // Guards closed and additions to background
+	closed      bool           // Set by Shutdown; no new background work is accepted
+	background  sync.WaitGroup // Async workflows and timer tickers still running
+	shutdown    chan struct{}  // Closed by Shutdown to stop timer tickers
+	running     sync.Map       // IDs of instances run by background work, reported by Shutdown
+	runs        sync.Map       // Instances executing in this process, by ID, for CancelWorkflow
+	gates       sync.Map       // Gate each waiting step instance, or held-back workflow instance, is at, by ID
+	watchers    eventWatchers  // Callers of WaitForStep, woken by each event of their instance
+
+	concurrency       keyedMutex        // Locks held per workflow concurrency key
+	concurrencyPolicy ConcurrencyPolicy // Queue or reject instances whose key is held
+
+	startLimits      map[string]*startLimiter // Token buckets of new instances by workflow ID, set by options only
+	startLimitPolicy StartLimitPolicy         // Reject or wait for starts beyond a start limit
+
+	persistThreshold int          // Consecutive failed step writes that pause workflows (0 = off)
+	persistFailures  atomic.Int64 // Current run of consecutive failed step writes
+	paused           sync.Map     // IDs of instances paused by the breaker, whose status may not be saved
+
+	stepLimits map[string]*resourcePool // Per-definition caps on concurrent steps, guarded by mu
+	inFlight   *resourcePool            // Caps concurrently executing instances (nil = unlimited)
+
+	adaptive *adaptiveRetry // Fits attempt caps to recent success rates when set
+
+	inputProvenance bool // Record the source of each step input key
+
+	panicPolicy PanicPolicy // What a panicking executor does to its step (empty = PanicRecoverAsError)
 }
 
-// NewOrchestrator creates a new workflow orchestrator
-func NewOrchestrator(stateManager StateManager) *Orchestrator {
-	return &Orchestrator{
+// NewOrchestrator creates a new workflow orchestrator configured with the given options
+func NewOrchestrator(stateManager StateManager, opts ...Option) *Orchestrator {
+	o := &Orchestrator{
 		stateManager: stateManager,
 		workflows:    make(map[string]*WorkflowDefinition),
 		asyncWorkers: 10, // Default number of async workers
+		logger:       noopLogger{},
+		metrics:      noopMetrics{},
+		clock:        realClock{},
+		transport:    LocalTransport{},
+		shutdown:     make(chan struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
 }
 
 // NewOrchestratorWithAsyncWorkers creates a new workflow orchestrator with custom async worker count
 func NewOrchestratorWithAsyncWorkers(stateManager StateManager, asyncWorkers int) *Orchestrator {
-	return &Orchestrator{
-		stateManager: stateManager,
-		workflows:    make(map[string]*WorkflowDefinition),
-		asyncWorkers: asyncWorkers,
-	}
+	return NewOrchestrator(stateManager, WithAsyncWorkers(asyncWorkers))
 }
 
 // RegisterWorkflow registers a workflow definition
 func (o *Orchestrator) RegisterWorkflow(workflow *WorkflowDefinition) error {
+	if err := o.validateWorkflow(workflow); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.checkRegistrableLocked(workflow, 0); err != nil {
+		return err
+	}
+	o.addWorkflowLocked(workflow)
+	return nil
+}
+
+// validateWorkflow checks a definition on its own, before it is registered
+func (o *Orchestrator) validateWorkflow(workflow *WorkflowDefinition) error {
 	if workflow == nil {
 		return fmt.Errorf("workflow cannot be nil")
 	}
 	if workflow.ID == "" {
 		return fmt.Errorf("workflow ID cannot be empty")
 	}
+	if o.maxSteps > 0 && len(workflow.Steps) > o.maxSteps {
+		return fmt.Errorf("%w: workflow %s has %d steps, maximum is %d", ErrLimitExceeded, workflow.ID, len(workflow.Steps), o.maxSteps)
+	}
+	if err := o.validateResources(workflow); err != nil {
+		return err
+	}
+	return o.validateExecutors(workflow)
+}
 
-	o.mu.Lock()
-	defer o.mu.Unlock()
+// checkRegistrableLocked checks a definition against the registered ones, counting
+// pending definitions about to be registered with it. The caller must hold o.mu.
+func (o *Orchestrator) checkRegistrableLocked(workflow *WorkflowDefinition, pending int) error {
+	if _, exists := o.workflows[workflow.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrWorkflowAlreadyExists, workflow.ID)
+	}
+	if o.maxWorkflows > 0 && len(o.workflows)+pending >= o.maxWorkflows {
+		return fmt.Errorf("%w: cannot register workflow %s, maximum of %d workflows already registered", ErrLimitExceeded, workflow.ID, o.maxWorkflows)
+	}
+	return nil
+}
 
+// addWorkflowLocked registers a checked definition. The caller must hold o.mu.
+func (o *Orchestrator) addWorkflowLocked(workflow *WorkflowDefinition) {
 	o.workflows[workflow.ID] = workflow
-	return nil
+	if workflow.MaxConcurrentSteps > 0 {
+		if o.stepLimits == nil {
+			o.stepLimits = make(map[string]*resourcePool)
+		}
+		o.stepLimits[workflow.ID] = newResourcePool("workflow "+workflow.ID, workflow.MaxConcurrentSteps)
+	}
 }
 
 // GetWorkflow retrieves a registered workflow definition
@@ -59,12 +155,27 @@ func (o *Orchestrator) GetWorkflow(workflowID string) (*WorkflowDefinition, erro
 
 	workflow, ok := o.workflows[workflowID]
 	if !ok {
-		return nil, fmt.Errorf("workflow not found: %s", workflowID)
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
 	}
 
 	return workflow, nil
 }
 
+// WorkflowCount returns the number of registered workflow definitions
+func (o *Orchestrator) WorkflowCount() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.workflows)
+}
+
+// HealthCheck verifies the state manager is reachable, for use as a readiness probe
+func (o *Orchestrator) HealthCheck(ctx context.Context) error {
+	if err := o.stateManager.Ping(ctx); err != nil {
+		return fmt.Errorf("state manager unreachable (%d workflows registered): %w", o.WorkflowCount(), err)
+	}
+	return nil
+}
+
 // StartWorkflow starts a new workflow instance (synchronous execution)
 func (o *Orchestrator) StartWorkflow(ctx context.Context, workflowID string, input map[string]interface{}, metadata map[string]interface{}) (*WorkflowResult, error) {
 	// Get workflow definition
@@ -73,31 +184,31 @@ func (o *Orchestrator) StartWorkflow(ctx context.Context, workflowID string, inp
 		return nil, err
 	}
 
-	// Create workflow instance
-	instance := &WorkflowInstance{
-		ID:            uuid.New().String(),
-		WorkflowID:    workflowID,
-		Status:        WorkflowStatusPending,
-		Input:         input,
-		Output:        make(map[string]interface{}),
-		Context:       make(map[string]interface{}),
-		StartedAt:     time.Now(),
-		Metadata:      metadata,
-		TraceID:       getTraceID(ctx, metadata),
-		CorrelationID: getCorrelationID(ctx, metadata),
-		BusinessID:    getBusinessID(ctx, metadata),
-		Steps:         make([]*StepInstance, 0),
+	parentInstID := getParentInstID(ctx, metadata)
+	if err := o.checkChildBudget(ctx, workflowID, parentInstID); err != nil {
+		return nil, err
 	}
 
-	// Save initial state
-	if err := o.stateManager.SaveWorkflow(ctx, instance); err != nil {
-		return nil, fmt.Errorf("failed to save workflow: %w", err)
+	if err := o.waitStartLimit(ctx, workflowID); err != nil {
+		return nil, err
 	}
 
-	// Emit workflow started event
-	o.emitEvent(ctx, instance.ID, nil, "workflow.started", map[string]interface{}{
-		"workflow_id": workflowID,
-	})
+	release, err := o.lockConcurrencyKey(ctx, workflowID, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	freeSlot, err := o.acquireSlot(ctx, workflow, parentInstID)
+	if err != nil {
+		return nil, err
+	}
+	defer freeSlot()
+
+	instance, err := o.createWorkflowInstance(ctx, workflow, input, metadata)
+	if err != nil {
+		return nil, err
+	}
 
 	// Execute workflow synchronously
 	return o.executeWorkflow(ctx, workflow, instance)
@@ -111,12 +222,88 @@ func (o *Orchestrator) StartWorkflowAsync(ctx context.Context, workflowID string
 		return "", err
 	}
 
-	// Create workflow instance
+	// Refuse before anything is saved, so a start after Shutdown leaves no instance behind
+	if o.isShutdown() {
+		return "", ErrShutdown
+	}
+
+	if err := o.waitStartLimit(ctx, workflowID); err != nil {
+		return "", err
+	}
+
+	// A rejected key fails the call; a queued one waits in the background
+	var release func()
+	if o.concurrencyPolicy == ConcurrencyPolicyReject {
+		if release, err = o.lockConcurrencyKey(ctx, workflowID, metadata); err != nil {
+			return "", err
+		}
+	}
+
+	instance, err := o.createWorkflowInstance(ctx, workflow, input, metadata)
+	if err != nil {
+		if release != nil {
+			release()
+		}
+		return "", err
+	}
+
+	// Register the run before it waits, so CancelWorkflow ends the wait and no cancel
+	// slips in between the wait and the steps
+	run := o.startRun(instance.ID)
+
+	// Start async execution in a goroutine
+	if err := o.goBackground(func() {
+		defer o.finishRun(instance.ID, run)
+
+		asyncCtx := context.Background()
+		waitCtx := run.bind(asyncCtx)
+		if release == nil {
+			var lockErr error
+			if release, lockErr = o.lockConcurrencyKey(waitCtx, workflowID, metadata); lockErr != nil {
+				o.settleQueuedWait(asyncCtx, waitCtx, workflow, instance, run, lockErr)
+				return
+			}
+		}
+		defer release()
+
+		defer o.trackRunning(instance.ID)()
+
+		// Beyond WithMaxInFlight, the instance stays queued until a slot frees
+		freeSlot, slotErr := o.acquireQueuedSlot(waitCtx, workflow, instance)
+		if slotErr != nil {
+			o.settleQueuedWait(asyncCtx, waitCtx, workflow, instance, run, slotErr)
+			return
+		}
+		defer freeSlot()
+
+		// Another orchestrator may have settled the instance while it waited for its key or slot
+		if o.settledWhileWaiting(asyncCtx, instance) {
+			return
+		}
+		o.executeRun(asyncCtx, workflow, instance, run)
+	}); err != nil {
+		o.finishRun(instance.ID, run)
+		if release != nil {
+			release()
+		}
+		// Shutdown began after the instance was saved; fail it so nothing resumes it later
+		if saveErr := o.stateManager.UpdateWorkflowError(ctx, instance.ID, err); saveErr != nil {
+			o.logger.Printf("orchwf: failed to fail workflow %s refused at shutdown: %v", instance.ID, saveErr)
+		}
+		return "", err
+	}
+
+	return instance.ID, nil
+}
+
+// createWorkflowInstance creates and saves a new workflow instance and emits its start events
+func (o *Orchestrator) createWorkflowInstance(ctx context.Context, workflow *WorkflowDefinition, input map[string]interface{}, metadata map[string]interface{}) (*WorkflowInstance, error) {
+	workflowID := workflow.ID
 	instance := &WorkflowInstance{
 		ID:            uuid.New().String(),
 		WorkflowID:    workflowID,
 		Status:        WorkflowStatusPending,
-		Input:         input,
+		Input:         applyInputDefaults(workflow.InputDefaults, input),
 		Output:        make(map[string]interface{}),
 		Context:       make(map[string]interface{}),
 		StartedAt:     time.Now(),
@@ -124,30 +311,75 @@ func (o *Orchestrator) StartWorkflowAsync(ctx context.Context, workflowID string
 		TraceID:       getTraceID(ctx, metadata),
 		CorrelationID: getCorrelationID(ctx, metadata),
 		BusinessID:    getBusinessID(ctx, metadata),
+		ParentInstID:  getParentInstID(ctx, metadata),
 		Steps:         make([]*StepInstance, 0),
 	}
 
 	// Save initial state
-	if err := o.stateManager.SaveWorkflow(ctx, instance); err != nil {
-		return "", fmt.Errorf("failed to save workflow: %w", err)
+	if err := o.stateManager.SaveWorkflow(ctx, redactInstance(instance, workflow.RedactedKeys)); err != nil {
+		return nil, fmt.Errorf("failed to save workflow: %w", err)
 	}
 
 	// Emit workflow started event
-	o.emitEvent(ctx, instance.ID, nil, "workflow.started", map[string]interface{}{
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowStarted, map[string]interface{}{
 		"workflow_id": workflowID,
 	})
 
-	// Start async execution in a goroutine
-	go func() {
-		asyncCtx := context.Background()
-		o.executeWorkflow(asyncCtx, workflow, instance)
-	}()
+	// Record the child on the parent's event log
+	if instance.ParentInstID != "" {
+		o.emitEvent(ctx, instance.ParentInstID, nil, EventWorkflowChildStarted, map[string]interface{}{
+			"child_workflow_inst_id": instance.ID,
+			"child_workflow_id":      workflowID,
+		})
+	}
 
-	return instance.ID, nil
+	return instance, nil
+}
+
+// StartChildWorkflow starts a workflow synchronously as a child of the given parent instance.
+// Pass the parent step's context: the child then runs within the parent's remaining
+// deadline, and fails with ErrInsufficientBudget if too little of it is left to start.
+func (o *Orchestrator) StartChildWorkflow(ctx context.Context, parentInstID, workflowID string, input map[string]interface{}, metadata map[string]interface{}) (*WorkflowResult, error) {
+	childMetadata := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		childMetadata[k] = v
+	}
+	childMetadata["parent_workflow_inst_id"] = parentInstID
+
+	return o.StartWorkflow(ctx, workflowID, input, childMetadata)
+}
+
+// checkChildBudget rejects a child workflow whose context leaves it less than the
+// minimum budget before the parent's deadline
+func (o *Orchestrator) checkChildBudget(ctx context.Context, workflowID, parentInstID string) error {
+	if parentInstID == "" {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining < o.minChildBudget {
+		return fmt.Errorf("%w for sub-workflow %s: %v left before the parent's deadline, need %v",
+			ErrInsufficientBudget, workflowID, remaining.Round(time.Millisecond), o.minChildBudget)
+	}
+	return nil
+}
+
+// GetChildWorkflows retrieves the workflow instances started by the given parent instance
+func (o *Orchestrator) GetChildWorkflows(ctx context.Context, parentInstID string) ([]*WorkflowInstance, error) {
+	return o.stateManager.GetChildWorkflows(ctx, parentInstID)
 }
 
-// ResumeWorkflow resumes a workflow from a saved state
-func (o *Orchestrator) ResumeWorkflow(ctx context.Context, workflowInstID string) (*WorkflowResult, error) {
+// ResumeWorkflow resumes a workflow from a saved state. By default steps interrupted
+// mid-retry continue their retry budget; pass WithResumeRetries(FreshRetries) to reset it.
+// Steps that already failed are left as they are; re-run them with RedriveFailedSteps.
+func (o *Orchestrator) ResumeWorkflow(ctx context.Context, workflowInstID string, opts ...ResumeOption) (*WorkflowResult, error) {
+	cfg := resumeConfig{retries: ContinueRetries}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	// Load workflow instance
 	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
 	if err != nil {
@@ -162,14 +394,38 @@ func (o *Orchestrator) ResumeWorkflow(ctx context.Context, workflowInstID string
 
 	// Check if workflow can be resumed
 	if instance.IsCompleted() {
+		snapshot := instance.Clone()
 		return &WorkflowResult{
 			Success:      instance.Status == WorkflowStatusCompleted,
-			WorkflowInst: instance,
-			Output:       instance.Output,
+			WorkflowInst: snapshot,
+			Output:       snapshot.Output,
 			Duration:     time.Since(instance.StartedAt),
 		}, nil
 	}
 
+	release, err := o.lockConcurrencyKey(ctx, instance.WorkflowID, instance.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	freeSlot, err := o.acquireSlot(ctx, workflow, instance.ParentInstID)
+	if err != nil {
+		return nil, err
+	}
+	defer freeSlot()
+
+	if cfg.retries == FreshRetries {
+		if err := o.resetStepRetries(ctx, instance); err != nil {
+			return nil, err
+		}
+	}
+
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowResumed, map[string]interface{}{
+		"status":  string(instance.Status),
+		"retries": string(cfg.retries),
+	})
+
 	// Resume execution
 	return o.executeWorkflow(ctx, workflow, instance)
 }
@@ -179,15 +435,67 @@ func (o *Orchestrator) GetWorkflowStatus(ctx context.Context, workflowInstID str
 	return o.stateManager.GetWorkflow(ctx, workflowInstID)
 }
 
-// ListWorkflows lists workflows with optional filters
+// UpdateWorkflowMetadata merges metadata into a workflow instance's stored metadata,
+// for example an external reference learned partway through the run
+func (o *Orchestrator) UpdateWorkflowMetadata(ctx context.Context, workflowInstID string, metadata map[string]interface{}) error {
+	return o.stateManager.UpdateWorkflowMetadata(ctx, workflowInstID, metadata)
+}
+
+// GetWorkflowSteps retrieves the step instances of a workflow in execution order
+func (o *Orchestrator) GetWorkflowSteps(ctx context.Context, workflowInstID string) ([]*StepInstance, error) {
+	return o.stateManager.GetWorkflowSteps(ctx, workflowInstID)
+}
+
+// ListWorkflows lists workflows with optional filters. The total is -1 when ctx
+// comes from WithoutTotal.
 func (o *Orchestrator) ListWorkflows(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*WorkflowInstance, int64, error) {
 	return o.stateManager.ListWorkflows(ctx, filters, limit, offset)
 }
 
+// CountWorkflowsByStatus counts workflows matching filters, grouped by status.
+// Filters have the same semantics as ListWorkflows.
+func (o *Orchestrator) CountWorkflowsByStatus(ctx context.Context, filters map[string]interface{}) (map[WorkflowStatus]int64, error) {
+	return o.stateManager.CountWorkflowsByStatus(ctx, filters)
+}
+
+// CleanupEvents deletes workflow events older than retention and returns how many were
+// deleted. Events grow much faster than instances, so they can be pruned on a shorter
+// retention without touching the instances they belong to.
+func (o *Orchestrator) CleanupEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := o.stateManager.DeleteEventsOlderThan(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events: %w", err)
+	}
+	return deleted, nil
+}
+
 // executeWorkflow executes a workflow instance
 func (o *Orchestrator) executeWorkflow(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) (*WorkflowResult, error) {
+	run := o.startRun(instance.ID)
+	defer o.finishRun(instance.ID, run)
+	return o.executeRun(ctx, workflow, instance, run)
+}
+
+// executeRun executes a workflow instance whose run is already registered, such as an
+// async instance that was registered while it waited to start
+func (o *Orchestrator) executeRun(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, run *activeRun) (*WorkflowResult, error) {
 	startTime := time.Now()
 
+	ctx, recorder := o.startCapture(ctx)
+	defer o.finishCapture(ctx, recorder, instance)
+
+	// Let steps enrich the workflow metadata through the context
+	ctx = withWorkflowRun(ctx, o, instance)
+
+	// Let CancelWorkflow stop the steps of this run
+	stepsCtx := run.bind(ctx)
+
+	// A run cancelled before it began is settled without running anything
+	if cancelRequested(stepsCtx) {
+		run.result = o.settleCancelled(ctx, workflow, instance, startTime)
+		return run.result, run.result.Error
+	}
+
 	// Update status to running
 	instance.Status = WorkflowStatusRunning
 	if err := o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusRunning); err != nil {
@@ -205,20 +513,57 @@ func (o *Orchestrator) executeWorkflow(ctx context.Context, workflow *WorkflowDe
 				Input:          make(map[string]interface{}),
 				Output:         make(map[string]interface{}),
 				ExecutionOrder: i,
+				Priority:       stepDef.Priority,
 			}
 			instance.Steps = append(instance.Steps, stepInst)
+		}
 
-			if err := o.stateManager.SaveStep(ctx, stepInst); err != nil {
-				return nil, fmt.Errorf("failed to save step: %w", err)
-			}
+		if err := o.stateManager.SaveSteps(ctx, redactSteps(instance.Steps, workflow.RedactedKeys)); err != nil {
+			return nil, fmt.Errorf("failed to save steps: %w", err)
 		}
 	}
 
-	// Build dependency graph
-	graph := o.buildDependencyGraph(workflow)
+	// Build dependency graph now that the input is known
+	graph, stepsErr := o.buildDependencyGraph(workflow, instance.Input)
+	if stepsErr != nil {
+		o.skipPendingSteps(ctx, instance)
+	} else {
+		// Execute steps based on dependencies
+		stepsErr = o.executeSteps(stepsCtx, workflow, instance, graph)
+	}
+	// Guard against steps of a strictly ordered workflow having run in parallel
+	if stepsErr == nil && workflow.StrictOrdering {
+		stepsErr = checkStrictOrdering(instance)
+	}
+	if stepsErr == nil && workflow.FailOnOptionalFailure {
+		stepsErr = checkOptionalFailures(instance)
+	}
+
+	// CancelWorkflow stopped the steps: settle as cancelled rather than failed
+	if stepsErr != nil && cancelRequested(stepsCtx) {
+		run.result = o.settleCancelled(ctx, workflow, instance, startTime)
+		return run.result, run.result.Error
+	}
+
+	// Pause rather than fail when the state manager is down, so the run can resume later
+	if errors.Is(stepsErr, ErrStateManagerDown) {
+		return o.pauseWorkflow(ctx, instance, stepsErr, startTime), stepsErr
+	}
+
+	// Roll back the completed steps before the finalizer sees the outcome
+	var compensation *CompensationResult
+	if stepsErr != nil {
+		o.flushCompletedSteps(ctx, workflow, instance)
+		compensation = o.compensate(ctx, workflow, instance)
+	}
+
+	// Run the finalizer once the steps are done, unless the workflow is paused on a timer
+	var finalizerErr error
+	if stepsErr != nil || len(o.waitingSteps(instance)) == 0 {
+		finalizerErr = o.runFinalizer(ctx, workflow, instance, stepsErr)
+	}
 
-	// Execute steps based on dependencies
-	if err := o.executeSteps(ctx, workflow, instance, graph); err != nil {
+	if err := stepsErr; err != nil {
 		// Mark workflow as failed
 		instance.Status = WorkflowStatusFailed
 		instance.Error = stringPtr(err.Error())
@@ -228,16 +573,50 @@ func (o *Orchestrator) executeWorkflow(ctx context.Context, workflow *WorkflowDe
 		o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusFailed)
 		o.stateManager.UpdateWorkflowError(ctx, instance.ID, err)
 
-		o.emitEvent(ctx, instance.ID, nil, "workflow.failed", map[string]interface{}{
+		o.emitEvent(ctx, instance.ID, nil, EventWorkflowFailed, map[string]interface{}{
 			"error": err.Error(),
 		})
+		o.metrics.ObserveDuration("workflow.duration", time.Since(startTime), map[string]string{
+			"workflow_id": workflow.ID,
+			"status":      string(WorkflowStatusFailed),
+		})
+
+		return &WorkflowResult{
+			Success:        false,
+			WorkflowInst:   instance.Clone(),
+			Error:          err,
+			FinalizerError: finalizerErr,
+			Compensation:   compensation,
+			Duration:       time.Since(startTime),
+		}, err
+	}
+
+	// Pause the workflow if a timer step is still waiting
+	if waitingSteps := o.waitingSteps(instance); len(waitingSteps) > 0 {
+		instance.Status = WorkflowStatusWaiting
+		o.flushCompletedSteps(ctx, workflow, instance)
+		if err := o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusWaiting); err != nil {
+			return nil, fmt.Errorf("failed to update workflow status: %w", err)
+		}
+
+		o.emitEvent(ctx, instance.ID, nil, EventWorkflowWaiting, map[string]interface{}{
+			"waiting_steps": waitingSteps,
+		})
 
+		snapshot := instance.Clone()
 		return &WorkflowResult{
 			Success:      false,
-			WorkflowInst: instance,
-			Error:        err,
+			WorkflowInst: snapshot,
+			Output:       snapshot.Output,
 			Duration:     time.Since(startTime),
-		}, err
+		}, nil
+	}
+
+	// Replace the merged step outputs with the workflow's curated output
+	if workflow.OutputMapping != nil {
+		o.mapWorkflowOutput(ctx, workflow, instance)
+	} else {
+		o.flushWorkflowOutput(ctx, workflow, instance)
 	}
 
 	// Mark workflow as completed
@@ -249,21 +628,92 @@ func (o *Orchestrator) executeWorkflow(ctx context.Context, workflow *WorkflowDe
 		return nil, fmt.Errorf("failed to update workflow status: %w", err)
 	}
 
-	o.emitEvent(ctx, instance.ID, nil, "workflow.completed", map[string]interface{}{
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowCompleted, map[string]interface{}{
 		"duration_ms": time.Since(startTime).Milliseconds(),
 	})
+	o.metrics.ObserveDuration("workflow.duration", time.Since(startTime), map[string]string{
+		"workflow_id": workflow.ID,
+		"status":      string(WorkflowStatusCompleted),
+	})
 
+	snapshot := instance.Clone()
 	return &WorkflowResult{
-		Success:      true,
-		WorkflowInst: instance,
-		Output:       instance.Output,
-		Duration:     time.Since(startTime),
+		Success:        true,
+		WorkflowInst:   snapshot,
+		Output:         snapshot.Output,
+		FinalizerError: finalizerErr,
+		Duration:       time.Since(startTime),
 	}, nil
 }
 
+// runFinalizer executes the workflow finalizer, if any, after the steps have completed or failed.
+// Its error is logged and returned separately so it never masks the workflow's own failure.
+func (o *Orchestrator) runFinalizer(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, workflowErr error) error {
+	finalizer := workflow.Finalizer
+	if finalizer == nil {
+		return nil
+	}
+
+	stepInstMap := make(map[string]*StepInstance)
+	for _, stepInst := range instance.Steps {
+		stepInstMap[stepInst.StepID] = stepInst
+	}
+
+	stepInst, err := o.ensureStepInstance(ctx, instance, stepInstMap, finalizer)
+	if err != nil {
+		o.logger.Printf("orchwf: failed to save finalizer %s for workflow %s: %v", finalizer.ID, instance.ID, err)
+		return fmt.Errorf("failed to save finalizer step: %w", err)
+	}
+
+	// Finalizers run even if the caller's context was cancelled, like a defer
+	finalCtx := context.WithoutCancel(ctx)
+	if finalizer.AdvancedExecutor != nil {
+		finalCtx = withCompletedOutputs(finalCtx, stepInstMap)
+	}
+	if workflowErr != nil {
+		finalCtx = withWorkflowError(finalCtx, workflowErr)
+	}
+
+	if err := o.executeStep(finalCtx, finalizer, stepInst, instance, stepInstMap); err != nil {
+		o.logger.Printf("orchwf: finalizer %s failed for workflow %s: %v", finalizer.ID, instance.ID, err)
+		o.emitEvent(finalCtx, instance.ID, &stepInst.ID, EventFinalizerFailed, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ensureStepInstance returns the instance of a step that is not part of the dependency
+// graph, such as a finalizer, creating and saving it on first use
+func (o *Orchestrator) ensureStepInstance(ctx context.Context, instance *WorkflowInstance, stepInstMap map[string]*StepInstance, stepDef *StepDefinition) (*StepInstance, error) {
+	if stepInst, ok := stepInstMap[stepDef.ID]; ok {
+		return stepInst, nil
+	}
+
+	stepInst := &StepInstance{
+		ID:             uuid.New().String(),
+		StepID:         stepDef.ID,
+		WorkflowInstID: instance.ID,
+		Status:         StepStatusPending,
+		Input:          make(map[string]interface{}),
+		Output:         make(map[string]interface{}),
+		ExecutionOrder: len(instance.Steps),
+		Priority:       stepDef.Priority,
+	}
+	if err := o.stateManager.SaveStep(ctx, stepInst); err != nil {
+		return nil, err
+	}
+	instance.Steps = append(instance.Steps, stepInst)
+	stepInstMap[stepDef.ID] = stepInst
+	return stepInst, nil
+}
+
 // executeSteps executes workflow steps based on dependency graph
 func (o *Orchestrator) executeSteps(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance, graph map[string][]string) error {
 	executed := make(map[string]bool)
+	waiting := make(map[string]bool)
 	stepDefMap := make(map[string]*StepDefinition)
 	stepInstMap := make(map[string]*StepInstance)
 
@@ -275,92 +725,218 @@ func (o *Orchestrator) executeSteps(ctx context.Context, workflow *WorkflowDefin
 		stepInstMap[stepInst.StepID] = stepInst
 	}
 
+	// The workflow's step limit is shared by all of its instances
+	o.mu.RLock()
+	limit := o.stepLimits[workflow.ID]
+	o.mu.RUnlock()
+
 	// Execute steps in order based on dependencies
 	for {
 		// Find steps that can be executed (all dependencies met)
 		readySteps := o.findReadySteps(workflow, executed, graph)
+
+		// Timer steps that are still waiting hold back their dependents
+		pending := readySteps[:0]
+		for _, stepDef := range readySteps {
+			if !waiting[stepDef.ID] {
+				pending = append(pending, stepDef)
+			}
+		}
+		readySteps = pending
+
 		if len(readySteps) == 0 {
 			break
 		}
 
-		// Sort ready steps by priority (higher priority first)
-		sort.Slice(readySteps, func(i, j int) bool {
-			return readySteps[i].Priority > readySteps[j].Priority
+		// Record when each step became ready so its scheduling wait can be measured
+		readyAt := time.Now()
+		for _, stepDef := range readySteps {
+			if stepInst := stepInstMap[stepDef.ID]; !stepInst.IsCompleted() {
+				stepInst.ReadyAt = &readyAt
+			}
+		}
+
+		// Sort ready steps by priority (higher priority first), preferring the
+		// priority persisted on the step instance so resumed runs keep their order
+		sort.SliceStable(readySteps, func(i, j int) bool {
+			return o.effectivePriority(readySteps[i], stepInstMap) > o.effectivePriority(readySteps[j], stepInstMap)
 		})
 
 		// Separate sync and async steps
 		var syncSteps, asyncSteps []*StepDefinition
 		for _, stepDef := range readySteps {
-			if stepDef.Async {
+			if stepDef.Async && !workflow.StrictOrdering {
 				asyncSteps = append(asyncSteps, stepDef)
 			} else {
 				syncSteps = append(syncSteps, stepDef)
 			}
 		}
 
-		// Execute sync steps sequentially
-		for _, stepDef := range syncSteps {
-			stepInst := stepInstMap[stepDef.ID]
-			if stepInst.Status == StepStatusCompleted {
-				executed[stepDef.ID] = true
-				continue
-			}
+		failFast := workflow.FailurePolicy != FailurePolicyCompleteWave
+		overlap := workflow.WaveStrategy == WaveStrategyOverlap
+		var waveErr error
+		var wg sync.WaitGroup
+		var errMu sync.Mutex // Guards waveErr, which async steps may set while sync steps run
+		waveCtx, cancelWave := context.WithCancel(ctx)
+		if hasAdvancedExecutor(readySteps) {
+			waveCtx = withCompletedOutputs(waveCtx, stepInstMap)
+		}
 
-			if err := o.executeStep(ctx, stepDef, stepInst, instance, stepInstMap); err != nil {
-				if stepDef.Required {
-					return err
-				} else {
-					// Non-required step failed, mark as skipped and continue
-					stepInst.Status = StepStatusSkipped
-					o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusSkipped)
+		// Create alternative member instances up front so async steps never see stepInstMap change
+		for _, stepDef := range syncSteps {
+			for _, member := range stepDef.Alternatives {
+				if _, err := o.ensureStepInstance(ctx, instance, stepInstMap, member); err != nil {
+					cancelWave()
+					return fmt.Errorf("failed to save alternative %s: %w", member.ID, err)
 				}
 			}
-			executed[stepDef.ID] = true
 		}
 
-		// Execute async steps concurrently using goroutines
-		if len(asyncSteps) > 0 {
-			var wg sync.WaitGroup
-			errors := make(chan error, len(asyncSteps))
-
+		// startAsync launches the async steps of the wave; the caller waits on wg
+		startAsync := func() {
 			for _, stepDef := range asyncSteps {
 				stepInst := stepInstMap[stepDef.ID]
 				if stepInst.Status == StepStatusCompleted {
-					executed[stepDef.ID] = true
-					continue
+					continue // Merged with its siblings once the wave is done
 				}
 
 				wg.Add(1)
 				go func(sd *StepDefinition, si *StepInstance) {
 					defer wg.Done()
-					if err := o.executeStep(ctx, sd, si, instance, stepInstMap); err != nil {
-						if sd.Required {
-							errors <- err
+					if err := o.executeLimitedStep(withDeferredMerge(waveCtx, sd.ID), limit, sd, si, instance, stepInstMap); err != nil {
+						errMu.Lock()
+						defer errMu.Unlock()
+
+						if waveErr != nil && failFast && waveCtx.Err() != nil {
+							// Sibling cancelled because another required step failed
+							o.cancelStep(ctx, si, instance)
+						} else if sd.Required || o.panicFailsWorkflow(err) {
+							if waveErr == nil {
+								waveErr = err
+							}
+							if failFast {
+								cancelWave()
+							}
 						} else {
 							// Non-required step failed, mark as skipped and continue
-							si.Status = StepStatusSkipped
-							o.stateManager.UpdateStepStatus(ctx, si.ID, StepStatusSkipped)
+							o.skipOptionalStep(ctx, si, instance, err)
 						}
 					}
-					executed[sd.ID] = true
 				}(stepDef, stepInst)
 			}
+		}
+
+		// With the overlap strategy, async steps run alongside the sync steps of the wave
+		if overlap {
+			startAsync()
+		}
+
+		// Execute sync steps sequentially
+		for _, stepDef := range syncSteps {
+			errMu.Lock()
+			stop := waveErr != nil && failFast
+			errMu.Unlock()
+			if stop {
+				break
+			}
 
-			wg.Wait()
-			close(errors)
+			stepInst := stepInstMap[stepDef.ID]
+			if stepInst.Status == StepStatusCompleted {
+				// Restore output of steps completed before a resume
+				o.mergeStepOutput(instance, stepDef.ID, stepInst.Output)
+				executed[stepDef.ID] = true
+				continue
+			}
 
-			// Check for errors
-			for err := range errors {
-				if err != nil {
-					return err
+			if err := o.executeLimitedStep(waveCtx, limit, stepDef, stepInst, instance, stepInstMap); err != nil {
+				errMu.Lock()
+				cancelled := waveErr != nil && failFast && waveCtx.Err() != nil
+				failsWorkflow := stepDef.Required || o.panicFailsWorkflow(err)
+				if failsWorkflow && waveErr == nil {
+					waveErr = err
+				}
+				errMu.Unlock()
+
+				if cancelled {
+					// Cancelled because an overlapping async step failed
+					o.cancelStep(ctx, stepInst, instance)
+				} else if failsWorkflow {
+					if failFast {
+						cancelWave()
+					}
+				} else {
+					// Non-required step failed, mark as skipped and continue
+					o.skipOptionalStep(ctx, stepInst, instance, err)
 				}
 			}
+			if stepInst.Status == StepStatusWaiting {
+				waiting[stepDef.ID] = true
+				continue
+			}
+			executed[stepDef.ID] = true
 		}
 
-		// Check if all steps are executed
-		if len(executed) == len(workflow.Steps) {
-			break
+		// Otherwise async steps start once the sync steps are done; under fail-fast,
+		// they are not started after a sync failure
+		if !overlap && !(waveErr != nil && failFast) {
+			startAsync()
 		}
+
+		wg.Wait()
+		cancelWave()
+
+		// Async outputs are merged in a stable order, not as the steps happen to finish
+		o.mergeAsyncOutputs(ctx, workflow, instance, asyncSteps, stepInstMap)
+
+		// Record progress once all goroutines are done to avoid concurrent map writes.
+		// Skipped optional steps count as executed so their dependents become ready in the next wave.
+		if overlap || !(waveErr != nil && failFast) {
+			for _, stepDef := range asyncSteps {
+				if stepInstMap[stepDef.ID].Status == StepStatusWaiting {
+					waiting[stepDef.ID] = true
+				} else {
+					executed[stepDef.ID] = true
+				}
+			}
+		}
+
+		// A required step failed: mark the steps that never ran and fail the workflow
+		if waveErr != nil {
+			o.skipPendingSteps(ctx, instance)
+			return waveErr
+		}
+
+		// A success step completed: stop scheduling and skip whatever has not run
+		if stepID, ok := o.reachedSuccessStep(workflow, stepInstMap); ok {
+			o.skipAfterEarlySuccess(ctx, instance, stepID)
+			return nil
+		}
+
+		// Check if all steps are executed
+		if len(executed) == len(workflow.Steps) {
+			break
+		}
+
+		// CancelWorkflow was called: start no further waves
+		if cancelRequested(ctx) {
+			return context.Cause(ctx)
+		}
+
+		// Persistence keeps failing: stop before running more steps whose progress would be lost
+		if o.persistenceUnhealthy() {
+			return fmt.Errorf("%w: %d consecutive state manager writes failed", ErrStateManagerDown, o.persistFailures.Load())
+		}
+
+		// A step asked to slow down: hold the next wave back
+		if err := o.applyBackpressure(ctx, instance); err != nil {
+			o.skipPendingSteps(ctx, instance)
+			return err
+		}
+	}
+
+	// Nothing is ready, yet some steps never ran and are not held back by a timer: they can never run
+	if stuck := unsatisfiableSteps(workflow, graph, executed, waiting); len(stuck) > 0 {
+		return fmt.Errorf("%w: %s can never become ready", ErrWorkflowDeadlock, strings.Join(stuck, "; "))
 	}
 
 	return nil
@@ -373,15 +949,50 @@ func (o *Orchestrator) executeStep(ctx context.Context, stepDef *StepDefinition,
 		return nil
 	}
 
+	// Alternatives groups run their members instead of an executor
+	if len(stepDef.Alternatives) > 0 {
+		return o.executeAlternatives(ctx, stepDef, stepInst, workflowInst, stepInstMap)
+	}
+
 	// Prepare input from previous steps
 	input := o.prepareStepInput(stepDef, stepInst, workflowInst, stepInstMap)
+	saveTransitions := o.savesTransitions(workflowInst.WorkflowID)
+	if o.inputProvenance && saveTransitions {
+		o.notePersistence(o.stateManager.UpdateStepProvenance(ctx, stepInst.ID, stepInst.Provenance))
+	}
 
-	// Apply timeout if specified
-	stepCtx := ctx
-	if stepDef.Timeout > 0 {
-		var cancel context.CancelFunc
-		stepCtx, cancel = context.WithTimeout(ctx, stepDef.Timeout)
-		defer cancel()
+	// Reject inputs that grew too large, such as a fan-in of many big outputs, before running anything
+	if err := o.checkInputSize(stepDef, input); err != nil {
+		o.failStep(ctx, stepDef, stepInst, workflowInst, err, FailureKindValidation)
+		return fmt.Errorf("step %s failed: %w", stepDef.ID, err)
+	}
+
+	// Timer steps wait until their wake time before executing
+	if stepDef.TimerUntil != nil && o.awaitTimer(ctx, stepDef, stepInst, workflowInst, input) {
+		return nil
+	}
+
+	stepCtx := withIdempotencyToken(ctx, stepInst.ID)
+	stepCtx = withStepRun(stepCtx, o, stepInst)
+	stepCtx = o.withInstanceInfo(stepCtx, workflowInst)
+
+	// Apply absolute deadline from input if specified
+	if stepDef.DeadlineKey != "" {
+		deadline, err := deadlineFromInput(input, stepDef.DeadlineKey)
+		kind := FailureKindValidation
+		if err == nil && deadline != nil && !deadline.After(time.Now()) {
+			err = fmt.Errorf("deadline already passed: %s", deadline.Format(time.RFC3339))
+			kind = FailureKindTimeout
+		}
+		if err != nil {
+			o.failStep(ctx, stepDef, stepInst, workflowInst, err, kind)
+			return fmt.Errorf("step %s failed: %w", stepDef.ID, err)
+		}
+		if deadline != nil {
+			var cancel context.CancelFunc
+			stepCtx, cancel = context.WithDeadline(stepCtx, *deadline)
+			defer cancel()
+		}
 	}
 
 	// Execute with retry
@@ -393,90 +1004,673 @@ func (o *Orchestrator) executeStep(ctx context.Context, stepDef *StepDefinition,
 		}
 	}
 
+	// Adaptive retry replaces the attempt cap with one fitted to the step's recent success rate
+	if o.adaptive != nil && stepDef.RetryPolicy != nil {
+		adapted := *retryPolicy
+		adapted.MaxAttempts = o.adaptive.attemptsFor(adaptiveKey(workflowInst.WorkflowID, stepDef.ID))
+		retryPolicy = &adapted
+	}
+
+	// The budget and max duration checks read time as advanced by o.clock, so an injected
+	// clock that skips the backoff waits also brings the step closer to its cutoffs
+	stepNow := o.clockedNow()
+
+	// The step timeout is one budget for all attempts and the backoff between them
+	var budget time.Time
+	if stepDef.Timeout > 0 {
+		budget = time.Now().Add(stepDef.Timeout)
+	}
+
+	// A step interrupted mid-retry continues from the attempt it was on, unless
+	// ResumeWorkflow reset its retry count with FreshRetries
+	start := stepInst.RetryCount
+	if start >= retryPolicy.MaxAttempts {
+		err := fmt.Errorf("step %s has no attempts left: %d of %d used", stepDef.ID, start, retryPolicy.MaxAttempts)
+		o.failStep(ctx, stepDef, stepInst, workflowInst, err, FailureKindExhaustedRetries)
+		return err
+	}
+
 	var lastErr error
-	for attempt := 0; attempt < retryPolicy.MaxAttempts; attempt++ {
+	kind := FailureKindExhaustedRetries
+	attempts := start
+	for attempt := start; attempt < retryPolicy.MaxAttempts; attempt++ {
 		if attempt > 0 {
-			// Wait before retry
+			// Stop retrying once the workflow context or step deadline is done
+			if err := stepCtx.Err(); err != nil {
+				kind = contextFailureKind(err)
+				break
+			}
+
+			// Wait before retry, unless the max duration would run out first
 			interval := o.calculateRetryInterval(retryPolicy, attempt)
-			time.Sleep(interval)
+			if capAt := maxDurationDeadline(stepDef, stepInst); !capAt.IsZero() && !stepNow().Add(interval).Before(capAt) {
+				lastErr = maxDurationError(stepDef, attempt+1, lastErr)
+				kind = FailureKindMaxDuration
+				break
+			}
 
-			stepInst.Status = StepStatusRetrying
+			// The backoff draws from the step budget, so don't wait out a budget that
+			// would leave the next attempt already expired
+			if !budget.IsZero() && !stepNow().Add(interval).Before(budget) {
+				if lastErr == nil {
+					lastErr = context.DeadlineExceeded
+				}
+				lastErr = fmt.Errorf("step %s budget of %v exceeded before attempt %d: %w", stepDef.ID, stepDef.Timeout, attempt+1, lastErr)
+				kind = FailureKindTimeout
+				break
+			}
+			if err := o.sleep(stepCtx, interval); err != nil {
+				kind = contextFailureKind(err)
+				break
+			}
+
+			if err := o.transitionStep(stepInst, StepStatusRetrying); err != nil {
+				return err
+			}
 			stepInst.RetryCount = attempt
-			now := time.Now()
+			now := o.clock.Now()
 			stepInst.LastRetryAt = &now
-			o.stateManager.UpdateStepStatus(stepCtx, stepInst.ID, StepStatusRetrying)
+			if saveTransitions {
+				o.notePersistence(o.stateManager.UpdateStepStatus(stepCtx, stepInst.ID, StepStatusRetrying))
+				o.notePersistence(o.stateManager.UpdateStepRetry(stepCtx, stepInst.ID, attempt, &now))
 
-			o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, "step.retry", map[string]interface{}{
-				"attempt": attempt + 1,
-			})
+				o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, EventStepRetry, map[string]interface{}{
+					"attempt": attempt + 1,
+				})
+			}
 		}
 
 		// Mark step as running
 		if attempt == 0 {
-			stepInst.Status = StepStatusRunning
+			if err := o.transitionStep(stepInst, StepStatusRunning); err != nil {
+				return err
+			}
 			now := time.Now()
 			stepInst.StartedAt = &now
-			o.stateManager.UpdateStepStatus(stepCtx, stepInst.ID, StepStatusRunning)
+			if stepInst.ReadyAt != nil {
+				stepInst.WaitMs = now.Sub(*stepInst.ReadyAt).Milliseconds()
+			}
+			o.setCurrentStep(stepCtx, workflowInst, stepDef.ID)
 
-			o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, "step.started", map[string]interface{}{
-				"step_id": stepDef.ID,
-			})
+			if saveTransitions {
+				o.notePersistence(o.stateManager.UpdateStepStatus(stepCtx, stepInst.ID, StepStatusRunning))
+				if stepInst.ReadyAt != nil {
+					o.notePersistence(o.stateManager.UpdateStepWait(stepCtx, stepInst.ID, *stepInst.ReadyAt, stepInst.WaitMs))
+				}
+
+				o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, EventStepStarted, withTags(map[string]interface{}{
+					"step_id": stepDef.ID,
+				}, stepDef))
+			}
 		}
 
 		// Execute step
-		startTime := time.Now()
-		output, err := stepDef.Executor(stepCtx, input)
-		duration := time.Since(startTime)
+		capAt := maxDurationDeadline(stepDef, stepInst)
+		attemptCtx, cancelAttempt := o.attemptContext(stepCtx, earliest(budget, capAt))
+		output, duration, err := o.runExecutor(attemptCtx, stepDef, stepInst, input)
+		if err == nil {
+			err = checkOutputKeys(stepDef, output)
+		}
+		o.flushReportedProgress(stepCtx)
+		timedOut := err != nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+		cancelAttempt()
+		attempts++
+		if o.adaptive != nil {
+			o.adaptive.record(adaptiveKey(workflowInst.WorkflowID, stepDef.ID), err == nil)
+		}
+
+		if timedOut && saveTransitions {
+			o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, EventStepTimeout, map[string]interface{}{
+				"step_id":     stepDef.ID,
+				"attempt":     attempt + 1,
+				"duration_ms": duration.Milliseconds(),
+			})
+		}
 
 		stepInst.DurationMs = duration.Milliseconds()
 
+		// The output asks for another attempt; the last attempt's output is kept
+		if err == nil && stepDef.RetryableOutput != nil && attempt+1 < retryPolicy.MaxAttempts && stepDef.RetryableOutput(output) {
+			lastErr = fmt.Errorf("step %s output requested a retry", stepDef.ID)
+			continue
+		}
+
 		if err == nil {
 			// Step succeeded
-			stepInst.Status = StepStatusCompleted
-			stepInst.Output = output
-			now := time.Now()
-			stepInst.CompletedAt = &now
+			o.completeStep(ctx, stepDef, stepInst, workflowInst, output, duration)
+			return nil
+		}
 
-			o.stateManager.UpdateStepStatus(stepCtx, stepInst.ID, StepStatusCompleted)
-			o.stateManager.UpdateStepOutput(stepCtx, stepInst.ID, output)
+		lastErr = err
+		kind = FailureKindExhaustedRetries
+		if timedOut {
+			kind = FailureKindTimeout
+		} else if err := stepCtx.Err(); err != nil {
+			kind = contextFailureKind(err)
+		} else if errors.Is(lastErr, ErrStepPanicked) {
+			kind = FailureKindPanic
+		}
 
-			o.emitEvent(stepCtx, workflowInst.ID, &stepInst.ID, "step.completed", map[string]interface{}{
-				"duration_ms": duration.Milliseconds(),
-			})
+		// Under PanicFailWorkflow a panic ends the step without retries
+		if o.panicFailsWorkflow(err) {
+			break
+		}
+
+		// An attempt cut off by the max duration ends the step
+		if timedOut && !capAt.IsZero() && !time.Now().Before(capAt) {
+			lastErr = maxDurationError(stepDef, attempt+1, lastErr)
+			kind = FailureKindMaxDuration
+			break
+		}
+	}
+
+	// All retries exhausted
+	o.failStep(ctx, stepDef, stepInst, workflowInst, lastErr, kind)
+
+	return fmt.Errorf("step %s failed after %d attempts: %w", stepDef.ID, attempts, lastErr)
+}
+
+// checkOutputKeys enforces WithRequiredOutputKeys on a successful attempt's output
+func checkOutputKeys(stepDef *StepDefinition, output map[string]interface{}) error {
+	var missing []string
+	for _, key := range stepDef.RequiredOutputKeys {
+		if output[key] == nil {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: step %s did not set %s", ErrMissingOutputKeys, stepDef.ID, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// clockedNow returns a func reporting the wall time as advanced by o.clock since the
+// call. With the system clock it is time.Now.
+func (o *Orchestrator) clockedNow() func() time.Time {
+	wallStart, clockStart := time.Now(), o.clock.Now()
+	return func() time.Time {
+		return wallStart.Add(o.clock.Now().Sub(clockStart))
+	}
+}
+
+// contextFailureKind classifies a step stopped by its context: a deadline ran out or the
+// workflow was cancelled
+func contextFailureKind(err error) FailureKind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureKindTimeout
+	}
+	return FailureKindCancelled
+}
+
+// attemptContext bounds one executor attempt by the step's budget. Without a budget or
+// a caller deadline, the default executor timeout applies to each attempt.
+func (o *Orchestrator) attemptContext(stepCtx context.Context, budget time.Time) (context.Context, context.CancelFunc) {
+	if !budget.IsZero() {
+		return context.WithDeadline(stepCtx, budget)
+	}
+	if _, hasDeadline := stepCtx.Deadline(); !hasDeadline && o.defaultTimeout > 0 {
+		// Safety net so no executor runs unbounded
+		return context.WithTimeout(stepCtx, o.defaultTimeout)
+	}
+	return stepCtx, func() {}
+}
+
+// maxDurationDeadline returns when the step's max duration runs out, or the zero time
+// if it has none or has not started. StartedAt is persisted, so a step resumed mid-retry
+// keeps its original cap.
+func maxDurationDeadline(stepDef *StepDefinition, stepInst *StepInstance) time.Time {
+	if stepDef.MaxDuration <= 0 || stepInst.StartedAt == nil {
+		return time.Time{}
+	}
+	return stepInst.StartedAt.Add(stepDef.MaxDuration)
+}
+
+// maxDurationError reports that the step ran out of its max duration at the given attempt
+func maxDurationError(stepDef *StepDefinition, attempt int, lastErr error) error {
+	if lastErr == nil {
+		lastErr = context.DeadlineExceeded
+	}
+	return fmt.Errorf("%w of %v at attempt %d: %w", ErrMaxDurationExceeded, stepDef.MaxDuration, attempt, lastErr)
+}
 
-			// Merge output to workflow context
-			o.mergeStepOutput(workflowInst, stepDef.ID, output)
+// earliest returns the earlier of two deadlines, where the zero time means none
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// setCurrentStep records the step the workflow most recently started, so status polls show progress
+func (o *Orchestrator) setCurrentStep(ctx context.Context, workflowInst *WorkflowInstance, stepID string) {
+	o.outputMu.Lock()
+	workflowInst.CurrentStepID = stepID
+	o.outputMu.Unlock()
+
+	if !o.savesTransitions(workflowInst.WorkflowID) {
+		return
+	}
+	o.notePersistence(o.stateManager.UpdateWorkflowCurrentStep(ctx, workflowInst.ID, stepID))
+}
+
+// completeStep marks a step as completed and persists its output along with the workflow output
+func (o *Orchestrator) completeStep(ctx context.Context, stepDef *StepDefinition, stepInst *StepInstance, workflowInst *WorkflowInstance, output map[string]interface{}, duration time.Duration) {
+	if err := o.transitionStep(stepInst, StepStatusCompleted); err != nil {
+		o.logger.Printf("orchwf: %v", err)
+		return
+	}
+	stepInst.Output = output
+	now := time.Now()
+	stepInst.CompletedAt = &now
+
+	// Merge output to workflow context, unless the step's wave merges it later
+	deferred := mergeDeferred(ctx, stepDef.ID)
+	var workflowOutput map[string]interface{}
+	if !deferred {
+		workflowOutput = o.mergeStepOutput(workflowInst, stepDef.ID, output)
+	}
+
+	// Under PersistOnFailure the result stays in memory unless the workflow fails or waits
+	if o.persistPolicy(workflowInst.WorkflowID) == PersistOnFailure {
+		o.observeStepDuration(workflowInst, stepDef, duration)
+		return
+	}
+	o.flushStepProgress(ctx, stepInst, workflowInst)
 
+	// Persist the step result and workflow output together, with sensitive values masked
+	keys := o.redactedKeys(workflowInst.WorkflowID)
+	err := o.stateManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := o.stateManager.UpdateStepStatus(txCtx, stepInst.ID, StepStatusCompleted); err != nil {
+			return err
+		}
+		if err := o.stateManager.UpdateStepOutput(txCtx, stepInst.ID, redactMap(output, keys)); err != nil {
+			return err
+		}
+		if deferred {
 			return nil
 		}
+		return o.stateManager.UpdateWorkflowOutput(txCtx, workflowInst.ID, redactMap(workflowOutput, keys))
+	})
+	o.notePersistence(err)
+	if err != nil {
+		o.logger.Printf("orchwf: failed to persist result of step %s for workflow %s: %v", stepDef.ID, workflowInst.ID, err)
+	}
 
-		lastErr = err
+	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, EventStepCompleted, withTags(map[string]interface{}{
+		"duration_ms": duration.Milliseconds(),
+	}, stepDef))
+	o.observeStepDuration(workflowInst, stepDef, duration)
+}
+
+// observeStepDuration records the duration of a completed step
+func (o *Orchestrator) observeStepDuration(workflowInst *WorkflowInstance, stepDef *StepDefinition, duration time.Duration) {
+	o.metrics.ObserveDuration("step.duration", duration, map[string]string{
+		"workflow_id": workflowInst.WorkflowID,
+		"step_id":     stepDef.ID,
+		"status":      string(StepStatusCompleted),
+		"tags":        tagLabel(stepDef),
+	})
+}
+
+// runExecutor runs one attempt of the step executor, holding the step's shared resource if it declares one
+func (o *Orchestrator) runExecutor(ctx context.Context, stepDef *StepDefinition, stepInst *StepInstance, input map[string]interface{}) (output map[string]interface{}, duration time.Duration, err error) {
+	if pool := o.resources[stepDef.Resource]; pool != nil {
+		leaveGate := o.waitAtGate(stepInst.ID, stepGate{blocker: StepBlockerResource, resource: stepDef.Resource})
+		err := pool.acquire(ctx, stepDef.ResourceWeight)
+		leaveGate()
+		if err != nil {
+			return nil, 0, err
+		}
+		defer pool.release(stepDef.ResourceWeight)
 	}
 
-	// All retries exhausted
-	stepInst.Status = StepStatusFailed
-	stepInst.Error = stringPtr(lastErr.Error())
+	// Intercepted values reach only the executor, not the persisted input or traces
+	execInput := input
+	if stepDef.InputInterceptor != nil {
+		execInput, err = stepDef.InputInterceptor(ctx, copyMap(input))
+		if err != nil {
+			return nil, 0, fmt.Errorf("input interceptor of step %s failed: %w", stepDef.ID, err)
+		}
+	}
+
+	startTime := time.Now()
+	if recorder := traceRecorderFromContext(ctx); recorder != nil {
+		capturedInput := copyMap(input)
+		defer func() {
+			recorder.recordStep(stepDef.ID, capturedInput, output, err, startTime, duration)
+		}()
+	}
+
+	// A step with its own timeout is bounded even when its executor ignores ctx
+	if !o.sandbox && stepDef.Timeout == 0 && stepDef.MaxDuration == 0 {
+		output, err := o.callExecutor(ctx, stepDef, execInput)
+		return output, time.Since(startTime), err
+	}
+
+	type executorResult struct {
+		output map[string]interface{}
+		err    error
+	}
+	done := make(chan executorResult, 1)
+	go func() {
+		output, err := o.callExecutor(ctx, stepDef, execInput)
+		done <- executorResult{output: output, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.output, time.Since(startTime), res.err
+	case <-ctx.Done():
+		o.abandoned.Add(1)
+		o.metrics.IncCounter("step.abandoned", map[string]string{
+			"step_id": stepDef.ID,
+			"tags":    tagLabel(stepDef),
+		})
+		go func() {
+			<-done
+			o.abandoned.Add(-1)
+		}()
+		return nil, time.Since(startTime), fmt.Errorf("step %s abandoned: %w", stepDef.ID, ctx.Err())
+	}
+}
+
+// AbandonedSteps returns the number of sandboxed executor goroutines that are
+// still running after their step timed out or was cancelled
+func (o *Orchestrator) AbandonedSteps() int64 {
+	return o.abandoned.Load()
+}
+
+// failStep marks a step as failed and records the error
+func (o *Orchestrator) failStep(ctx context.Context, stepDef *StepDefinition, stepInst *StepInstance, workflowInst *WorkflowInstance, stepErr error, kind FailureKind) {
+	if err := o.transitionStep(stepInst, StepStatusFailed); err != nil {
+		o.logger.Printf("orchwf: %v", err)
+		return
+	}
+	stepInst.Error = stringPtr(stepErr.Error())
+	stepInst.FailureKind = kind
 	now := time.Now()
 	stepInst.CompletedAt = &now
 
-	o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusFailed)
-	o.stateManager.UpdateStepError(ctx, stepInst.ID, lastErr)
+	o.flushStepProgress(ctx, stepInst, workflowInst)
+	o.notePersistence(o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusFailed))
+	o.notePersistence(o.stateManager.UpdateStepError(ctx, stepInst.ID, stepErr))
+	o.notePersistence(o.stateManager.UpdateStepFailureKind(ctx, stepInst.ID, kind))
+
+	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, EventStepFailed, withTags(map[string]interface{}{
+		"error":        stepErr.Error(),
+		"retries":      stepInst.RetryCount,
+		"failure_kind": string(kind),
+	}, stepDef))
+	o.metrics.IncCounter("step.failed", map[string]string{
+		"workflow_id": workflowInst.WorkflowID,
+		"step_id":     stepDef.ID,
+		"tags":        tagLabel(stepDef),
+	})
+}
+
+// deadlineFromInput reads an absolute deadline from input; a missing key means no deadline
+func deadlineFromInput(input map[string]interface{}, key string) (*time.Time, error) {
+	value, ok := input[key]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return &v, nil
+	case *time.Time:
+		return v, nil
+	case string:
+		deadline, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deadline %q in input key %s: %w", v, key, err)
+		}
+		return &deadline, nil
+	default:
+		return nil, fmt.Errorf("invalid deadline type %T in input key %s", value, key)
+	}
+}
+
+// skipOptionalStep marks a failed non-required step as skipped so its dependents can still run
+func (o *Orchestrator) skipOptionalStep(ctx context.Context, stepInst *StepInstance, workflowInst *WorkflowInstance, err error) {
+	reason := SkipReasonOptionalFailure
+	if errors.Is(err, context.DeadlineExceeded) {
+		reason = SkipReasonTimedOut
+	}
+	o.skipStep(ctx, stepInst, workflowInst, reason)
+}
+
+// checkOptionalFailures summarizes the non-required steps that failed and were skipped
+func checkOptionalFailures(instance *WorkflowInstance) error {
+	var failed []string
+	for _, stepInst := range instance.Steps {
+		if stepInst.Status != StepStatusSkipped {
+			continue
+		}
+		if stepInst.SkipReason == SkipReasonOptionalFailure || stepInst.SkipReason == SkipReasonTimedOut {
+			failed = append(failed, fmt.Sprintf("%s (%s)", stepInst.StepID, stringValue(stepInst.Error)))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrOptionalStepsFailed, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// skipStep marks a step as skipped with the given reason
+func (o *Orchestrator) skipStep(ctx context.Context, stepInst *StepInstance, workflowInst *WorkflowInstance, reason SkipReason) {
+	if err := o.transitionStep(stepInst, StepStatusSkipped); err != nil {
+		o.logger.Printf("orchwf: %v", err)
+		return
+	}
+	stepInst.SkipReason = reason
+	o.notePersistence(o.stateManager.UpdateStepSkipReason(ctx, stepInst.ID, reason))
+
+	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, EventStepSkipped, map[string]interface{}{
+		"step_id": stepInst.StepID,
+		"reason":  string(reason),
+	})
+}
+
+// cancelStep marks a step as cancelled after a sibling failure
+func (o *Orchestrator) cancelStep(ctx context.Context, stepInst *StepInstance, workflowInst *WorkflowInstance) {
+	if err := o.transitionStep(stepInst, StepStatusCancelled); err != nil {
+		o.logger.Printf("orchwf: %v", err)
+		return
+	}
+	o.notePersistence(o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusCancelled))
+
+	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, EventStepCancelled, map[string]interface{}{
+		"step_id": stepInst.StepID,
+	})
+}
+
+// skipPendingSteps marks steps that never started as skipped after the workflow failed
+func (o *Orchestrator) skipPendingSteps(ctx context.Context, workflowInst *WorkflowInstance) {
+	for _, stepInst := range workflowInst.Steps {
+		if stepInst.Status != StepStatusPending {
+			continue
+		}
+
+		o.skipStep(ctx, stepInst, workflowInst, SkipReasonCancelled)
+	}
+}
+
+// reachedSuccessStep reports the first success step of the workflow that has completed
+func (o *Orchestrator) reachedSuccessStep(workflow *WorkflowDefinition, stepInstMap map[string]*StepInstance) (string, bool) {
+	for _, stepID := range workflow.SuccessSteps {
+		if stepInst, ok := stepInstMap[stepID]; ok && stepInst.Status == StepStatusCompleted {
+			return stepID, true
+		}
+	}
+	return "", false
+}
+
+// skipAfterEarlySuccess marks steps that have not run, including waiting timers, as
+// skipped once a success step has completed the workflow
+func (o *Orchestrator) skipAfterEarlySuccess(ctx context.Context, workflowInst *WorkflowInstance, successStepID string) {
+	for _, stepInst := range workflowInst.Steps {
+		if stepInst.Status != StepStatusPending && stepInst.Status != StepStatusWaiting {
+			continue
+		}
+
+		o.skipStep(ctx, stepInst, workflowInst, SkipReasonEarlySuccess)
+	}
+
+	o.emitEvent(ctx, workflowInst.ID, nil, EventWorkflowEarlySuccess, map[string]interface{}{
+		"success_step_id": successStepID,
+	})
+}
+
+// awaitTimer persists the wake time of a timer step and reports whether the step must keep waiting
+func (o *Orchestrator) awaitTimer(ctx context.Context, stepDef *StepDefinition, stepInst *StepInstance, workflowInst *WorkflowInstance, input map[string]interface{}) bool {
+	if stepInst.WakeAt == nil {
+		wakeAt := stepDef.TimerUntil(input)
+		stepInst.WakeAt = &wakeAt
+		if err := o.stateManager.UpdateStepWakeAt(ctx, stepInst.ID, wakeAt); err != nil {
+			o.logger.Printf("orchwf: failed to persist wake time for step %s: %v", stepDef.ID, err)
+		}
+	}
+
+	if !time.Now().Before(*stepInst.WakeAt) {
+		return false
+	}
+
+	if stepInst.Status == StepStatusWaiting {
+		return true
+	}
+	if err := o.transitionStep(stepInst, StepStatusWaiting); err != nil {
+		o.logger.Printf("orchwf: %v", err)
+		return true
+	}
+
+	o.notePersistence(o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusWaiting))
+
+	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, EventStepWaiting, map[string]interface{}{
+		"step_id": stepDef.ID,
+		"wake_at": stepInst.WakeAt.Format(time.RFC3339Nano),
+	})
+
+	return true
+}
+
+// waitingSteps returns the IDs of steps waiting on a timer
+func (o *Orchestrator) waitingSteps(instance *WorkflowInstance) []string {
+	var ids []string
+	for _, stepInst := range instance.Steps {
+		if stepInst.Status == StepStatusWaiting {
+			ids = append(ids, stepInst.StepID)
+		}
+	}
+	return ids
+}
+
+// ResumeDueTimers resumes waiting workflows whose timer steps are due.
+// It returns the number of workflows that were resumed.
+func (o *Orchestrator) ResumeDueTimers(ctx context.Context) (int, error) {
+	steps, err := o.stateManager.GetDueWaitingSteps(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due waiting steps: %w", err)
+	}
+
+	resumed := 0
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		if seen[step.WorkflowInstID] {
+			continue
+		}
+		seen[step.WorkflowInstID] = true
+
+		untrack := o.trackRunning(step.WorkflowInstID)
+		_, err := o.ResumeWorkflow(ctx, step.WorkflowInstID)
+		untrack()
+		if err != nil {
+			o.logger.Printf("orchwf: failed to resume workflow %s: %v", step.WorkflowInstID, err)
+			continue
+		}
+		resumed++
+	}
+
+	return resumed, nil
+}
 
-	o.emitEvent(ctx, workflowInst.ID, &stepInst.ID, "step.failed", map[string]interface{}{
-		"error":   lastErr.Error(),
-		"retries": stepInst.RetryCount,
+// StartTimerTicker polls for due timer steps at the given interval until ctx is
+// cancelled or the orchestrator is shut down
+func (o *Orchestrator) StartTimerTicker(ctx context.Context, interval time.Duration) {
+	err := o.goBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-o.shutdown:
+				return
+			case <-ticker.C:
+				if _, err := o.ResumeDueTimers(ctx); err != nil {
+					o.logger.Printf("orchwf: timer ticker: %v", err)
+				}
+			}
+		}
 	})
+	if err != nil {
+		o.logger.Printf("orchwf: timer ticker not started: %v", err)
+	}
+}
 
-	return fmt.Errorf("step %s failed after %d attempts: %w", stepDef.ID, retryPolicy.MaxAttempts, lastErr)
+// effectivePriority returns the priority persisted on the step instance, falling back to the definition
+func (o *Orchestrator) effectivePriority(stepDef *StepDefinition, stepInstMap map[string]*StepInstance) int {
+	if stepInst, ok := stepInstMap[stepDef.ID]; ok {
+		return stepInst.Priority
+	}
+	return stepDef.Priority
 }
 
-// buildDependencyGraph builds a dependency graph from workflow steps
-func (o *Orchestrator) buildDependencyGraph(workflow *WorkflowDefinition) map[string][]string {
+// buildDependencyGraph builds a dependency graph from workflow steps, resolving
+// dynamic dependencies against the workflow input
+func (o *Orchestrator) buildDependencyGraph(workflow *WorkflowDefinition, input map[string]interface{}) (map[string][]string, error) {
+	stepIDs := make(map[string]bool)
+	for _, step := range workflow.Steps {
+		stepIDs[step.ID] = true
+	}
+
 	graph := make(map[string][]string)
 	for _, step := range workflow.Steps {
-		graph[step.ID] = step.Dependencies
+		deps := stepDependencies(workflow, step, input)
+		if step.DynamicDependencies != nil {
+			for _, dep := range deps {
+				if !stepIDs[dep] || dep == step.ID {
+					return nil, fmt.Errorf("%w: step %s resolved invalid dependency: %s", ErrInvalidDependency, step.ID, dep)
+				}
+			}
+		}
+		graph[step.ID] = deps
+	}
+	return graph, nil
+}
+
+// stepDependencies returns the steps a step waits for: its own dependencies, resolved
+// from the input when dynamic, plus the workflow's gate step, which every other step
+// implicitly depends on
+func stepDependencies(workflow *WorkflowDefinition, step *StepDefinition, input map[string]interface{}) []string {
+	deps := ownDependencies(step, input)
+
+	gate := workflow.GateStep
+	if gate == "" || step.ID == gate || containsString(deps, gate) {
+		return deps
+	}
+	return append([]string{gate}, deps...)
+}
+
+// ownDependencies returns the dependencies a step declares, resolved from the input when
+// dynamic. These are the steps whose outputs feed the step's input.
+func ownDependencies(step *StepDefinition, input map[string]interface{}) []string {
+	if step.DynamicDependencies != nil {
+		return step.DynamicDependencies(input)
 	}
-	return graph
+	return step.Dependencies
 }
 
 // findReadySteps finds steps that can be executed (all dependencies met)
@@ -505,36 +1699,99 @@ func (o *Orchestrator) findReadySteps(workflow *WorkflowDefinition, executed map
 	return ready
 }
 
-// prepareStepInput prepares input for a step from workflow input and previous step outputs
+// prepareStepInput prepares input for a step from workflow input and previous step outputs.
+// Under WithInputProvenance it also records the source of each key on the step instance.
 func (o *Orchestrator) prepareStepInput(stepDef *StepDefinition, stepInst *StepInstance, workflowInst *WorkflowInstance, stepInstMap map[string]*StepInstance) map[string]interface{} {
 	input := make(map[string]interface{})
+	var provenance map[string]string
+	if o.inputProvenance {
+		provenance = make(map[string]string)
+	}
 
 	// Start with workflow input
 	for k, v := range workflowInst.Input {
 		input[k] = v
+		if provenance != nil {
+			provenance[k] = ProvenanceInput
+		}
 	}
 
 	// Add outputs from dependency steps
-	for _, depID := range stepDef.Dependencies {
+	deps := ownDependencies(stepDef, workflowInst.Input)
+	for _, depID := range deps {
 		if depInst, ok := stepInstMap[depID]; ok {
 			for k, v := range depInst.Output {
 				input[k] = v
+				if provenance != nil {
+					provenance[k] = dependencyProvenance(depID)
+				}
 			}
 			// Also add with step prefix
 			input[depID] = depInst.Output
+			if provenance != nil {
+				provenance[depID] = dependencyProvenance(depID)
+			}
 		}
 	}
 
-	// Add workflow context
+	// Add workflow context; async siblings may be merging their output concurrently
+	o.outputMu.Lock()
 	for k, v := range workflowInst.Context {
+		// A context entry that repeats a value already taken from the input or a
+		// dependency keeps that source
+		if provenance != nil {
+			if prev, ok := input[k]; !ok || !reflect.DeepEqual(prev, v) {
+				provenance[k] = ProvenanceContext
+			}
+		}
 		input[k] = v
 	}
+	o.outputMu.Unlock()
+
+	if stepDef.FanIn {
+		reduced := applyFanIn(stepDef, deps, input, stepInstMap)
+		if provenance != nil {
+			provenance[stepDef.ID] = ProvenanceFanIn
+			for k := range reduced {
+				provenance[k] = ProvenanceReducer
+			}
+		}
+	}
 
+	if provenance != nil {
+		stepInst.Provenance = provenance
+	}
 	return input
 }
 
-// mergeStepOutput merges step output into workflow context
-func (o *Orchestrator) mergeStepOutput(workflowInst *WorkflowInstance, stepID string, output map[string]interface{}) {
+// mapWorkflowOutput sets the output of a completed workflow to what its output mapping builds
+// from the completed steps, and persists it. The merged outputs stay in the instance context.
+func (o *Orchestrator) mapWorkflowOutput(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) {
+	stepOutputs := make(map[string]map[string]interface{})
+	for _, stepInst := range instance.Steps {
+		if stepInst.Status == StepStatusCompleted {
+			stepOutputs[stepInst.StepID] = copyMap(stepInst.Output)
+		}
+	}
+
+	output := workflow.OutputMapping(stepOutputs)
+	if output == nil {
+		output = make(map[string]interface{})
+	}
+	instance.Output = output
+
+	if err := o.stateManager.UpdateWorkflowOutput(ctx, instance.ID, redactMap(output, workflow.RedactedKeys)); err != nil {
+		o.logger.Printf("orchwf: failed to persist output of workflow %s: %v", instance.ID, err)
+	}
+}
+
+// mergeStepOutput merges step output into workflow context and returns a copy of the workflow output
+func (o *Orchestrator) mergeStepOutput(workflowInst *WorkflowInstance, stepID string, output map[string]interface{}) map[string]interface{} {
+	deep := o.deepMerge(workflowInst.WorkflowID)
+
+	o.outputMu.Lock()
+	defer o.outputMu.Unlock()
+
 	if workflowInst.Context == nil {
 		workflowInst.Context = make(map[string]interface{})
 	}
@@ -542,8 +1799,11 @@ func (o *Orchestrator) mergeStepOutput(workflowInst *WorkflowInstance, stepID st
 	// Store output under step ID
 	workflowInst.Context[stepID] = output
 
-	// Also merge directly into context
+	// Also merge directly into context, recursively under WithDeepMerge
 	for k, v := range output {
+		if deep {
+			v = mergeValue(workflowInst.Context[k], v)
+		}
 		workflowInst.Context[k] = v
 	}
 
@@ -552,8 +1812,18 @@ func (o *Orchestrator) mergeStepOutput(workflowInst *WorkflowInstance, stepID st
 		workflowInst.Output = make(map[string]interface{})
 	}
 	for k, v := range output {
+		if deep {
+			v = mergeValue(workflowInst.Output[k], v)
+		}
 		workflowInst.Output[k] = v
 	}
+
+	// Return a snapshot so it can be persisted outside the lock
+	snapshot := make(map[string]interface{}, len(workflowInst.Output))
+	for k, v := range workflowInst.Output {
+		snapshot[k] = v
+	}
+	return snapshot
 }
 
 // calculateRetryInterval calculates the retry interval with exponential backoff
@@ -577,12 +1847,15 @@ func (o *Orchestrator) emitEvent(ctx context.Context, workflowInstID string, ste
 		WorkflowInstID: workflowInstID,
 		StepInstID:     stepInstID,
 		EventType:      eventType,
-		EventData:      data,
+		EventData:      redactMap(data, o.redactedKeysFromContext(ctx)),
 		Timestamp:      time.Now(),
 	}
 
 	// Best effort - don't fail workflow if event saving fails
-	o.stateManager.SaveEvent(ctx, event)
+	if err := o.stateManager.SaveEvent(ctx, event); err != nil {
+		o.logger.Printf("orchwf: failed to save event %s for workflow %s: %v", eventType, workflowInstID, err)
+	}
+	o.watchers.notify(workflowInstID)
 }
 
 // Helper functions
@@ -629,6 +1902,20 @@ func getBusinessID(ctx context.Context, metadata map[string]interface{}) string
 	return ""
 }
 
+func getParentInstID(ctx context.Context, metadata map[string]interface{}) string {
+	if metadata != nil {
+		if parentInstID, ok := metadata["parent_workflow_inst_id"].(string); ok {
+			return parentInstID
+		}
+	}
+	if parentInstID := ctx.Value("parent_workflow_inst_id"); parentInstID != nil {
+		if id, ok := parentInstID.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
 func pow(base, exp float64) float64 {
 	if exp == 0 {
 		return 1
@@ -3,6 +3,11 @@ package orchwf
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,6 +38,79 @@ func TestNewOrchestratorWithAsyncWorkers(t *testing.T) {
 	}
 }
 
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+type recordingMetrics struct {
+	mu        sync.Mutex
+	counters  map[string]int
+	durations map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: make(map[string]int), durations: make(map[string]int)}
+}
+
+func (m *recordingMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name]++
+}
+
+func (m *recordingMetrics) ObserveDuration(name string, duration time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations[name]++
+}
+
+func TestNewOrchestrator_Options(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	logger := &recordingLogger{}
+	metrics := newRecordingMetrics()
+
+	orchestrator := NewOrchestrator(sm, WithAsyncWorkers(3), WithLogger(logger), WithMetrics(metrics))
+
+	if orchestrator.asyncWorkers != 3 {
+		t.Errorf("WithAsyncWorkers() asyncWorkers = %v, want %v", orchestrator.asyncWorkers, 3)
+	}
+	if orchestrator.logger != logger {
+		t.Errorf("WithLogger() did not set logger")
+	}
+
+	workflow := &WorkflowDefinition{
+		ID:   "options-workflow",
+		Name: "Options Workflow",
+		Steps: []*StepDefinition{
+			{ID: "step1", Name: "Step 1", Required: true, Executor: func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"result": "success"}, nil
+			}},
+		},
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "options-workflow", nil, nil); err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	if metrics.durations["workflow.duration"] != 1 {
+		t.Errorf("workflow.duration observations = %v, want %v", metrics.durations["workflow.duration"], 1)
+	}
+	if metrics.durations["step.duration"] != 1 {
+		t.Errorf("step.duration observations = %v, want %v", metrics.durations["step.duration"], 1)
+	}
+
+	// Nil options keep the defaults
+	orchestrator = NewOrchestrator(sm, WithLogger(nil), WithMetrics(nil))
+	if orchestrator.logger == nil || orchestrator.metrics == nil {
+		t.Errorf("nil logger or metrics should keep the no-op defaults")
+	}
+}
+
 func TestOrchestrator_RegisterWorkflow(t *testing.T) {
 	orchestrator := NewOrchestrator(NewInMemoryStateManager())
 
@@ -52,6 +130,12 @@ func TestOrchestrator_RegisterWorkflow(t *testing.T) {
 		t.Errorf("RegisterWorkflow() error = %v", err)
 	}
 
+	// Test duplicate registration
+	err = orchestrator.RegisterWorkflow(workflow)
+	if !errors.Is(err, ErrWorkflowAlreadyExists) {
+		t.Errorf("RegisterWorkflow() duplicate error = %v, want ErrWorkflowAlreadyExists", err)
+	}
+
 	// Test nil workflow
 	err = orchestrator.RegisterWorkflow(nil)
 	if err == nil {
@@ -66,6 +150,115 @@ func TestOrchestrator_RegisterWorkflow(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_RegisterWorkflowUnboundExecutor(t *testing.T) {
+	noop := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	workflow := &WorkflowDefinition{
+		ID:   "loaded",
+		Name: "Loaded",
+		Steps: []*StepDefinition{
+			{ID: "fetch", Name: "Fetch", Executor: noop},
+			{ID: "store", Name: "Store", Dependencies: []string{"fetch"}},
+		},
+	}
+
+	err := NewOrchestrator(NewInMemoryStateManager()).RegisterWorkflow(workflow)
+	if !errors.Is(err, ErrExecutorNotFound) || !strings.Contains(err.Error(), "store") {
+		t.Errorf("RegisterWorkflow() error = %v, want ErrExecutorNotFound naming store", err)
+	}
+
+	// A remote transport binds executors itself
+	remote := NewOrchestrator(NewInMemoryStateManager(), WithTransport(&countingTransport{}))
+	if err := remote.RegisterWorkflow(workflow); err != nil {
+		t.Errorf("RegisterWorkflow() with a transport error = %v", err)
+	}
+}
+
+// liveInstanceStateManager keeps the instance the orchestrator saves, which is the one it runs
+type liveInstanceStateManager struct {
+	*InMemoryStateManager
+	live *WorkflowInstance
+}
+
+func (m *liveInstanceStateManager) SaveWorkflow(ctx context.Context, workflow *WorkflowInstance) error {
+	m.live = workflow
+	return m.InMemoryStateManager.SaveWorkflow(ctx, workflow)
+}
+
+func TestOrchestrator_ResultInstanceIsCloned(t *testing.T) {
+	sm := &liveInstanceStateManager{InMemoryStateManager: NewInMemoryStateManager()}
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("order", "Order").
+		AddStepFunc("price", "Price", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"quote": map[string]interface{}{"total": 10}}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "order", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// Changing the result downstream must not reach the instance the orchestrator ran
+	result.WorkflowInst.Status = WorkflowStatusFailed
+	result.WorkflowInst.Steps[0].Status = StepStatusFailed
+	result.Output["quote"].(map[string]interface{})["total"] = 0
+
+	if sm.live.Status != WorkflowStatusCompleted || sm.live.Steps[0].Status != StepStatusCompleted {
+		t.Errorf("live instance = %s with step %s, want both completed", sm.live.Status, sm.live.Steps[0].Status)
+	}
+	if total := sm.live.Output["quote"].(map[string]interface{})["total"]; total != 10 {
+		t.Errorf("live output total = %v, want 10", total)
+	}
+}
+
+func TestOrchestrator_RegisterWorkflowLimits(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithLimits(2, 2))
+
+	newWorkflow := func(id string, steps int) *WorkflowDefinition {
+		builder := NewWorkflowBuilder(id, id)
+		for i := 0; i < steps; i++ {
+			builder.AddStepFunc(fmt.Sprintf("step%d", i), "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return nil, nil
+			})
+		}
+		workflow, _ := builder.Build()
+		return workflow
+	}
+
+	// At the step limit
+	if err := orchestrator.RegisterWorkflow(newWorkflow("at-limit", 2)); err != nil {
+		t.Errorf("RegisterWorkflow() at step limit error = %v", err)
+	}
+
+	// Beyond the step limit
+	err := orchestrator.RegisterWorkflow(newWorkflow("too-many-steps", 3))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("RegisterWorkflow() beyond step limit error = %v, want ErrLimitExceeded", err)
+	}
+
+	// At the workflow limit
+	if err := orchestrator.RegisterWorkflow(newWorkflow("second", 1)); err != nil {
+		t.Errorf("RegisterWorkflow() at workflow limit error = %v", err)
+	}
+
+	// Beyond the workflow limit
+	err = orchestrator.RegisterWorkflow(newWorkflow("third", 1))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("RegisterWorkflow() beyond workflow limit error = %v, want ErrLimitExceeded", err)
+	}
+
+	// Zero disables the limits
+	unlimited := NewOrchestrator(NewInMemoryStateManager(), WithLimits(0, 0))
+	for i := 0; i < 5; i++ {
+		if err := unlimited.RegisterWorkflow(newWorkflow(fmt.Sprintf("wf%d", i), 10)); err != nil {
+			t.Errorf("RegisterWorkflow() without limits error = %v", err)
+		}
+	}
+}
+
 func TestOrchestrator_GetWorkflow(t *testing.T) {
 	orchestrator := NewOrchestrator(NewInMemoryStateManager())
 
@@ -92,8 +285,14 @@ func TestOrchestrator_GetWorkflow(t *testing.T) {
 
 	// Test non-existing workflow
 	_, err = orchestrator.GetWorkflow("non-existing")
-	if err == nil {
-		t.Errorf("GetWorkflow() with non-existing workflow should return error")
+	if !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("GetWorkflow() with non-existing workflow error = %v, want ErrWorkflowNotFound", err)
+	}
+
+	// Not-found survives wrapping by the orchestrator
+	_, err = orchestrator.ResumeWorkflow(context.Background(), "non-existing")
+	if !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("ResumeWorkflow() with non-existing instance error = %v, want ErrWorkflowNotFound", err)
 	}
 }
 
@@ -133,6 +332,42 @@ func TestOrchestrator_StartWorkflow(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_InputDefaults(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var seen map[string]interface{}
+	workflow, _ := NewWorkflowBuilder("defaults-workflow", "Defaults Workflow").
+		WithInputDefaults(map[string]interface{}{"currency": "USD", "region": "us"}).
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			seen = input
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "defaults-workflow",
+		map[string]interface{}{"currency": "EUR", "amount": 10}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	if seen["currency"] != "EUR" {
+		t.Errorf("caller value should override default, got currency = %v", seen["currency"])
+	}
+	if seen["region"] != "us" {
+		t.Errorf("missing key should take default, got region = %v", seen["region"])
+	}
+	if seen["amount"] != 10 {
+		t.Errorf("caller-only key should be kept, got amount = %v", seen["amount"])
+	}
+	if result.WorkflowInst.Input["region"] != "us" {
+		t.Errorf("instance input should include defaults, got %v", result.WorkflowInst.Input)
+	}
+	if _, ok := workflow.InputDefaults["amount"]; ok {
+		t.Errorf("defaults should not be mutated by caller input")
+	}
+}
+
 func TestOrchestrator_StartWorkflowAsync(t *testing.T) {
 	orchestrator := NewOrchestrator(NewInMemoryStateManager())
 
@@ -218,6 +453,37 @@ func TestOrchestrator_ResumeWorkflow(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_GetWorkflowSteps(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	workflow, _ := NewWorkflowBuilder("steps-workflow", "Steps Workflow").
+		AddStepFunc("first", "First", executor).
+		AddStepFunc("second", "Second", executor, WithStepDeps("first")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "steps-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	steps, err := orchestrator.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("GetWorkflowSteps() error = %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("GetWorkflowSteps() returned %d steps, want 2", len(steps))
+	}
+	for _, step := range steps {
+		if step.Status != StepStatusCompleted {
+			t.Errorf("step %s status = %v, want %v", step.StepID, step.Status, StepStatusCompleted)
+		}
+	}
+}
+
 func TestOrchestrator_ListWorkflows(t *testing.T) {
 	orchestrator := NewOrchestrator(NewInMemoryStateManager())
 
@@ -335,7 +601,7 @@ func TestOrchestrator_RetryPolicy(t *testing.T) {
 	}).WithRetryPolicy(NewRetryPolicyBuilder().
 		WithMaxAttempts(3).
 		WithInitialInterval(1 * time.Millisecond).
-		Build()).
+		MustBuild()).
 		Build()
 
 	workflow, _ := NewWorkflowBuilder("test-workflow", "Test Workflow").
@@ -387,3 +653,1735 @@ func TestOrchestrator_OptionalSteps(t *testing.T) {
 		t.Errorf("StartWorkflow() with optional step success = %v, want %v", result.Success, true)
 	}
 }
+
+func TestOrchestrator_TreatOptionalFailureAsWorkflowFailure(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict %v", strict), func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+			var finalized bool
+			workflow, _ := NewWorkflowBuilder("error_handling_demo", "Error Handling").
+				WithTreatOptionalFailureAsWorkflowFailure(strict).
+				AddStepFunc("unreliable_api_call", "Unreliable API Call", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return map[string]interface{}{"api_result": "ok"}, nil
+				}).
+				AddStepFunc("always_fails", "Always Fails Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return nil, errors.New("permanent failure: service unavailable")
+				}, WithStepDeps("unreliable_api_call"), WithStepRequired(false)).
+				AddStepFunc("finalize", "Finalize Results", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					finalized = true
+					return nil, nil
+				}, WithStepDeps("always_fails")).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "error_handling_demo", nil, nil)
+
+			// Dependents of the failed optional step run under both settings
+			if !finalized {
+				t.Errorf("finalize did not run after the optional failure")
+			}
+			if !strict {
+				if err != nil || !result.Success {
+					t.Errorf("StartWorkflow() = %v, %v, want success by default", result.Success, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrOptionalStepsFailed) || !strings.Contains(err.Error(), "always_fails (permanent failure") {
+				t.Errorf("StartWorkflow() error = %v, want ErrOptionalStepsFailed naming always_fails", err)
+			}
+			if result.Success || result.WorkflowInst.Status != WorkflowStatusFailed {
+				t.Errorf("workflow = %s, want failed", result.WorkflowInst.Status)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_TimerStep(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	var afterRuns int
+	workflow, err := NewWorkflowBuilder("timer-workflow", "Timer Workflow").
+		AddStepFunc("before", "Before", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"before": "done"}, nil
+		}).
+		AddStepFunc("sleep", "Sleep", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"slept": true}, nil
+		},
+			WithStepDeps("before"),
+			WithStepTimerUntil(func(input map[string]interface{}) time.Time {
+				return time.Now().Add(50 * time.Millisecond)
+			}),
+		).
+		AddStepFunc("after", "After", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			afterRuns++
+			return map[string]interface{}{"after": "done"}, nil
+		}, WithStepDeps("sleep")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "timer-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusWaiting {
+		t.Fatalf("StartWorkflow() status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusWaiting)
+	}
+	if afterRuns != 0 {
+		t.Errorf("dependent of waiting timer step should not run")
+	}
+
+	// Nothing is due yet
+	resumed, err := orchestrator.ResumeDueTimers(context.Background())
+	if err != nil || resumed != 0 {
+		t.Errorf("ResumeDueTimers() = %v, %v, want 0, nil", resumed, err)
+	}
+
+	// A fresh orchestrator stands in for a restarted process
+	time.Sleep(60 * time.Millisecond)
+	restarted := NewOrchestrator(sm)
+	restarted.RegisterWorkflow(workflow)
+
+	resumed, err = restarted.ResumeDueTimers(context.Background())
+	if err != nil || resumed != 1 {
+		t.Fatalf("ResumeDueTimers() = %v, %v, want 1, nil", resumed, err)
+	}
+
+	instance, err := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("GetWorkflow() error = %v", err)
+	}
+	if instance.Status != WorkflowStatusCompleted {
+		t.Errorf("resumed workflow status = %v, want %v", instance.Status, WorkflowStatusCompleted)
+	}
+	if afterRuns != 1 {
+		t.Errorf("dependent step runs = %v, want %v", afterRuns, 1)
+	}
+}
+
+func TestOrchestrator_ChildWorkflowInheritsParentDeadline(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	childDef, _ := NewWorkflowBuilder("child", "Child").
+		AddStepFunc("wait", "Wait", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(childDef)
+
+	type childOutcome struct {
+		result *WorkflowResult
+		err    error
+	}
+	childDone := make(chan childOutcome, 1)
+	spawn, _ := NewStepBuilder("spawn", "Spawn", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		info, _ := InstanceInfoFromContext(ctx)
+		result, err := orchestrator.StartChildWorkflow(ctx, info.InstanceID, "child", nil, nil)
+		childDone <- childOutcome{result, err}
+		return nil, err
+	}).WithTimeout(50 * time.Millisecond).Build()
+	parentDef, _ := NewWorkflowBuilder("parent", "Parent").AddStep(spawn).Build()
+	orchestrator.RegisterWorkflow(parentDef)
+
+	start := time.Now()
+	if _, err := orchestrator.StartWorkflow(context.Background(), "parent", nil, nil); err == nil {
+		t.Fatalf("StartWorkflow() should fail when the child outlives the parent's deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("parent took %v, want the child stopped at the parent's deadline", elapsed)
+	}
+	var child childOutcome
+	select {
+	case child = <-childDone:
+	case <-time.After(time.Second):
+		t.Fatalf("child workflow did not stop at the parent's deadline")
+	}
+	if child.err == nil {
+		t.Fatalf("StartChildWorkflow() should fail at the parent's deadline")
+	}
+	if child.result == nil || child.result.WorkflowInst.Status != WorkflowStatusFailed {
+		t.Errorf("child result = %+v, want a failed child instance", child.result)
+	}
+}
+
+func TestOrchestrator_ChildWorkflowInsufficientBudget(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithMinChildBudget(time.Second))
+
+	childRan := false
+	childDef, _ := NewWorkflowBuilder("child", "Child").
+		AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			childRan = true
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(childDef)
+
+	var childErr error
+	var parentID string
+	spawn, _ := NewStepBuilder("spawn", "Spawn", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		info, _ := InstanceInfoFromContext(ctx)
+		parentID = info.InstanceID
+		_, childErr = orchestrator.StartChildWorkflow(ctx, info.InstanceID, "child", nil, nil)
+		return nil, childErr
+	}).WithTimeout(100 * time.Millisecond).Build()
+	parentDef, _ := NewWorkflowBuilder("parent", "Parent").AddStep(spawn).Build()
+	orchestrator.RegisterWorkflow(parentDef)
+
+	orchestrator.StartWorkflow(context.Background(), "parent", nil, nil)
+
+	if !errors.Is(childErr, ErrInsufficientBudget) {
+		t.Fatalf("StartChildWorkflow() error = %v, want ErrInsufficientBudget", childErr)
+	}
+	if !strings.Contains(childErr.Error(), "insufficient time budget for sub-workflow child") {
+		t.Errorf("StartChildWorkflow() error = %q, want it to name the sub-workflow", childErr)
+	}
+	if childRan {
+		t.Errorf("child step ran despite the insufficient budget")
+	}
+	if children, _ := sm.GetChildWorkflows(context.Background(), parentID); len(children) != 0 {
+		t.Errorf("GetChildWorkflows() = %d instances, want none created", len(children))
+	}
+}
+
+func TestOrchestrator_StartChildWorkflow(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"result": "success"}, nil
+	}
+	parentDef, _ := NewWorkflowBuilder("parent", "Parent").AddStepFunc("step1", "Step 1", executor).Build()
+	childDef, _ := NewWorkflowBuilder("child", "Child").AddStepFunc("step1", "Step 1", executor).Build()
+	orchestrator.RegisterWorkflow(parentDef)
+	orchestrator.RegisterWorkflow(childDef)
+
+	parent, err := orchestrator.StartWorkflow(context.Background(), "parent", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	parentID := parent.WorkflowInst.ID
+
+	child, err := orchestrator.StartChildWorkflow(context.Background(), parentID, "child", nil, map[string]interface{}{"business_id": "biz"})
+	if err != nil {
+		t.Fatalf("StartChildWorkflow() error = %v", err)
+	}
+	if child.WorkflowInst.ParentInstID != parentID {
+		t.Errorf("StartChildWorkflow() ParentInstID = %v, want %v", child.WorkflowInst.ParentInstID, parentID)
+	}
+	if child.WorkflowInst.BusinessID != "biz" {
+		t.Errorf("StartChildWorkflow() BusinessID = %v, want %v", child.WorkflowInst.BusinessID, "biz")
+	}
+
+	children, err := orchestrator.GetChildWorkflows(context.Background(), parentID)
+	if err != nil {
+		t.Fatalf("GetChildWorkflows() error = %v", err)
+	}
+	if len(children) != 1 || children[0].ID != child.WorkflowInst.ID {
+		t.Errorf("GetChildWorkflows() = %v, want child %v", children, child.WorkflowInst.ID)
+	}
+
+	events, _ := sm.GetWorkflowEvents(context.Background(), parentID)
+	found := false
+	for _, event := range events {
+		if event.EventType == EventWorkflowChildStarted && event.EventData["child_workflow_inst_id"] == child.WorkflowInst.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("parent events should include workflow.child_started for the child")
+	}
+}
+
+func TestOrchestrator_FailurePolicy(t *testing.T) {
+	failing := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("boom")
+	}
+	blocking := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+			return map[string]interface{}{"slow": "done"}, nil
+		}
+	}
+	succeeding := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"ok": true}, nil
+	}
+
+	t.Run("fail fast cancels async siblings", func(t *testing.T) {
+		sm := NewInMemoryStateManager()
+		orchestrator := NewOrchestrator(sm)
+
+		workflow, _ := NewWorkflowBuilder("fail-fast", "Fail Fast").
+			AddStepFunc("fail", "Fail", failing, WithStepAsync(true)).
+			AddStepFunc("slow", "Slow", blocking, WithStepAsync(true)).
+			AddStepFunc("after", "After", succeeding, WithStepDeps("fail")).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		result, err := orchestrator.StartWorkflow(context.Background(), "fail-fast", nil, nil)
+		if err == nil {
+			t.Fatalf("StartWorkflow() should fail")
+		}
+		if time.Since(start) > time.Second {
+			t.Errorf("fail fast should cancel the blocking sibling, took %v", time.Since(start))
+		}
+
+		statuses := make(map[string]StepStatus)
+		reasons := make(map[string]SkipReason)
+		for _, step := range result.WorkflowInst.Steps {
+			statuses[step.StepID] = step.Status
+			reasons[step.StepID] = step.SkipReason
+		}
+		if statuses["fail"] != StepStatusFailed {
+			t.Errorf("failing step status = %v, want %v", statuses["fail"], StepStatusFailed)
+		}
+		if statuses["slow"] != StepStatusCancelled {
+			t.Errorf("sibling step status = %v, want %v", statuses["slow"], StepStatusCancelled)
+		}
+		if statuses["after"] != StepStatusSkipped {
+			t.Errorf("pending step status = %v, want %v", statuses["after"], StepStatusSkipped)
+		}
+		if reasons["after"] != SkipReasonCancelled {
+			t.Errorf("pending step skip reason = %v, want %v", reasons["after"], SkipReasonCancelled)
+		}
+	})
+
+	t.Run("complete wave finishes ready steps", func(t *testing.T) {
+		sm := NewInMemoryStateManager()
+		orchestrator := NewOrchestrator(sm)
+
+		workflow, _ := NewWorkflowBuilder("complete-wave", "Complete Wave").
+			WithFailurePolicy(FailurePolicyCompleteWave).
+			AddStepFunc("fail", "Fail", failing, WithStepPriority(10)).
+			AddStepFunc("sibling", "Sibling", succeeding).
+			AddStepFunc("async", "Async", succeeding, WithStepAsync(true)).
+			AddStepFunc("after", "After", succeeding, WithStepDeps("sibling")).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "complete-wave", nil, nil)
+		if err == nil {
+			t.Fatalf("StartWorkflow() should fail")
+		}
+
+		statuses := make(map[string]StepStatus)
+		for _, step := range result.WorkflowInst.Steps {
+			statuses[step.StepID] = step.Status
+		}
+		if statuses["sibling"] != StepStatusCompleted {
+			t.Errorf("sync sibling status = %v, want %v", statuses["sibling"], StepStatusCompleted)
+		}
+		if statuses["async"] != StepStatusCompleted {
+			t.Errorf("async sibling status = %v, want %v", statuses["async"], StepStatusCompleted)
+		}
+		if statuses["after"] != StepStatusSkipped {
+			t.Errorf("next wave step status = %v, want %v", statuses["after"], StepStatusSkipped)
+		}
+	})
+}
+
+func TestOrchestrator_AsyncOptionalStepFailureRunsDependents(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		sm := NewInMemoryStateManager()
+		orchestrator := NewOrchestrator(sm)
+
+		var dependentRan bool
+		workflow, _ := NewWorkflowBuilder("async-optional", "Async Optional").
+			AddStepFunc("optional", "Optional", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("optional failure")
+			}, WithStepAsync(true), WithStepRequired(false)).
+			AddStepFunc("sibling", "Sibling", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"sibling": "done"}, nil
+			}, WithStepAsync(true)).
+			AddStepFunc("dependent", "Dependent", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				dependentRan = true
+				return map[string]interface{}{"dependent": "done"}, nil
+			}, WithStepDeps("optional", "sibling")).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "async-optional", nil, nil)
+		if err != nil {
+			t.Fatalf("StartWorkflow() error = %v", err)
+		}
+		if !result.Success || !dependentRan {
+			t.Fatalf("dependent of skipped async optional step should run (success=%v, ran=%v)", result.Success, dependentRan)
+		}
+
+		steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+		for _, step := range steps {
+			if step.StepID == "optional" && (step.Status != StepStatusSkipped || step.SkipReason != SkipReasonOptionalFailure) {
+				t.Errorf("optional step status = %v (%v), want %v (%v)", step.Status, step.SkipReason, StepStatusSkipped, SkipReasonOptionalFailure)
+			}
+		}
+
+		events, _ := sm.GetWorkflowEvents(context.Background(), result.WorkflowInst.ID)
+		skippedEvents := 0
+		for _, event := range events {
+			if event.EventType == EventStepSkipped {
+				skippedEvents++
+				if event.EventData["reason"] != string(SkipReasonOptionalFailure) {
+					t.Errorf("step.skipped reason = %v, want %v", event.EventData["reason"], SkipReasonOptionalFailure)
+				}
+			}
+		}
+		if skippedEvents != 1 {
+			t.Errorf("step.skipped events = %d, want %d", skippedEvents, 1)
+		}
+	}
+}
+
+func TestOrchestrator_IdempotencyToken(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var tokens []string
+	attempts := 0
+	workflow, _ := NewWorkflowBuilder("idempotency-workflow", "Idempotency Workflow").
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			token, ok := IdempotencyTokenFromContext(ctx)
+			if !ok {
+				return nil, fmt.Errorf("missing idempotency token")
+			}
+			tokens = append(tokens, token)
+			attempts++
+			if attempts < 3 {
+				return nil, fmt.Errorf("transient failure")
+			}
+			return map[string]interface{}{"charged": true}, nil
+		}, WithStepRetryPolicy(&RetryPolicy{
+			MaxAttempts:     3,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+			Multiplier:      1,
+		}), WithStepTimeout(time.Second)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "idempotency-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	if len(tokens) != 3 {
+		t.Fatalf("executor attempts = %d, want %d", len(tokens), 3)
+	}
+	for _, token := range tokens {
+		if token != tokens[0] {
+			t.Errorf("IdempotencyTokenFromContext() changed across retries: %v", tokens)
+		}
+	}
+
+	steps, _ := orchestrator.stateManager.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	if len(steps) != 1 || steps[0].ID != tokens[0] {
+		t.Errorf("IdempotencyTokenFromContext() = %v, want step instance ID", tokens[0])
+	}
+
+	if _, ok := IdempotencyTokenFromContext(context.Background()); ok {
+		t.Errorf("IdempotencyTokenFromContext() should be unset outside step execution")
+	}
+}
+
+func TestOrchestrator_StatusReflectsLiveProgress(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	unblock := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("live-status", "Live Status").
+		AddStepFunc("fetch", "Fetch", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"fetched": 3}, nil
+		}).
+		AddStepFunc("process", "Process", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-unblock
+			return nil, nil
+		}, WithStepDeps("fetch")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	id, err := orchestrator.StartWorkflowAsync(ctx, "live-status", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+
+	// Poll as a status endpoint would until the second step is running
+	var status *WorkflowInstance
+	deadline := time.Now().Add(time.Second)
+	for {
+		status, err = orchestrator.GetWorkflowStatus(ctx, id)
+		if err != nil {
+			t.Fatalf("GetWorkflowStatus() error = %v", err)
+		}
+		if status.CurrentStepID == "process" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetWorkflowStatus() current step = %q, want process", status.CurrentStepID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if status.Status != WorkflowStatusRunning {
+		t.Errorf("status mid-run = %v, want running", status.Status)
+	}
+	if status.Output["fetched"] != 3 {
+		t.Errorf("output mid-run = %v, want the completed step's output", status.Output)
+	}
+	stepStatus := make(map[string]StepStatus)
+	for _, stepInst := range status.Steps {
+		stepStatus[stepInst.StepID] = stepInst.Status
+	}
+	if stepStatus["fetch"] != StepStatusCompleted || stepStatus["process"] != StepStatusRunning {
+		t.Errorf("step statuses mid-run = %v, want fetch completed and process running", stepStatus)
+	}
+
+	close(unblock)
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	orchestrator.Shutdown(shutdownCtx)
+
+	if status, _ = orchestrator.GetWorkflowStatus(ctx, id); status.Status != WorkflowStatusCompleted {
+		t.Errorf("final status = %v, want completed", status.Status)
+	}
+}
+
+func TestOrchestrator_OutputMapping(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	var seen map[string]map[string]interface{}
+	workflow, _ := NewWorkflowBuilder("output-mapping", "Output Mapping").
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"charge_id": "ch_1", "raw_response": "..."}, nil
+		}).
+		AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"tracking": "TRK-9"}, nil
+		}, WithStepDeps("charge")).
+		AddStepFunc("enrich", "Enrich", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, fmt.Errorf("enrichment unavailable")
+		}, WithStepRequired(false)).
+		WithOutputMapping(func(stepOutputs map[string]map[string]interface{}) map[string]interface{} {
+			seen = stepOutputs
+			return map[string]interface{}{
+				"charge_id": stepOutputs["charge"]["charge_id"],
+				"tracking":  stepOutputs["ship"]["tracking"],
+			}
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "output-mapping", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	want := map[string]interface{}{"charge_id": "ch_1", "tracking": "TRK-9"}
+	if !reflect.DeepEqual(result.Output, want) {
+		t.Errorf("result output = %v, want %v", result.Output, want)
+	}
+	if _, ok := seen["enrich"]; ok || len(seen) != 2 {
+		t.Errorf("mapping saw %v, want only the completed steps", seen)
+	}
+
+	saved, _ := sm.GetWorkflow(ctx, result.WorkflowInst.ID)
+	if !reflect.DeepEqual(saved.Output, want) {
+		t.Errorf("persisted output = %v, want %v", saved.Output, want)
+	}
+
+	// The merged step outputs are still available per step
+	if output, ok := result.StepOutput("charge"); !ok || output["raw_response"] != "..." {
+		t.Errorf("StepOutput(charge) = %v, %v, want the full step output", output, ok)
+	}
+}
+
+func TestOrchestrator_InstanceInfoFromContext(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var seen InstanceInfo
+	workflow, _ := NewWorkflowBuilder("instance-info", "Instance Info").
+		AddStepFunc("create", "Create", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, UpdateWorkflowMetadataFromContext(ctx, map[string]interface{}{"order_ref": "ord-7"})
+		}).
+		AddStepFunc("notify", "Notify", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			info, ok := InstanceInfoFromContext(ctx)
+			if !ok {
+				return nil, fmt.Errorf("missing instance info")
+			}
+			seen = info
+			// The snapshot is read-only: writing to it leaves the instance alone
+			info.Metadata["tenant"] = "changed"
+			return nil, nil
+		}, WithStepDeps("create")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	metadata := map[string]interface{}{"correlation_id": "corr-1", "business_id": "biz-1", "tenant": "acme"}
+	result, err := orchestrator.StartWorkflow(context.Background(), "instance-info", nil, metadata)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	inst := result.WorkflowInst
+	if seen.InstanceID != inst.ID || seen.WorkflowID != "instance-info" || seen.TraceID != inst.TraceID {
+		t.Errorf("InstanceInfoFromContext() = %+v, want instance %s", seen, inst.ID)
+	}
+	if seen.CorrelationID != "corr-1" || seen.BusinessID != "biz-1" {
+		t.Errorf("InstanceInfoFromContext() IDs = %q, %q, want corr-1, biz-1", seen.CorrelationID, seen.BusinessID)
+	}
+	if seen.Metadata["order_ref"] != "ord-7" {
+		t.Errorf("InstanceInfoFromContext() metadata = %v, want metadata added by an earlier step", seen.Metadata)
+	}
+	if inst.Metadata["tenant"] != "acme" {
+		t.Errorf("instance metadata tenant = %v, want acme", inst.Metadata["tenant"])
+	}
+
+	if _, ok := InstanceInfoFromContext(context.Background()); ok {
+		t.Errorf("InstanceInfoFromContext() should be unset outside step execution")
+	}
+}
+
+type txKey struct{}
+
+// txRecordingStateManager marks transactional contexts and records which step updates used one
+type txRecordingStateManager struct {
+	*InMemoryStateManager
+	mu           sync.Mutex
+	transactions int
+	inTx         map[string]bool
+}
+
+func (m *txRecordingStateManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.mu.Lock()
+	m.transactions++
+	m.mu.Unlock()
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+func (m *txRecordingStateManager) record(ctx context.Context, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inTx[name] = ctx.Value(txKey{}) != nil
+}
+
+func (m *txRecordingStateManager) UpdateStepOutput(ctx context.Context, stepInstID string, output map[string]interface{}) error {
+	m.record(ctx, "step_output")
+	return m.InMemoryStateManager.UpdateStepOutput(ctx, stepInstID, output)
+}
+
+func (m *txRecordingStateManager) UpdateWorkflowOutput(ctx context.Context, workflowInstID string, output map[string]interface{}) error {
+	m.record(ctx, "workflow_output")
+	return m.InMemoryStateManager.UpdateWorkflowOutput(ctx, workflowInstID, output)
+}
+
+func TestOrchestrator_StepResultPersistedInTransaction(t *testing.T) {
+	sm := &txRecordingStateManager{InMemoryStateManager: NewInMemoryStateManager(), inTx: make(map[string]bool)}
+	orchestrator := NewOrchestrator(sm)
+
+	workflow, _ := NewWorkflowBuilder("tx-workflow", "Transaction Workflow").
+		AddStepFunc("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"a": 1}, nil
+		}).
+		AddStepFunc("step2", "Step 2", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"b": 2}, nil
+		}, WithStepDeps("step1")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "tx-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	if sm.transactions != 2 {
+		t.Errorf("WithTransaction() calls = %d, want %d", sm.transactions, 2)
+	}
+	for _, name := range []string{"step_output", "workflow_output"} {
+		if !sm.inTx[name] {
+			t.Errorf("%s update should run inside the transaction", name)
+		}
+	}
+
+	stored, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if stored.Output["a"] != 1 || stored.Output["b"] != 2 {
+		t.Errorf("persisted workflow output = %v, want merged step outputs", stored.Output)
+	}
+}
+
+// unreachableStateManager fails health checks
+type unreachableStateManager struct {
+	*InMemoryStateManager
+}
+
+func (m *unreachableStateManager) Ping(ctx context.Context) error {
+	return errPingFailed
+}
+
+var errPingFailed = errors.New("connection refused")
+
+func TestOrchestrator_HealthCheck(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	workflow, _ := NewWorkflowBuilder("health-workflow", "Health Workflow").
+		AddStepFunc("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	if err := orchestrator.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v, want nil", err)
+	}
+	if count := orchestrator.WorkflowCount(); count != 1 {
+		t.Errorf("WorkflowCount() = %d, want %d", count, 1)
+	}
+
+	orchestrator = NewOrchestrator(&unreachableStateManager{NewInMemoryStateManager()})
+	if err := orchestrator.HealthCheck(context.Background()); !errors.Is(err, errPingFailed) {
+		t.Errorf("HealthCheck() error = %v, want %v", err, errPingFailed)
+	}
+}
+
+func TestOrchestrator_StepDeadlineFromInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		deadline     interface{}
+		wantErr      bool
+		wantExecuted bool
+	}{
+		{"future time", time.Now().Add(time.Hour), false, true},
+		{"future RFC3339 string", time.Now().Add(time.Hour).Format(time.RFC3339), false, true},
+		{"already passed", time.Now().Add(-time.Minute), true, false},
+		{"invalid string", "tomorrow", true, false},
+		{"missing", nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+			executed := false
+			var hasDeadline bool
+			workflow, _ := NewWorkflowBuilder("deadline-workflow", "Deadline Workflow").
+				AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					executed = true
+					_, hasDeadline = ctx.Deadline()
+					return nil, nil
+				}, WithStepDeadlineFromInput("ship_by")).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			input := map[string]interface{}{}
+			if tt.deadline != nil {
+				input["ship_by"] = tt.deadline
+			}
+
+			_, err := orchestrator.StartWorkflow(context.Background(), "deadline-workflow", input, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if executed != tt.wantExecuted {
+				t.Errorf("executor ran = %v, want %v", executed, tt.wantExecuted)
+			}
+			if executed && hasDeadline != (tt.deadline != nil) {
+				t.Errorf("executor context deadline set = %v, want %v", hasDeadline, tt.deadline != nil)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_StepWaitTime(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	// Both steps become ready together; the lower priority one waits for the other to finish
+	workflow, _ := NewWorkflowBuilder("wait-workflow", "Wait Workflow").
+		AddStepFunc("first", "First", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			time.Sleep(30 * time.Millisecond)
+			return nil, nil
+		}, WithStepPriority(10)).
+		AddStepFunc("second", "Second", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "wait-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	for _, step := range steps {
+		if step.ReadyAt == nil {
+			t.Errorf("step %s ReadyAt not persisted", step.StepID)
+		}
+		switch step.StepID {
+		case "first":
+			if step.WaitMs >= 30 {
+				t.Errorf("first step WaitMs = %d, want < 30", step.WaitMs)
+			}
+		case "second":
+			if step.WaitMs < 30 {
+				t.Errorf("second step WaitMs = %d, want >= 30", step.WaitMs)
+			}
+		}
+	}
+}
+
+func TestOrchestrator_Finalizer(t *testing.T) {
+	stepErr := errors.New("step failed")
+	finalizerErr := errors.New("unlock failed")
+
+	tests := []struct {
+		name             string
+		stepErr          error
+		finalizerErr     error
+		wantSuccess      bool
+		wantWorkflowErr  error
+		wantFinalizerErr error
+	}{
+		{"runs after success", nil, nil, true, nil, nil},
+		{"runs after failure", stepErr, nil, false, stepErr, nil},
+		{"error does not mask failure", stepErr, finalizerErr, false, stepErr, finalizerErr},
+		{"error surfaced on success", nil, finalizerErr, true, nil, finalizerErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+			var seenErr error
+			var seenInput map[string]interface{}
+			ran := false
+			finalizer, _ := NewStepBuilder("release_lock", "Release Lock", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				ran = true
+				seenErr = WorkflowErrorFromContext(ctx)
+				seenInput = input
+				return nil, tt.finalizerErr
+			}).Build()
+
+			workflow, _ := NewWorkflowBuilder("finalizer-workflow", "Finalizer Workflow").
+				AddStepFunc("work", "Work", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					if tt.stepErr != nil {
+						return nil, tt.stepErr
+					}
+					return map[string]interface{}{"locked": true}, nil
+				}).
+				WithFinalizer(finalizer).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "finalizer-workflow", nil, nil)
+
+			if !ran {
+				t.Fatalf("finalizer did not run")
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("StartWorkflow() success = %v, want %v", result.Success, tt.wantSuccess)
+			}
+			if !errors.Is(err, tt.wantWorkflowErr) || (tt.wantWorkflowErr == nil && err != nil) {
+				t.Errorf("StartWorkflow() error = %v, want %v", err, tt.wantWorkflowErr)
+			}
+			if !errors.Is(seenErr, tt.wantWorkflowErr) || (tt.wantWorkflowErr == nil && seenErr != nil) {
+				t.Errorf("WorkflowErrorFromContext() = %v, want %v", seenErr, tt.wantWorkflowErr)
+			}
+			if !errors.Is(result.FinalizerError, tt.wantFinalizerErr) || (tt.wantFinalizerErr == nil && result.FinalizerError != nil) {
+				t.Errorf("FinalizerError = %v, want %v", result.FinalizerError, tt.wantFinalizerErr)
+			}
+			if tt.stepErr == nil && seenInput["locked"] != true {
+				t.Errorf("finalizer input = %v, want workflow context", seenInput)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_StepSandboxAbandonsBlockedExecutor(t *testing.T) {
+	metrics := newRecordingMetrics()
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithStepSandbox(true), WithMetrics(metrics))
+
+	// The executor blocks on a channel and ignores its context
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("sandbox-workflow", "Sandbox Workflow").
+		AddStepFunc("blocked", "Blocked", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			defer close(finished)
+			<-release
+			return nil, nil
+		}, WithStepTimeout(20*time.Millisecond)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	done := make(chan *WorkflowResult, 1)
+	go func() {
+		result, _ := orchestrator.StartWorkflow(context.Background(), "sandbox-workflow", nil, nil)
+		done <- result
+	}()
+
+	var result *WorkflowResult
+	select {
+	case result = <-done:
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatalf("StartWorkflow() did not return while the executor was blocked")
+	}
+
+	if result.Success {
+		t.Errorf("StartWorkflow() success = true, want false for an abandoned step")
+	}
+	if got := orchestrator.AbandonedSteps(); got != 1 {
+		t.Errorf("AbandonedSteps() = %d, want 1", got)
+	}
+	metrics.mu.Lock()
+	if metrics.counters["step.abandoned"] != 1 {
+		t.Errorf("step.abandoned counter = %d, want 1", metrics.counters["step.abandoned"])
+	}
+	metrics.mu.Unlock()
+
+	close(release)
+	<-finished
+	deadline := time.Now().Add(time.Second)
+	for orchestrator.AbandonedSteps() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := orchestrator.AbandonedSteps(); got != 0 {
+		t.Errorf("AbandonedSteps() after executor returned = %d, want 0", got)
+	}
+}
+
+func TestOrchestrator_GateStep(t *testing.T) {
+	build := func(gateErr error, ran *sync.Map) *WorkflowDefinition {
+		record := func(id string) StepExecutor {
+			return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				ran.Store(id, true)
+				return map[string]interface{}{}, nil
+			}
+		}
+		gate, _ := NewStepBuilder("validate", "Validate", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			ran.Store("validate", true)
+			return map[string]interface{}{}, gateErr
+		}).Build()
+
+		workflow, _ := NewWorkflowBuilder("gated", "Gated").
+			AddStepFunc("notify", "Notify", record("notify"), WithStepAsync(true)).
+			AddStepFunc("charge", "Charge", record("charge")).
+			AddStepFunc("ship", "Ship", record("ship"), WithStepDeps("charge")).
+			WithGateStep(gate).
+			Build()
+		return workflow
+	}
+
+	t.Run("failure stops every other step", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var ran sync.Map
+		orchestrator.RegisterWorkflow(build(errors.New("invalid payment"), &ran))
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "gated", nil, nil)
+		if err == nil || result.Success {
+			t.Fatalf("StartWorkflow() should fail when the gate fails")
+		}
+		for _, id := range []string{"notify", "charge", "ship"} {
+			if _, ok := ran.Load(id); ok {
+				t.Errorf("step %s ran after the gate failed", id)
+			}
+		}
+	})
+
+	t.Run("success lets the workflow run", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var ran sync.Map
+		orchestrator.RegisterWorkflow(build(nil, &ran))
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "gated", nil, nil)
+		if err != nil || !result.Success {
+			t.Fatalf("StartWorkflow() error = %v", err)
+		}
+		for _, id := range []string{"validate", "notify", "charge", "ship"} {
+			if _, ok := ran.Load(id); !ok {
+				t.Errorf("step %s did not run", id)
+			}
+		}
+	})
+}
+
+func TestOrchestrator_DynamicDependencies(t *testing.T) {
+	newWorkflow := func(resolve DependencyResolver, order *[]string) *WorkflowDefinition {
+		record := func(id string) StepExecutor {
+			return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				*order = append(*order, id)
+				return map[string]interface{}{}, nil
+			}
+		}
+		workflow, _ := NewWorkflowBuilder("trip", "Trip").
+			AddStepFunc("flight", "Flight", record("flight")).
+			AddStepFunc("hotel", "Hotel", record("hotel"), WithStepPriority(1), WithStepDynamicDependencies(resolve)).
+			Build()
+		return workflow
+	}
+	afterFlight := func(input map[string]interface{}) []string {
+		if noFlight, _ := input["no_flight"].(bool); noFlight {
+			return nil
+		}
+		return []string{"flight"}
+	}
+
+	tests := []struct {
+		name      string
+		input     map[string]interface{}
+		wantOrder []string
+	}{
+		{"resolved dependency orders the steps", map[string]interface{}{}, []string{"flight", "hotel"}},
+		// Without the dependency both share a wave and the higher priority hotel goes first
+		{"input removes the dependency", map[string]interface{}{"no_flight": true}, []string{"hotel", "flight"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+			var order []string
+			orchestrator.RegisterWorkflow(newWorkflow(afterFlight, &order))
+
+			if _, err := orchestrator.StartWorkflow(context.Background(), "trip", tt.input, nil); err != nil {
+				t.Fatalf("StartWorkflow() error = %v", err)
+			}
+			if fmt.Sprint(order) != fmt.Sprint(tt.wantOrder) {
+				t.Errorf("steps ran in order %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+
+	t.Run("unknown resolved dependency fails the workflow", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var order []string
+		orchestrator.RegisterWorkflow(newWorkflow(func(input map[string]interface{}) []string {
+			return []string{"train"}
+		}, &order))
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "trip", nil, nil)
+		if !errors.Is(err, ErrInvalidDependency) {
+			t.Fatalf("StartWorkflow() error = %v, want %v", err, ErrInvalidDependency)
+		}
+		if result == nil || result.WorkflowInst.Status != WorkflowStatusFailed {
+			t.Errorf("workflow should be marked failed, got %+v", result)
+		}
+		if len(order) != 0 {
+			t.Errorf("steps %v ran despite the invalid dependency", order)
+		}
+	})
+}
+
+func TestOrchestrator_EarlySuccess(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	var called []string
+	provider := func(name string, fail bool) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			called = append(called, name)
+			if fail {
+				return nil, errors.New(name + " unavailable")
+			}
+			return map[string]interface{}{"provider": name}, nil
+		}
+	}
+
+	// Try provider A, else B, else C; the first that succeeds completes the workflow
+	workflow, _ := NewWorkflowBuilder("fallback", "Fallback").
+		AddStepFunc("provider_a", "Provider A", provider("a", true), WithStepRequired(false)).
+		AddStepFunc("provider_b", "Provider B", provider("b", false), WithStepRequired(false), WithStepDeps("provider_a")).
+		AddStepFunc("provider_c", "Provider C", provider("c", false), WithStepRequired(false), WithStepDeps("provider_b")).
+		AddStepFunc("notify", "Notify", provider("notify", false), WithStepDeps("provider_c")).
+		WithSuccessStep("provider_a").
+		WithSuccessStep("provider_b").
+		WithSuccessStep("provider_c").
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "fallback", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if !result.Success || result.WorkflowInst.Status != WorkflowStatusCompleted {
+		t.Errorf("StartWorkflow() success = %v, status = %v, want completed", result.Success, result.WorkflowInst.Status)
+	}
+	if result.Output["provider"] != "b" {
+		t.Errorf("output provider = %v, want b", result.Output["provider"])
+	}
+	if len(called) != 2 {
+		t.Errorf("called = %v, want only providers a and b", called)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	for _, step := range steps {
+		switch step.StepID {
+		case "provider_c", "notify":
+			if step.Status != StepStatusSkipped || step.SkipReason != SkipReasonEarlySuccess {
+				t.Errorf("step %s = %v/%v, want skipped/%v", step.StepID, step.Status, step.SkipReason, SkipReasonEarlySuccess)
+			}
+		case "provider_b":
+			if step.Status != StepStatusCompleted {
+				t.Errorf("step %s status = %v, want %v", step.StepID, step.Status, StepStatusCompleted)
+			}
+		}
+	}
+}
+
+func TestOrchestrator_Alternatives(t *testing.T) {
+	provider := func(name string, fail bool, calls *[]string) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			*calls = append(*calls, name)
+			if fail {
+				return nil, errors.New(name + " unavailable")
+			}
+			return map[string]interface{}{"sent_by": name}, nil
+		}
+	}
+
+	t.Run("first success wins", func(t *testing.T) {
+		sm := NewInMemoryStateManager()
+		orchestrator := NewOrchestrator(sm)
+
+		var calls []string
+		var receipt interface{}
+		a, _ := NewStepBuilder("provider_a", "Provider A", provider("a", true, &calls)).Build()
+		b, _ := NewStepBuilder("provider_b", "Provider B", provider("b", false, &calls)).Build()
+		c, _ := NewStepBuilder("provider_c", "Provider C", provider("c", false, &calls)).Build()
+		workflow, _ := NewWorkflowBuilder("alternatives", "Alternatives").
+			AddAlternatives("send", a, b, c).
+			AddStepFunc("record", "Record", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				receipt = input["send"]
+				return nil, nil
+			}, WithStepDeps("send")).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "alternatives", nil, nil)
+		if err != nil || !result.Success {
+			t.Fatalf("StartWorkflow() success = %v, err = %v", result.Success, err)
+		}
+		if len(calls) != 2 || calls[1] != "b" {
+			t.Errorf("calls = %v, want [a b]", calls)
+		}
+		if group, ok := receipt.(map[string]interface{}); !ok || group["sent_by"] != "b" {
+			t.Errorf("dependent saw group output %v, want sent_by=b", receipt)
+		}
+
+		steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+		statuses := make(map[string]StepStatus)
+		for _, step := range steps {
+			statuses[step.StepID] = step.Status
+			if step.StepID == "provider_c" && step.SkipReason != SkipReasonAlternativeWon {
+				t.Errorf("provider_c skip reason = %v, want %v", step.SkipReason, SkipReasonAlternativeWon)
+			}
+		}
+		want := map[string]StepStatus{
+			"send":       StepStatusCompleted,
+			"provider_a": StepStatusFailed,
+			"provider_b": StepStatusCompleted,
+			"provider_c": StepStatusSkipped,
+			"record":     StepStatusCompleted,
+		}
+		for id, status := range want {
+			if statuses[id] != status {
+				t.Errorf("step %s status = %v, want %v", id, statuses[id], status)
+			}
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		var calls []string
+		a, _ := NewStepBuilder("provider_a", "Provider A", provider("a", true, &calls)).Build()
+		b, _ := NewStepBuilder("provider_b", "Provider B", provider("b", true, &calls)).Build()
+
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		workflow, _ := NewWorkflowBuilder("required", "Required").AddAlternatives("send", a, b).Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		if _, err := orchestrator.StartWorkflow(context.Background(), "required", nil, nil); err == nil {
+			t.Errorf("StartWorkflow() should fail when every required alternative fails")
+		}
+
+		optA, _ := NewStepBuilder("provider_a", "Provider A", provider("a", true, &calls)).WithRequired(false).Build()
+		optB, _ := NewStepBuilder("provider_b", "Provider B", provider("b", true, &calls)).WithRequired(false).Build()
+		workflow, _ = NewWorkflowBuilder("optional", "Optional").AddAlternatives("send", optA, optB).Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "optional", nil, nil)
+		if err != nil || !result.Success {
+			t.Errorf("StartWorkflow() with optional alternatives success = %v, err = %v", result.Success, err)
+		}
+	})
+}
+
+func TestOrchestrator_UpdateWorkflowMetadataFromStep(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	workflow, _ := NewWorkflowBuilder("metadata-workflow", "Metadata Workflow").
+		AddStepFunc("create_order", "Create Order", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, UpdateWorkflowMetadataFromContext(ctx, map[string]interface{}{"order_ref": "ORD-42"})
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "metadata-workflow", nil,
+		map[string]interface{}{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	saved, _ := orchestrator.GetWorkflowStatus(context.Background(), result.WorkflowInst.ID)
+	if saved.Metadata["order_ref"] != "ORD-42" || saved.Metadata["tenant"] != "acme" {
+		t.Errorf("stored metadata = %v, want order_ref merged with tenant", saved.Metadata)
+	}
+	if result.WorkflowInst.Metadata["order_ref"] != "ORD-42" {
+		t.Errorf("running instance metadata = %v, want order_ref", result.WorkflowInst.Metadata)
+	}
+
+	if err := UpdateWorkflowMetadataFromContext(context.Background(), nil); !errors.Is(err, ErrNoWorkflowContext) {
+		t.Errorf("UpdateWorkflowMetadataFromContext() outside a step error = %v, want ErrNoWorkflowContext", err)
+	}
+}
+
+func TestOrchestrator_OverlapAsync(t *testing.T) {
+	sleeping := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		time.Sleep(60 * time.Millisecond)
+		return map[string]interface{}{}, nil
+	}
+
+	run := func(t *testing.T, overlap bool) time.Duration {
+		sm := NewInMemoryStateManager()
+		orchestrator := NewOrchestrator(sm)
+
+		workflow, err := NewWorkflowBuilder("overlap", "Overlap").
+			WithOverlapAsync(overlap).
+			AddStepFunc("sync-a", "Sync A", sleeping).
+			AddStepFunc("sync-b", "Sync B", sleeping).
+			AddStepFunc("async", "Async", sleeping, WithStepAsync(true)).
+			Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		if _, err := orchestrator.StartWorkflow(context.Background(), "overlap", nil, nil); err != nil {
+			t.Fatalf("StartWorkflow() error = %v", err)
+		}
+		return time.Since(start)
+	}
+
+	// Sequential waves take sync_total + async_max, overlapping ones max(sync_total, async_max)
+	if elapsed := run(t, false); elapsed < 180*time.Millisecond {
+		t.Errorf("sequential wave took %v, want at least 180ms", elapsed)
+	}
+	if elapsed := run(t, true); elapsed >= 180*time.Millisecond {
+		t.Errorf("overlapping wave took %v, want under 180ms", elapsed)
+	}
+}
+
+func TestOrchestrator_DefaultExecutorTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		stepTimeout time.Duration
+		callerLimit time.Duration
+		wantDefault bool
+	}{
+		{"applied without explicit timeouts", 0, 0, true},
+		{"step timeout takes precedence", 5 * time.Second, 0, false},
+		{"caller deadline takes precedence", 0, 5 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithDefaultExecutorTimeout(100*time.Millisecond))
+
+			var remaining time.Duration
+			opts := []StepOption{}
+			if tt.stepTimeout > 0 {
+				opts = append(opts, WithStepTimeout(tt.stepTimeout))
+			}
+			workflow, _ := NewWorkflowBuilder("default-timeout", "Default Timeout").
+				AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					deadline, ok := ctx.Deadline()
+					if !ok {
+						return nil, errors.New("executor context has no deadline")
+					}
+					remaining = time.Until(deadline)
+					return map[string]interface{}{}, nil
+				}, opts...).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			ctx := context.Background()
+			if tt.callerLimit > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerLimit)
+				defer cancel()
+			}
+			if _, err := orchestrator.StartWorkflow(ctx, "default-timeout", nil, nil); err != nil {
+				t.Fatalf("StartWorkflow() error = %v", err)
+			}
+
+			if usedDefault := remaining <= 100*time.Millisecond; usedDefault != tt.wantDefault {
+				t.Errorf("executor deadline in %v, want default timeout applied = %v", remaining, tt.wantDefault)
+			}
+		})
+	}
+
+	t.Run("stops an unbounded executor", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithDefaultExecutorTimeout(20*time.Millisecond))
+		workflow, _ := NewWorkflowBuilder("unbounded", "Unbounded").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		result, err := orchestrator.StartWorkflow(context.Background(), "unbounded", nil, nil)
+		if err == nil || result.Success {
+			t.Fatalf("StartWorkflow() should fail once the default timeout expires")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("StartWorkflow() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestOrchestrator_CleanupEvents(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	workflow, _ := NewWorkflowBuilder("events", "Events").
+		AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "events", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	instanceID := result.WorkflowInst.ID
+
+	// Events from the run are within a week's retention
+	if deleted, err := orchestrator.CleanupEvents(context.Background(), 7*24*time.Hour); err != nil || deleted != 0 {
+		t.Errorf("CleanupEvents(week) = %d, %v, want 0, nil", deleted, err)
+	}
+
+	deleted, err := orchestrator.CleanupEvents(context.Background(), -time.Minute)
+	if err != nil {
+		t.Fatalf("CleanupEvents() error = %v", err)
+	}
+	if deleted == 0 {
+		t.Errorf("CleanupEvents() deleted no events")
+	}
+	if events, _ := sm.GetWorkflowEvents(context.Background(), instanceID); len(events) != 0 {
+		t.Errorf("GetWorkflowEvents() returned %d events after cleanup, want 0", len(events))
+	}
+	if _, err := orchestrator.GetWorkflowStatus(context.Background(), instanceID); err != nil {
+		t.Errorf("GetWorkflowStatus() error = %v, want instance kept", err)
+	}
+}
+
+func TestOrchestrator_LifecycleEvents(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	workflow, _ := NewWorkflowBuilder("lifecycle", "Lifecycle").
+		AddStepFunc("book", "Book", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}, WithStepCompensator(func(ctx context.Context, input map[string]interface{}) error {
+			return nil
+		})).
+		AddStepFunc("slow", "Slow", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, WithStepDeps("book"), WithStepTimeout(20*time.Millisecond)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "lifecycle", nil, nil)
+	if err == nil {
+		t.Fatal("StartWorkflow() should fail once the slow step times out")
+	}
+
+	// Resuming a finished instance returns early; resume a running copy instead
+	running := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "lifecycle", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+	sm.SaveWorkflow(context.Background(), running)
+	orchestrator.ResumeWorkflow(context.Background(), running.ID)
+
+	payloads := make(map[string]map[string]interface{})
+	for _, id := range []string{result.WorkflowInst.ID, running.ID} {
+		events, _ := sm.GetWorkflowEvents(context.Background(), id)
+		for _, event := range events {
+			payloads[event.EventType] = event.EventData
+		}
+	}
+
+	if data := payloads[EventStepTimeout]; data == nil || data["step_id"] != "slow" || data["attempt"] != 1 {
+		t.Errorf("%s payload = %v, want step slow on attempt 1", EventStepTimeout, data)
+	}
+	if data := payloads[EventStepCompensated]; data == nil || data["step_id"] != "book" {
+		t.Errorf("%s payload = %v, want step book", EventStepCompensated, data)
+	}
+	if data := payloads[EventWorkflowResumed]; data == nil || data["status"] != string(WorkflowStatusRunning) || data["retries"] != string(ContinueRetries) {
+		t.Errorf("%s payload = %v, want previous status and retry accounting", EventWorkflowResumed, data)
+	}
+}
+
+func TestOrchestrator_TimeoutIsBudgetAcrossRetries(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+		Multiplier:      1,
+	}
+
+	t.Run("stops retrying once the budget is spent", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var runs atomic.Int32 // The executor may still be returning when the step gives up
+		workflow, _ := NewWorkflowBuilder("budget", "Budget").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs.Add(1)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}, WithStepRetryPolicy(policy), WithStepTimeout(50*time.Millisecond)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		_, err := orchestrator.StartWorkflow(context.Background(), "budget", nil, nil)
+		if err == nil {
+			t.Fatal("StartWorkflow() should fail once the step budget is spent")
+		}
+		if !strings.Contains(err.Error(), "budget") || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("StartWorkflow() error = %v, want budget exceeded wrapping %v", err, context.DeadlineExceeded)
+		}
+		if runs.Load() != 1 {
+			t.Errorf("executor ran %d times, want 1", runs.Load())
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("step took %v, want it bounded by its budget", elapsed)
+		}
+	})
+
+	t.Run("a backoff longer than the remaining budget is not waited out", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		runs := 0
+		workflow, _ := NewWorkflowBuilder("long-backoff", "Long Backoff").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs++
+				return nil, errors.New("transient failure")
+			}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialInterval: 5 * time.Second, MaxInterval: 5 * time.Second, Multiplier: 1}),
+				WithStepTimeout(100*time.Millisecond)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		_, err := orchestrator.StartWorkflow(context.Background(), "long-backoff", nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "budget") {
+			t.Errorf("StartWorkflow() error = %v, want the budget exceeded", err)
+		}
+		if runs != 1 {
+			t.Errorf("executor ran %d times, want 1", runs)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("step took %v, want it to give up instead of waiting out the backoff", elapsed)
+		}
+	})
+
+	t.Run("fast failures retry within the budget", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		runs := 0
+		workflow, _ := NewWorkflowBuilder("within-budget", "Within Budget").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs++
+				return nil, errors.New("transient failure")
+			}, WithStepRetryPolicy(policy), WithStepTimeout(time.Second)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		_, err := orchestrator.StartWorkflow(context.Background(), "within-budget", nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "failed after 5 attempts") {
+			t.Errorf("StartWorkflow() error = %v, want failure after 5 attempts", err)
+		}
+		if runs != policy.MaxAttempts {
+			t.Errorf("executor ran %d times, want %d", runs, policy.MaxAttempts)
+		}
+	})
+
+	t.Run("an injected clock spends the budget", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithClock(clock))
+		runs := 0
+		workflow, _ := NewWorkflowBuilder("clocked-budget", "Clocked Budget").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs++
+				return nil, errors.New("transient failure")
+			}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 5, InitialInterval: 400 * time.Millisecond, MaxInterval: 400 * time.Millisecond, Multiplier: 1}),
+				WithStepTimeout(time.Second)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		// The third backoff takes the fake time past the one-second budget
+		_, err := orchestrator.StartWorkflow(context.Background(), "clocked-budget", nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "budget") {
+			t.Errorf("StartWorkflow() error = %v, want the budget exceeded", err)
+		}
+		if runs != 3 {
+			t.Errorf("executor ran %d times, want 3", runs)
+		}
+	})
+}
+
+func TestOrchestrator_MaxDurationAcrossRetries(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:     10,
+		InitialInterval: 20 * time.Millisecond,
+		MaxInterval:     20 * time.Millisecond,
+		Multiplier:      1,
+	}
+
+	t.Run("no retry starts past the cap", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var runs atomic.Int32
+		workflow, _ := NewWorkflowBuilder("capped", "Capped").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs.Add(1)
+				time.Sleep(30 * time.Millisecond)
+				return nil, errors.New("transient failure")
+			}, WithStepRetryPolicy(policy), WithStepMaxDuration(150*time.Millisecond)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		_, err := orchestrator.StartWorkflow(context.Background(), "capped", nil, nil)
+		if !errors.Is(err, ErrMaxDurationExceeded) || !strings.Contains(err.Error(), "transient failure") {
+			t.Errorf("StartWorkflow() error = %v, want %v wrapping the last attempt's error", err, ErrMaxDurationExceeded)
+		}
+		// Ten attempts with backoff would take about 480ms
+		if n := runs.Load(); n < 2 || n >= int32(policy.MaxAttempts) {
+			t.Errorf("executor ran %d times, want a few retries cut short by the cap", n)
+		}
+		if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+			t.Errorf("step took %v, want it bounded by its max duration", elapsed)
+		}
+	})
+
+	t.Run("cancels the attempt running when the cap runs out", func(t *testing.T) {
+		orchestrator := NewOrchestrator(NewInMemoryStateManager())
+		var runs atomic.Int32
+		workflow, _ := NewWorkflowBuilder("capped-attempt", "Capped Attempt").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				if runs.Add(1) == 1 {
+					return nil, errors.New("transient failure")
+				}
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}, WithStepRetryPolicy(policy), WithStepMaxDuration(100*time.Millisecond)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		start := time.Now()
+		_, err := orchestrator.StartWorkflow(context.Background(), "capped-attempt", nil, nil)
+		if !errors.Is(err, ErrMaxDurationExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("StartWorkflow() error = %v, want %v wrapping %v", err, ErrMaxDurationExceeded, context.DeadlineExceeded)
+		}
+		if n := runs.Load(); n != 2 {
+			t.Errorf("executor ran %d times, want 2", n)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("step took %v, want it bounded by its max duration", elapsed)
+		}
+	})
+
+	t.Run("an injected clock reaches the cap", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithClock(clock))
+		runs := 0
+		workflow, _ := NewWorkflowBuilder("clocked-cap", "Clocked Cap").
+			AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+				runs++
+				return nil, errors.New("transient failure")
+			}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 10, InitialInterval: 400 * time.Millisecond, MaxInterval: 400 * time.Millisecond, Multiplier: 1}),
+				WithStepMaxDuration(time.Second)).
+			Build()
+		orchestrator.RegisterWorkflow(workflow)
+
+		// After two backoffs, a third would end at 1.2s, past the one-second cap
+		_, err := orchestrator.StartWorkflow(context.Background(), "clocked-cap", nil, nil)
+		if !errors.Is(err, ErrMaxDurationExceeded) {
+			t.Errorf("StartWorkflow() error = %v, want %v", err, ErrMaxDurationExceeded)
+		}
+		if runs != 3 {
+			t.Errorf("executor ran %d times, want 3", runs)
+		}
+	})
+}
+
+func TestOrchestrator_RetryableOutput(t *testing.T) {
+	retryUntil := func(succeedOn int, runs *int) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			*runs++
+			return map[string]interface{}{"retry": *runs < succeedOn, "attempt": *runs}, nil
+		}
+	}
+	wantsRetry := func(output map[string]interface{}) bool {
+		retry, _ := output["retry"].(bool)
+		return retry
+	}
+	policy := NewRetryPolicyBuilder().WithMaxAttempts(3).WithInitialInterval(time.Millisecond).MustBuild()
+
+	tests := []struct {
+		name        string
+		succeedOn   int
+		wantRuns    int
+		wantAttempt int
+	}{
+		{"retries until the output is final", 2, 2, 2},
+		{"keeps the last output once attempts run out", 10, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator(NewInMemoryStateManager())
+			runs := 0
+			workflow, _ := NewWorkflowBuilder("soft-retry", "Soft Retry").
+				AddStepFunc("poll", "Poll", retryUntil(tt.succeedOn, &runs),
+					WithStepRetryPolicy(policy), WithStepRetryableOutput(wantsRetry)).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "soft-retry", nil, nil)
+			if err != nil {
+				t.Fatalf("StartWorkflow() error = %v", err)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("executor ran %d times, want %d", runs, tt.wantRuns)
+			}
+			output, _ := result.StepOutput("poll")
+			if output["attempt"] != tt.wantAttempt {
+				t.Errorf("step output attempt = %v, want %v", output["attempt"], tt.wantAttempt)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_FailureKind(t *testing.T) {
+	failing := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("permanent failure")
+	}
+	blocking := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	retries := &RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, Multiplier: 1}
+
+	tests := []struct {
+		name     string
+		executor StepExecutor
+		opts     []StepOption
+		input    map[string]interface{}
+		cancel   bool // Cancel the caller's context while the step runs
+		want     FailureKind
+	}{
+		{"error on every attempt", failing, []StepOption{WithStepRetryPolicy(retries)}, nil, false, FailureKindExhaustedRetries},
+		{"timeout", blocking, []StepOption{WithStepTimeout(10 * time.Millisecond)}, nil, false, FailureKindTimeout},
+		{"max duration", blocking, []StepOption{WithStepRetryPolicy(retries), WithStepMaxDuration(10 * time.Millisecond)}, nil, false, FailureKindMaxDuration},
+		{"invalid deadline", failing, []StepOption{func(b *StepBuilder) { b.WithDeadlineFromInput("due") }}, map[string]interface{}{"due": "tomorrow"}, false, FailureKindValidation},
+		{"cancelled", blocking, []StepOption{WithStepRetryPolicy(retries)}, nil, true, FailureKindCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewInMemoryStateManager()
+			orchestrator := NewOrchestrator(sm)
+			workflow, _ := NewWorkflowBuilder("classified", "Classified").
+				AddStepFunc("step", "Step", tt.executor, tt.opts...).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+
+			result, err := orchestrator.StartWorkflow(ctx, "classified", tt.input, nil)
+			if err == nil {
+				t.Fatal("StartWorkflow() should fail")
+			}
+			if kind := result.WorkflowInst.Steps[0].FailureKind; kind != tt.want {
+				t.Errorf("result failure kind = %q, want %q", kind, tt.want)
+			}
+
+			// The kind is persisted and reported with the failure event
+			steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+			if steps[0].FailureKind != tt.want {
+				t.Errorf("stored failure kind = %q, want %q", steps[0].FailureKind, tt.want)
+			}
+			events, _ := sm.GetWorkflowEvents(context.Background(), result.WorkflowInst.ID)
+			for _, event := range events {
+				if event.EventType == EventStepFailed && event.EventData["failure_kind"] != string(tt.want) {
+					t.Errorf("%s failure_kind = %v, want %q", EventStepFailed, event.EventData["failure_kind"], tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_InputInterceptorRunsEveryAttempt(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+	policy := NewRetryPolicyBuilder().WithMaxAttempts(4).WithInitialInterval(time.Millisecond).MustBuild()
+
+	fetches := 0
+	var tokens []interface{}
+	interceptor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		fetches++
+		if fetches == 1 {
+			return nil, errors.New("secret store unavailable")
+		}
+		input["token"] = fmt.Sprintf("token-%d", fetches)
+		return input, nil
+	}
+	workflow, _ := NewWorkflowBuilder("intercept", "Intercept").
+		AddStepFunc("call", "Call API", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			tokens = append(tokens, input["token"])
+			if len(tokens) < 2 {
+				return nil, errors.New("token expired")
+			}
+			return map[string]interface{}{"used": input["token"], "order": input["order_id"]}, nil
+		}, WithStepRetryPolicy(policy), WithStepInputInterceptor(interceptor)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "intercept", map[string]interface{}{"order_id": "o1"}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// The first interceptor error fails an attempt, which is retried
+	if fetches != 3 {
+		t.Errorf("interceptor ran %d times, want once per attempt (3)", fetches)
+	}
+	if !reflect.DeepEqual(tokens, []interface{}{"token-2", "token-3"}) {
+		t.Errorf("executor saw tokens %v, want a fresh one per attempt", tokens)
+	}
+	output, _ := result.StepOutput("call")
+	if output["used"] != "token-3" || output["order"] != "o1" {
+		t.Errorf("step output = %v, want the last token and the prepared input", output)
+	}
+
+	// The intercepted value is not persisted with the step input
+	steps, _ := orchestrator.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	if _, ok := steps[0].Input["token"]; ok {
+		t.Errorf("persisted step input = %v, want it without the intercepted token", steps[0].Input)
+	}
+}
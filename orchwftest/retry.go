@@ -0,0 +1,143 @@
+// Package orchwftest provides helpers for testing orchwf workflows
+package orchwftest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/refactorroom/orchwf"
+)
+
+// RetryScenarioResult describes what happened in a retry scenario
+type RetryScenarioResult struct {
+	Attempts  int             // Number of times the executor ran
+	Intervals []time.Duration // Backoff waits requested before each retry
+	Err       error           // Error returned by the workflow, if any
+}
+
+// FakeClock is a Clock that records sleeps instead of waiting
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewFakeClock creates a fake clock starting at the given time
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the fake time and records the duration
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+}
+
+// Sleeps returns the recorded sleep durations
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+// RunRetryScenario runs a single-step workflow whose executor fails on attempt i when
+// failPattern[i] is true (attempts beyond the pattern succeed). It asserts the attempt
+// count and the backoff intervals expected from policy, and returns what was observed.
+func RunRetryScenario(t testing.TB, policy *orchwf.RetryPolicy, failPattern []bool) RetryScenarioResult {
+	t.Helper()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	orchestrator := orchwf.NewOrchestrator(orchwf.NewInMemoryStateManager(), orchwf.WithClock(clock))
+
+	attempts := 0
+	step, err := orchwf.NewStepBuilder("step", "Retry Scenario Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		attempt := attempts
+		attempts++
+		if attempt < len(failPattern) && failPattern[attempt] {
+			return nil, fmt.Errorf("scenario failure on attempt %d", attempt+1)
+		}
+		return map[string]interface{}{"attempt": attempt + 1}, nil
+	}).WithRetryPolicy(policy).Build()
+	if err != nil {
+		t.Fatalf("RunRetryScenario: failed to build step: %v", err)
+	}
+
+	workflow, err := orchwf.NewWorkflowBuilder("retry-scenario", "Retry Scenario").AddStep(step).Build()
+	if err != nil {
+		t.Fatalf("RunRetryScenario: failed to build workflow: %v", err)
+	}
+	if err := orchestrator.RegisterWorkflow(workflow); err != nil {
+		t.Fatalf("RunRetryScenario: failed to register workflow: %v", err)
+	}
+
+	_, runErr := orchestrator.StartWorkflow(context.Background(), "retry-scenario", nil, nil)
+	result := RetryScenarioResult{
+		Attempts:  attempts,
+		Intervals: clock.Sleeps(),
+		Err:       runErr,
+	}
+
+	wantAttempts, wantSuccess := expectedAttempts(policy, failPattern)
+	if result.Attempts != wantAttempts {
+		t.Errorf("RunRetryScenario: attempts = %d, want %d", result.Attempts, wantAttempts)
+	}
+	if (runErr == nil) != wantSuccess {
+		t.Errorf("RunRetryScenario: workflow error = %v, want success %v", runErr, wantSuccess)
+	}
+
+	wantIntervals := ExpectedIntervals(policy, wantAttempts-1)
+	if len(result.Intervals) != len(wantIntervals) {
+		t.Errorf("RunRetryScenario: backoff intervals = %v, want %v", result.Intervals, wantIntervals)
+	} else {
+		for i := range wantIntervals {
+			if result.Intervals[i] != wantIntervals[i] {
+				t.Errorf("RunRetryScenario: backoff interval %d = %v, want %v", i+1, result.Intervals[i], wantIntervals[i])
+			}
+		}
+	}
+
+	return result
+}
+
+// ExpectedIntervals returns the backoff waits policy should produce before each of the
+// given number of retries: InitialInterval * Multiplier^(n-1), capped at MaxInterval
+func ExpectedIntervals(policy *orchwf.RetryPolicy, retries int) []time.Duration {
+	intervals := make([]time.Duration, 0, retries)
+	interval := float64(policy.InitialInterval)
+	for i := 0; i < retries; i++ {
+		d := time.Duration(interval)
+		if policy.MaxInterval > 0 && d > policy.MaxInterval {
+			d = policy.MaxInterval
+		}
+		intervals = append(intervals, d)
+		interval *= policy.Multiplier
+	}
+	return intervals
+}
+
+// expectedAttempts returns how many attempts the pattern should take under policy and
+// whether the step should eventually succeed
+func expectedAttempts(policy *orchwf.RetryPolicy, failPattern []bool) (int, bool) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt >= len(failPattern) || !failPattern[attempt] {
+			return attempt + 1, true
+		}
+	}
+	return maxAttempts, false
+}
@@ -0,0 +1,63 @@
+package orchwftest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/refactorroom/orchwf"
+)
+
+func TestRunRetryScenario(t *testing.T) {
+	policy := orchwf.NewRetryPolicyBuilder().
+		WithMaxAttempts(4).
+		WithInitialInterval(100 * time.Millisecond).
+		WithMaxInterval(300 * time.Millisecond).
+		WithMultiplier(2).
+		MustBuild()
+
+	tests := []struct {
+		name          string
+		failPattern   []bool
+		wantAttempts  int
+		wantIntervals []time.Duration
+		wantErr       bool
+	}{
+		{"succeeds first time", nil, 1, nil, false},
+		{"succeeds on third attempt", []bool{true, true, false}, 3, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, false},
+		{"exhausts attempts", []bool{true, true, true, true}, 4, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RunRetryScenario(t, policy, tt.failPattern)
+
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if len(result.Intervals) != len(tt.wantIntervals) {
+				t.Fatalf("Intervals = %v, want %v", result.Intervals, tt.wantIntervals)
+			}
+			for i, want := range tt.wantIntervals {
+				if result.Intervals[i] != want {
+					t.Errorf("Intervals[%d] = %v, want %v", i, result.Intervals[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	clock.Sleep(time.Second)
+
+	if got := clock.Now(); !got.Equal(start.Add(time.Second)) {
+		t.Errorf("Now() = %v, want %v", got, start.Add(time.Second))
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 1 || sleeps[0] != time.Second {
+		t.Errorf("Sleeps() = %v, want [1s]", sleeps)
+	}
+}
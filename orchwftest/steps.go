@@ -0,0 +1,61 @@
+package orchwftest
+
+import (
+	"context"
+	"time"
+
+	"github.com/refactorroom/orchwf"
+)
+
+// Echo is an executor that returns a copy of its input as output
+func Echo(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	output := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		output[k] = v
+	}
+	return output, nil
+}
+
+// Noop is an executor that does nothing and returns an empty output
+func Noop(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+// Failing returns an executor that always fails with err
+func Failing(err error) orchwf.StepExecutor {
+	return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, err
+	}
+}
+
+// FixedDelay returns an executor that waits for d, or until its context is done
+func FixedDelay(d time.Duration) orchwf.StepExecutor {
+	return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		select {
+		case <-time.After(d):
+			return map[string]interface{}{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// EchoStep returns a step builder for a step that echoes its input
+func EchoStep(id, name string) *orchwf.StepBuilder {
+	return orchwf.NewStepBuilder(id, name, Echo)
+}
+
+// NoopStep returns a step builder for a step that does nothing
+func NoopStep(id, name string) *orchwf.StepBuilder {
+	return orchwf.NewStepBuilder(id, name, Noop)
+}
+
+// FailingStep returns a step builder for a step that always fails with err
+func FailingStep(id, name string, err error) *orchwf.StepBuilder {
+	return orchwf.NewStepBuilder(id, name, Failing(err))
+}
+
+// FixedDelayStep returns a step builder for a step that waits for d
+func FixedDelayStep(id, name string, d time.Duration) *orchwf.StepBuilder {
+	return orchwf.NewStepBuilder(id, name, FixedDelay(d))
+}
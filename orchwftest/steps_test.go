@@ -0,0 +1,56 @@
+package orchwftest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/refactorroom/orchwf"
+)
+
+func TestStepFixtures(t *testing.T) {
+	echo, _ := EchoStep("echo", "Echo").Build()
+	noop, _ := NoopStep("noop", "Noop").WithDependencies("echo").Build()
+	slow, _ := FixedDelayStep("slow", "Slow", 10*time.Millisecond).WithDependencies("noop").Build()
+
+	workflow, err := orchwf.NewWorkflowBuilder("fixtures", "Fixtures").
+		AddStep(echo).
+		AddStep(noop).
+		AddStep(slow).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	orchestrator := orchwf.NewOrchestrator(orchwf.NewInMemoryStateManager())
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "fixtures", map[string]interface{}{"greeting": "hi"}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if output, _ := result.StepOutput("echo"); output["greeting"] != "hi" {
+		t.Errorf("echo output = %v, want greeting echoed", output)
+	}
+	if result.Duration < 10*time.Millisecond {
+		t.Errorf("Duration = %v, want at least the fixed delay", result.Duration)
+	}
+}
+
+func TestFailingStep(t *testing.T) {
+	boom := errors.New("boom")
+	step, _ := FailingStep("fail", "Fail", boom).Build()
+
+	if _, err := step.Executor(context.Background(), nil); !errors.Is(err, boom) {
+		t.Errorf("Executor() error = %v, want %v", err, boom)
+	}
+}
+
+func TestFixedDelayHonoursContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FixedDelay(time.Hour)(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("FixedDelay() error = %v, want %v", err, context.Canceled)
+	}
+}
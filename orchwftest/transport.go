@@ -0,0 +1,64 @@
+package orchwftest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/refactorroom/orchwf"
+)
+
+// TransportCall is one step invocation seen by a FakeTransport
+type TransportCall struct {
+	StepID string
+	Input  map[string]interface{}
+}
+
+// FakeTransport is an ExecutorTransport that records every call. Stubbed steps return their
+// stubbed result; the others run their own executor, as with orchwf.LocalTransport.
+type FakeTransport struct {
+	mu    sync.Mutex
+	stubs map[string]transportStub
+	calls []TransportCall
+}
+
+// transportStub is the canned result of a stubbed step
+type transportStub struct {
+	output map[string]interface{}
+	err    error
+}
+
+// NewFakeTransport creates a fake transport with no stubs
+func NewFakeTransport() *FakeTransport {
+	return &FakeTransport{stubs: make(map[string]transportStub)}
+}
+
+// Stub makes every call to stepID return output and err instead of running its executor
+func (t *FakeTransport) Stub(stepID string, output map[string]interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stubs[stepID] = transportStub{output: output, err: err}
+}
+
+// Execute records the call and returns the step's stub, or runs its executor
+func (t *FakeTransport) Execute(ctx context.Context, stepDef *orchwf.StepDefinition, input map[string]interface{}) (map[string]interface{}, error) {
+	t.mu.Lock()
+	calledWith := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		calledWith[k] = v
+	}
+	t.calls = append(t.calls, TransportCall{StepID: stepDef.ID, Input: calledWith})
+	stub, ok := t.stubs[stepDef.ID]
+	t.mu.Unlock()
+
+	if ok {
+		return stub.output, stub.err
+	}
+	return stepDef.Executor(ctx, input)
+}
+
+// Calls returns the recorded calls in the order they were made
+func (t *FakeTransport) Calls() []TransportCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TransportCall(nil), t.calls...)
+}
@@ -0,0 +1,68 @@
+package orchwftest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/refactorroom/orchwf"
+)
+
+func TestFakeTransport(t *testing.T) {
+	transport := NewFakeTransport()
+	transport.Stub("charge", map[string]interface{}{"charge_id": "ch_1"}, nil)
+
+	chargeRan := false
+	charge, _ := orchwf.NewStepBuilder("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		chargeRan = true
+		return nil, errors.New("the real payment API should not be called")
+	}).Build()
+	receipt, _ := EchoStep("receipt", "Receipt").WithDependencies("charge").Build()
+
+	workflow, _ := orchwf.NewWorkflowBuilder("transport", "Transport").
+		AddStep(charge).
+		AddStep(receipt).
+		Build()
+	orchestrator := orchwf.NewOrchestrator(orchwf.NewInMemoryStateManager(), orchwf.WithTransport(transport))
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "transport", map[string]interface{}{"amount": 10}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if chargeRan {
+		t.Error("stubbed step ran its executor")
+	}
+	if output, _ := result.StepOutput("receipt"); output["charge_id"] != "ch_1" {
+		t.Errorf("receipt output = %v, want the stubbed charge ID", output)
+	}
+
+	calls := transport.Calls()
+	if len(calls) != 2 || calls[0].StepID != "charge" || calls[1].StepID != "receipt" {
+		t.Fatalf("Calls() = %+v, want charge then receipt", calls)
+	}
+	if calls[0].Input["amount"] != 10 {
+		t.Errorf("charge called with %v, want the workflow input", calls[0].Input)
+	}
+}
+
+func TestFakeTransportStubbedFailureRetries(t *testing.T) {
+	transport := NewFakeTransport()
+	transport.Stub("flaky", nil, errors.New("worker unavailable"))
+
+	flaky, _ := NoopStep("flaky", "Flaky").
+		WithRetryPolicy(&orchwf.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1}).
+		Build()
+	workflow, _ := orchwf.NewWorkflowBuilder("transport-retry", "Transport Retry").AddStep(flaky).Build()
+	orchestrator := orchwf.NewOrchestrator(orchwf.NewInMemoryStateManager(), orchwf.WithTransport(transport))
+	orchestrator.RegisterWorkflow(workflow)
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "transport-retry", nil, nil); err == nil {
+		t.Fatal("StartWorkflow() expected the stubbed failure")
+	}
+	// Retries stay with the orchestrator, so each attempt goes through the transport
+	if calls := transport.Calls(); len(calls) != 3 {
+		t.Errorf("transport calls = %d, want 3", len(calls))
+	}
+}
@@ -0,0 +1,74 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_RequiredOutputKeys(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	// The executor forgets the customer ID on its first attempt and sets it to nil on the second
+	var attempts atomic.Int32
+	var seen interface{}
+	workflow, _ := NewWorkflowBuilder("output-keys", "Output Keys").
+		AddStepFunc("lookup", "Lookup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			switch attempts.Add(1) {
+			case 1:
+				return map[string]interface{}{"email": "a@example.com"}, nil
+			case 2:
+				return map[string]interface{}{"email": "a@example.com", "customer_id": nil}, nil
+			}
+			return map[string]interface{}{"email": "a@example.com", "customer_id": "c-42"}, nil
+		},
+			WithStepRequiredOutputKeys("customer_id", "email"),
+			WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1})).
+		AddStepFunc("bill", "Bill", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			seen = input["customer_id"]
+			return nil, nil
+		}, WithStepDeps("lookup")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "output-keys", nil, nil); err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+	if seen != "c-42" {
+		t.Errorf("bill saw customer_id %v, want c-42", seen)
+	}
+}
+
+func TestOrchestrator_RequiredOutputKeysMissing(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var billed atomic.Bool
+	workflow, _ := NewWorkflowBuilder("output-keys-missing", "Output Keys Missing").
+		AddStepFunc("lookup", "Lookup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"email": "a@example.com"}, nil
+		}, WithStepRequiredOutputKeys("customer_id")).
+		AddStepFunc("bill", "Bill", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			billed.Store(true)
+			return nil, nil
+		}, WithStepDeps("lookup")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "output-keys-missing", nil, nil)
+	if !errors.Is(err, ErrMissingOutputKeys) {
+		t.Fatalf("StartWorkflow() error = %v, want %v", err, ErrMissingOutputKeys)
+	}
+	if billed.Load() {
+		t.Error("bill ran although lookup did not set customer_id")
+	}
+	for _, stepInst := range result.WorkflowInst.Steps {
+		if stepInst.StepID == "lookup" && stepInst.Status != StepStatusFailed {
+			t.Errorf("lookup status = %v, want %v", stepInst.Status, StepStatusFailed)
+		}
+	}
+}
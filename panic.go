@@ -0,0 +1,36 @@
+package orchwf
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicPolicy decides what a panicking step executor does to its step and workflow
+type PanicPolicy string
+
+const (
+	PanicRecoverAsError PanicPolicy = "recover"       // The panic becomes the attempt's error, and the step may retry (default)
+	PanicFailWorkflow   PanicPolicy = "fail_workflow" // The step fails without retries and fails the workflow, even if optional
+	PanicRepanic        PanicPolicy = "repanic"       // The panic is not recovered and crashes the process, for debugging
+)
+
+// WithPanicPolicy sets what happens when a step executor panics. Recovered panics
+// fail the attempt with ErrStepPanicked and log the stack.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(o *Orchestrator) {
+		o.panicPolicy = policy
+	}
+}
+
+// recoveredPanic turns a recovered executor panic into the attempt's error
+func (o *Orchestrator) recoveredPanic(stepDef *StepDefinition, p interface{}) error {
+	o.logger.Printf("orchwf: step %s panicked: %v\n%s", stepDef.ID, p, debug.Stack())
+	return fmt.Errorf("%w: step %s: %v", ErrStepPanicked, stepDef.ID, p)
+}
+
+// panicFailsWorkflow reports whether err is a panic that PanicFailWorkflow lets no
+// retry or optional step absorb
+func (o *Orchestrator) panicFailsWorkflow(err error) bool {
+	return o.panicPolicy == PanicFailWorkflow && errors.Is(err, ErrStepPanicked)
+}
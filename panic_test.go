@@ -0,0 +1,97 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var panicTestRetries = &RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1}
+
+// newPanicTestOrchestrator registers a workflow whose optional step panics on the
+// attempts listed in panicOn, followed by a required step
+func newPanicTestOrchestrator(policy PanicPolicy, panicOn func(attempt int32) bool) (*Orchestrator, *atomic.Int32) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithPanicPolicy(policy))
+
+	var attempts atomic.Int32
+	workflow, _ := NewWorkflowBuilder("panicky", "Panicky").
+		AddStepFunc("buggy", "Buggy", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			if panicOn(attempts.Add(1)) {
+				var m map[string]int
+				m["boom"]++ // Assignment to a nil map
+			}
+			return map[string]interface{}{"ok": true}, nil
+		}, WithStepRequired(false), WithStepRetryPolicy(panicTestRetries)).
+		AddStepFunc("after", "After", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("buggy")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	return orchestrator, &attempts
+}
+
+func TestOrchestrator_PanicRecoverAsError(t *testing.T) {
+	// A panic is an ordinary failed attempt, so the retry succeeds
+	orchestrator, attempts := newPanicTestOrchestrator(PanicRecoverAsError, func(attempt int32) bool { return attempt == 1 })
+	result, err := orchestrator.StartWorkflow(context.Background(), "panicky", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartWorkflow() error = %v, want the retry to succeed", err)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+
+	// An optional step that always panics is skipped like any failed optional step
+	orchestrator, attempts = newPanicTestOrchestrator("", func(int32) bool { return true })
+	result, err = orchestrator.StartWorkflow(context.Background(), "panicky", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartWorkflow() error = %v, want the optional step skipped", err)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+	for _, stepInst := range result.WorkflowInst.Steps {
+		if stepInst.StepID == "buggy" && stepInst.Status != StepStatusSkipped {
+			t.Errorf("buggy status = %v, want %v", stepInst.Status, StepStatusSkipped)
+		}
+	}
+}
+
+func TestOrchestrator_PanicFailWorkflow(t *testing.T) {
+	orchestrator, attempts := newPanicTestOrchestrator(PanicFailWorkflow, func(int32) bool { return true })
+	result, err := orchestrator.StartWorkflow(context.Background(), "panicky", nil, nil)
+	if !errors.Is(err, ErrStepPanicked) {
+		t.Fatalf("StartWorkflow() error = %v, want %v", err, ErrStepPanicked)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("attempts = %d, want 1 with retries skipped", n)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusFailed {
+		t.Errorf("workflow status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusFailed)
+	}
+	for _, stepInst := range result.WorkflowInst.Steps {
+		switch stepInst.StepID {
+		case "buggy":
+			if stepInst.Status != StepStatusFailed || stepInst.FailureKind != FailureKindPanic {
+				t.Errorf("buggy = %v (%v), want %v (%v)", stepInst.Status, stepInst.FailureKind, StepStatusFailed, FailureKindPanic)
+			}
+		case "after":
+			if stepInst.Status == StepStatusCompleted {
+				t.Error("after ran although the panic failed the workflow")
+			}
+		}
+	}
+}
+
+func TestOrchestrator_PanicRepanic(t *testing.T) {
+	orchestrator, _ := newPanicTestOrchestrator(PanicRepanic, func(int32) bool { return true })
+
+	defer func() {
+		if p := recover(); p == nil {
+			t.Error("StartWorkflow() did not panic under PanicRepanic")
+		}
+	}()
+	orchestrator.StartWorkflow(context.Background(), "panicky", nil, nil)
+}
@@ -0,0 +1,65 @@
+package orchwf
+
+import "context"
+
+// persistPolicy returns the persist policy of the workflow definition with the given ID
+func (o *Orchestrator) persistPolicy(workflowID string) PersistPolicy {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if workflow, ok := o.workflows[workflowID]; ok {
+		return workflow.PersistPolicy
+	}
+	return PersistEveryTransition
+}
+
+// savesTransitions reports whether a step's non-terminal transitions, such as running
+// and retrying, are saved as they happen
+func (o *Orchestrator) savesTransitions(workflowID string) bool {
+	return o.persistPolicy(workflowID) == PersistEveryTransition
+}
+
+// flushStepProgress saves what a step recorded in memory while its transitions were
+// not saved: its retry count, time spent waiting to start and input provenance
+func (o *Orchestrator) flushStepProgress(ctx context.Context, stepInst *StepInstance, workflowInst *WorkflowInstance) {
+	if o.savesTransitions(workflowInst.WorkflowID) {
+		return
+	}
+	if stepInst.RetryCount > 0 {
+		o.notePersistence(o.stateManager.UpdateStepRetry(ctx, stepInst.ID, stepInst.RetryCount, stepInst.LastRetryAt))
+	}
+	if stepInst.ReadyAt != nil {
+		o.notePersistence(o.stateManager.UpdateStepWait(ctx, stepInst.ID, *stepInst.ReadyAt, stepInst.WaitMs))
+	}
+	if o.inputProvenance {
+		o.notePersistence(o.stateManager.UpdateStepProvenance(ctx, stepInst.ID, stepInst.Provenance))
+	}
+}
+
+// flushCompletedSteps saves the completed steps and workflow output that PersistOnFailure
+// kept in memory, so a failed or waiting instance can be inspected and resumed
+func (o *Orchestrator) flushCompletedSteps(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) {
+	if workflow.PersistPolicy != PersistOnFailure {
+		return
+	}
+	for _, stepInst := range instance.Steps {
+		if stepInst.Status != StepStatusCompleted {
+			continue
+		}
+		o.flushStepProgress(ctx, stepInst, instance)
+		o.notePersistence(o.stateManager.UpdateStepStatus(ctx, stepInst.ID, StepStatusCompleted))
+		o.notePersistence(o.stateManager.UpdateStepOutput(ctx, stepInst.ID, redactMap(stepInst.Output, workflow.RedactedKeys)))
+	}
+	o.flushWorkflowOutput(ctx, workflow, instance)
+}
+
+// flushWorkflowOutput saves the merged workflow output that PersistOnFailure kept in memory
+func (o *Orchestrator) flushWorkflowOutput(ctx context.Context, workflow *WorkflowDefinition, instance *WorkflowInstance) {
+	if workflow.PersistPolicy != PersistOnFailure {
+		return
+	}
+	o.outputMu.Lock()
+	output := copyMap(instance.Output)
+	o.outputMu.Unlock()
+	o.notePersistence(o.stateManager.UpdateWorkflowOutput(ctx, instance.ID, redactMap(output, workflow.RedactedKeys)))
+}
@@ -0,0 +1,115 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCountingStateManager counts step status updates by status
+type writeCountingStateManager struct {
+	*InMemoryStateManager
+	mu           sync.Mutex
+	statusWrites map[StepStatus]int
+}
+
+func newWriteCountingStateManager() *writeCountingStateManager {
+	return &writeCountingStateManager{InMemoryStateManager: NewInMemoryStateManager(), statusWrites: make(map[StepStatus]int)}
+}
+
+func (m *writeCountingStateManager) UpdateStepStatus(ctx context.Context, stepInstID string, status StepStatus) error {
+	m.mu.Lock()
+	m.statusWrites[status]++
+	m.mu.Unlock()
+	return m.InMemoryStateManager.UpdateStepStatus(ctx, stepInstID, status)
+}
+
+func TestOrchestrator_PersistPolicyReducesWrites(t *testing.T) {
+	const steps = 50
+	tests := []struct {
+		policy        PersistPolicy
+		wantRunning   int
+		wantCompleted int
+	}{
+		{PersistEveryTransition, steps, steps},
+		{PersistTerminalOnly, 0, steps},
+		{PersistOnFailure, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("policy %q", tt.policy), func(t *testing.T) {
+			sm := newWriteCountingStateManager()
+			orchestrator := NewOrchestrator(sm)
+			builder := NewWorkflowBuilder("many-steps", "Many Steps").WithPersistPolicy(tt.policy)
+			for i := 0; i < steps; i++ {
+				n := i
+				var opts []StepOption
+				if i > 0 {
+					opts = append(opts, WithStepDeps(fmt.Sprintf("step%d", i-1)))
+				}
+				builder.AddStepFunc(fmt.Sprintf("step%d", i), "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return map[string]interface{}{"last": n}, nil
+				}, opts...)
+			}
+			workflow, _ := builder.Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "many-steps", nil, nil)
+			if err != nil {
+				t.Fatalf("StartWorkflow() error = %v", err)
+			}
+
+			if sm.statusWrites[StepStatusRunning] != tt.wantRunning || sm.statusWrites[StepStatusCompleted] != tt.wantCompleted {
+				t.Errorf("step status writes = %v, want %d running and %d completed", sm.statusWrites, tt.wantRunning, tt.wantCompleted)
+			}
+
+			// The workflow's final state is saved under every policy
+			stored, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+			if stored.Status != WorkflowStatusCompleted || stored.Output["last"] != steps-1 {
+				t.Errorf("stored workflow = %s with output %v, want completed with the merged output", stored.Status, stored.Output)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_PersistOnFailureFlushesCompletedSteps(t *testing.T) {
+	sm := newWriteCountingStateManager()
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("flush", "Flush").
+		WithPersistPolicy(PersistOnFailure).
+		AddStepFunc("reserve", "Reserve", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"reservation": "r1"}, nil
+		}).
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("card declined")
+		}, WithStepDeps("reserve"), WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, Multiplier: 1})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "flush", nil, nil)
+	if err == nil {
+		t.Fatal("StartWorkflow() should fail when charge fails")
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	byID := make(map[string]*StepInstance)
+	for _, step := range steps {
+		byID[step.StepID] = step
+	}
+	if reserve := byID["reserve"]; reserve.Status != StepStatusCompleted || reserve.Output["reservation"] != "r1" {
+		t.Errorf("stored reserve = %s with output %v, want it flushed as completed", reserve.Status, reserve.Output)
+	}
+	if charge := byID["charge"]; charge.Status != StepStatusFailed || charge.RetryCount != 1 || charge.Error == nil {
+		t.Errorf("stored charge = %+v, want failed with its retry count and error", charge)
+	}
+	if sm.statusWrites[StepStatusRetrying] != 0 {
+		t.Errorf("retrying status written %d times, want it kept in memory", sm.statusWrites[StepStatusRetrying])
+	}
+
+	stored, _ := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if stored.Status != WorkflowStatusFailed || stored.Output["reservation"] != "r1" {
+		t.Errorf("stored workflow = %s with output %v, want failed with the flushed output", stored.Status, stored.Output)
+	}
+}
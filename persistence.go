@@ -0,0 +1,98 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithPersistenceBreaker pauses running workflows once threshold consecutive step
+// writes to the state manager have failed, instead of continuing with progress that
+// is not persisted. Paused workflows have status WorkflowStatusPaused and are picked
+// up again by ResumePausedWorkflows. A zero threshold disables the breaker.
+func WithPersistenceBreaker(threshold int) Option {
+	return func(o *Orchestrator) {
+		o.persistThreshold = threshold
+	}
+}
+
+// notePersistence feeds the result of a step write into the persistence breaker
+func (o *Orchestrator) notePersistence(err error) {
+	if o.persistThreshold <= 0 {
+		return
+	}
+	if err == nil {
+		o.persistFailures.Store(0)
+		return
+	}
+	if o.persistFailures.Add(1) == int64(o.persistThreshold) {
+		o.logger.Printf("orchwf: state manager writes failing, pausing workflows: %v", err)
+	}
+}
+
+// persistenceUnhealthy reports whether the breaker has tripped
+func (o *Orchestrator) persistenceUnhealthy() bool {
+	return o.persistThreshold > 0 && o.persistFailures.Load() >= int64(o.persistThreshold)
+}
+
+// pauseWorkflow marks a workflow paused after the persistence breaker tripped.
+// Saving the status is best effort, since the state manager is likely still down.
+func (o *Orchestrator) pauseWorkflow(ctx context.Context, instance *WorkflowInstance, err error, startTime time.Time) *WorkflowResult {
+	instance.Status = WorkflowStatusPaused
+	o.paused.Store(instance.ID, struct{}{})
+	if updateErr := o.stateManager.UpdateWorkflowStatus(ctx, instance.ID, WorkflowStatusPaused); updateErr != nil {
+		o.logger.Printf("orchwf: failed to persist paused status for workflow %s: %v", instance.ID, updateErr)
+	}
+
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowPaused, map[string]interface{}{
+		"error": err.Error(),
+	})
+	o.metrics.IncCounter("workflow.paused", map[string]string{
+		"workflow_id": instance.WorkflowID,
+	})
+
+	snapshot := instance.Clone()
+	return &WorkflowResult{
+		Success:      false,
+		WorkflowInst: snapshot,
+		Output:       snapshot.Output,
+		Error:        err,
+		Duration:     time.Since(startTime),
+	}
+}
+
+// ResumePausedWorkflows checks that the state manager is healthy again, resets the
+// persistence breaker and resumes every paused workflow. It returns the number of
+// workflows that were resumed.
+func (o *Orchestrator) ResumePausedWorkflows(ctx context.Context) (int, error) {
+	if err := o.stateManager.Ping(ctx); err != nil {
+		return 0, fmt.Errorf("state manager still unhealthy: %w", err)
+	}
+	o.persistFailures.Store(0)
+
+	paused, _, err := o.stateManager.ListWorkflows(WithoutTotal(ctx), map[string]interface{}{"status": WorkflowStatusPaused}, 1000, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list paused workflows: %w", err)
+	}
+
+	// The paused status itself may not have been saved while the state manager was down
+	ids := make(map[string]struct{})
+	for _, instance := range paused {
+		ids[instance.ID] = struct{}{}
+	}
+	o.paused.Range(func(key, _ interface{}) bool {
+		ids[key.(string)] = struct{}{}
+		return true
+	})
+
+	resumed := 0
+	for id := range ids {
+		if _, err := o.ResumeWorkflow(ctx, id); err != nil {
+			o.logger.Printf("orchwf: failed to resume paused workflow %s: %v", id, err)
+			continue
+		}
+		o.paused.Delete(id)
+		resumed++
+	}
+	return resumed, nil
+}
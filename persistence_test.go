@@ -0,0 +1,90 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// flakyStateManager fails writes and pings while down is set
+type flakyStateManager struct {
+	*InMemoryStateManager
+	down atomic.Bool
+}
+
+var errStoreDown = errors.New("store down")
+
+func (m *flakyStateManager) UpdateStepStatus(ctx context.Context, stepInstID string, status StepStatus) error {
+	if m.down.Load() {
+		return errStoreDown
+	}
+	return m.InMemoryStateManager.UpdateStepStatus(ctx, stepInstID, status)
+}
+
+func (m *flakyStateManager) UpdateWorkflowStatus(ctx context.Context, workflowInstID string, status WorkflowStatus) error {
+	if m.down.Load() {
+		return errStoreDown
+	}
+	return m.InMemoryStateManager.UpdateWorkflowStatus(ctx, workflowInstID, status)
+}
+
+func (m *flakyStateManager) Ping(ctx context.Context) error {
+	if m.down.Load() {
+		return errStoreDown
+	}
+	return nil
+}
+
+func TestOrchestrator_PersistenceBreakerPausesAndResumes(t *testing.T) {
+	sm := &flakyStateManager{InMemoryStateManager: NewInMemoryStateManager()}
+	orchestrator := NewOrchestrator(sm, WithPersistenceBreaker(1))
+
+	var firstRuns, secondRuns atomic.Int32
+	workflow, _ := NewWorkflowBuilder("flaky", "Flaky").
+		AddStepFunc("first", "First", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			if firstRuns.Add(1) == 1 {
+				sm.down.Store(true) // The store goes down while the step runs
+			}
+			return map[string]interface{}{}, nil
+		}).
+		AddStepFunc("second", "Second", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			secondRuns.Add(1)
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("first")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "flaky", nil, nil)
+	if !errors.Is(err, ErrStateManagerDown) {
+		t.Fatalf("StartWorkflow() error = %v, want %v", err, ErrStateManagerDown)
+	}
+	if result.WorkflowInst.Status != WorkflowStatusPaused {
+		t.Errorf("workflow status = %v, want %v", result.WorkflowInst.Status, WorkflowStatusPaused)
+	}
+	if secondRuns.Load() != 0 {
+		t.Errorf("second step ran %d times while paused, want 0", secondRuns.Load())
+	}
+
+	if _, err := orchestrator.ResumePausedWorkflows(context.Background()); err == nil {
+		t.Errorf("ResumePausedWorkflows() should fail while the store is down")
+	}
+
+	sm.down.Store(false)
+	resumed, err := orchestrator.ResumePausedWorkflows(context.Background())
+	if err != nil {
+		t.Fatalf("ResumePausedWorkflows() error = %v", err)
+	}
+	if resumed != 1 {
+		t.Errorf("ResumePausedWorkflows() = %d, want %d", resumed, 1)
+	}
+
+	instance, _ := orchestrator.GetWorkflowStatus(context.Background(), result.WorkflowInst.ID)
+	if instance.Status != WorkflowStatusCompleted {
+		t.Errorf("workflow status after resume = %v, want %v", instance.Status, WorkflowStatusCompleted)
+	}
+	// The first step's completion was never saved, so it runs again
+	if firstRuns.Load() != 2 || secondRuns.Load() != 1 {
+		t.Errorf("runs = first %d, second %d; want 2 and 1", firstRuns.Load(), secondRuns.Load())
+	}
+}
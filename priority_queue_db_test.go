@@ -226,6 +226,55 @@ func TestPriorityMigration(t *testing.T) {
 
 // Helper functions
 
+// TestPriorityPersistedOnResume tests that resumed workflows keep the priority captured at creation
+func TestPriorityPersistedOnResume(t *testing.T) {
+	stateManager := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(stateManager)
+	ctx := context.Background()
+
+	executionOrder := make([]string, 0)
+	record := func(id string) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			executionOrder = append(executionOrder, id)
+			return map[string]interface{}{}, nil
+		}
+	}
+
+	// The definition now prefers "second", but the instance was created when "first" had the higher priority
+	workflow, err := NewWorkflowBuilder("priority_resume_test", "Priority Resume Test").
+		AddStepFunc("first", "First", record("first"), WithStepPriority(1)).
+		AddStepFunc("second", "Second", record("second"), WithStepPriority(10)).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to create workflow: %v", err)
+	}
+	orchestrator.RegisterWorkflow(workflow)
+
+	instance := &WorkflowInstance{ID: "resume-instance", WorkflowID: "priority_resume_test", Status: WorkflowStatusRunning}
+	stateManager.SaveWorkflow(ctx, instance)
+	stateManager.SaveStep(ctx, &StepInstance{ID: "si-first", StepID: "first", WorkflowInstID: instance.ID, Status: StepStatusPending, ExecutionOrder: 0, Priority: 10})
+	stateManager.SaveStep(ctx, &StepInstance{ID: "si-second", StepID: "second", WorkflowInstID: instance.ID, Status: StepStatusPending, ExecutionOrder: 1, Priority: 1})
+
+	if _, err := orchestrator.ResumeWorkflow(ctx, instance.ID); err != nil {
+		t.Fatalf("ResumeWorkflow() error = %v", err)
+	}
+
+	if len(executionOrder) != 2 || executionOrder[0] != "first" {
+		t.Errorf("Expected persisted priority order [first second], got %v", executionOrder)
+	}
+
+	// Fresh runs capture the definition priority on the step instance
+	result, err := orchestrator.StartWorkflow(ctx, "priority_resume_test", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	for _, step := range result.WorkflowInst.Steps {
+		if want := getStepPriorityFromWorkflow(workflow, step.StepID); step.Priority != want {
+			t.Errorf("Step %s persisted priority = %d, want %d", step.StepID, step.Priority, want)
+		}
+	}
+}
+
 func createPriorityWorkflow() (*WorkflowDefinition, error) {
 	// Create steps with different priorities
 	criticalStep, err := NewStepBuilder("critical", "Critical Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
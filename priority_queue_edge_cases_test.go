@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -421,13 +422,20 @@ func TestPriorityWithTimeoutEdgeCase(t *testing.T) {
 
 	executionOrder := make([]string, 0)
 	executionMutex := sync.Mutex{}
+	release := make(chan struct{})
+	var finished atomic.Bool
 
 	// High priority step that times out
 	highPriorityTimeout, err := NewStepBuilder("high_timeout", "High Priority Timeout", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
 		executionMutex.Lock()
 		executionOrder = append(executionOrder, "high_timeout")
 		executionMutex.Unlock()
-		time.Sleep(200 * time.Millisecond) // Longer than timeout
+		// Ignores ctx and runs until the test releases it, well past the timeout
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+		finished.Store(true)
 		return map[string]interface{}{"result": "high_timeout"}, nil
 	}).
 		WithPriority(10).
@@ -467,8 +475,10 @@ func TestPriorityWithTimeoutEdgeCase(t *testing.T) {
 	// Register and execute
 	orchestrator.RegisterWorkflow(workflow)
 
+	start := time.Now()
 	result, err := orchestrator.StartWorkflow(context.Background(), "timeout_priority_test",
 		map[string]interface{}{"data": "test"}, nil)
+	elapsed := time.Since(start)
 
 	if err != nil {
 		t.Fatalf("Workflow execution failed: %v", err)
@@ -478,9 +488,21 @@ func TestPriorityWithTimeoutEdgeCase(t *testing.T) {
 		t.Fatalf("Workflow failed: %v", result.Error)
 	}
 
+	// The timed-out step is abandoned at its timeout rather than waited on, even though
+	// its executor ignores ctx and is still running
+	if finished.Load() {
+		t.Errorf("Workflow returned after the timed-out executor finished, want it abandoned")
+	}
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("Workflow took %v, want it to wait for the 100ms step timeout", elapsed)
+	}
+	close(release)
+
 	// Both steps should execute (high priority first, then low)
 	expectedOrder := []string{"high_timeout", "low_success"}
+	executionMutex.Lock()
 	if len(executionOrder) != len(expectedOrder) {
+		executionMutex.Unlock()
 		t.Fatalf("Expected %d steps to execute, got %d", len(expectedOrder), len(executionOrder))
 	}
 
@@ -489,6 +511,33 @@ func TestPriorityWithTimeoutEdgeCase(t *testing.T) {
 			t.Errorf("Expected step %d to be '%s', got '%s'", i, expected, executionOrder[i])
 		}
 	}
+	executionMutex.Unlock()
+
+	// Wait for the abandoned executor to return; its late output must change nothing
+	deadline := time.Now().Add(time.Second)
+	for orchestrator.AbandonedSteps() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("AbandonedSteps() = %d, want 0 once the executor returns", orchestrator.AbandonedSteps())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	steps, _ := stateManager.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	for _, step := range steps {
+		switch step.StepID {
+		case "high_timeout":
+			if step.Status != StepStatusSkipped || step.SkipReason != SkipReasonTimedOut {
+				t.Errorf("high_timeout = %s (%s), want skipped (%s)", step.Status, step.SkipReason, SkipReasonTimedOut)
+			}
+			if len(step.Output) != 0 {
+				t.Errorf("high_timeout output = %v, want the late output discarded", step.Output)
+			}
+		case "low_success":
+			if step.Status != StepStatusCompleted {
+				t.Errorf("low_success = %s, want completed", step.Status)
+			}
+		}
+	}
 }
 
 // TestPriorityWithRetry tests priority behavior with retry logic
@@ -515,7 +564,7 @@ func TestPriorityWithRetry(t *testing.T) {
 		WithRetryPolicy(NewRetryPolicyBuilder().
 			WithMaxAttempts(3).
 			WithInitialInterval(10 * time.Millisecond).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
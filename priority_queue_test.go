@@ -705,7 +705,7 @@ func TestPriorityWithRetryPolicy(t *testing.T) {
 		WithRetryPolicy(NewRetryPolicyBuilder().
 			WithMaxAttempts(3).
 			WithInitialInterval(10 * time.Millisecond).
-			Build()).
+			MustBuild()).
 		Build()
 
 	if err != nil {
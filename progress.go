@@ -0,0 +1,83 @@
+package orchwf
+
+import (
+	"context"
+	"time"
+)
+
+// progressInterval is the least time between two progress events of a step. Updates
+// reported sooner are coalesced: only the latest is kept, and it is emitted once the
+// interval has passed or the attempt returns.
+const progressInterval = time.Second
+
+// progressUpdate is a progress report of a step
+type progressUpdate struct {
+	percent float64
+	message string
+}
+
+// ReportProgress records how far the current step has got, as a percentage from 0 to 100,
+// in a step.progress event. Rapid updates are coalesced so a step can report in a tight
+// loop without flooding the event store. Nothing is recorded when the workflow's persist
+// policy skips step transitions. It returns ErrNoWorkflowContext outside a step.
+func ReportProgress(ctx context.Context, percent float64, message string) error {
+	run, ok := ctx.Value(stepRunKey).(*stepRun)
+	if !ok {
+		return ErrNoWorkflowContext
+	}
+
+	o := run.orchestrator
+	if info, _ := InstanceInfoFromContext(ctx); !o.savesTransitions(info.WorkflowID) {
+		return nil
+	}
+
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	update := progressUpdate{percent: percent, message: message}
+	now := o.clock.Now()
+
+	run.mu.Lock()
+	if !run.progressAt.IsZero() && now.Sub(run.progressAt) < progressInterval && percent < 100 {
+		run.pendingProgress = &update
+		run.mu.Unlock()
+		return nil
+	}
+	run.progressAt = now
+	run.pendingProgress = nil
+	run.mu.Unlock()
+
+	o.emitProgress(ctx, run.stepInst, update)
+	return nil
+}
+
+// flushReportedProgress emits the latest progress update held back by coalescing, if any
+func (o *Orchestrator) flushReportedProgress(ctx context.Context) {
+	run, ok := ctx.Value(stepRunKey).(*stepRun)
+	if !ok {
+		return
+	}
+
+	run.mu.Lock()
+	update := run.pendingProgress
+	run.pendingProgress = nil
+	if update != nil {
+		run.progressAt = o.clock.Now()
+	}
+	run.mu.Unlock()
+
+	if update != nil {
+		o.emitProgress(ctx, run.stepInst, *update)
+	}
+}
+
+// emitProgress records a step.progress event
+func (o *Orchestrator) emitProgress(ctx context.Context, stepInst *StepInstance, update progressUpdate) {
+	o.emitEvent(ctx, stepInst.WorkflowInstID, &stepInst.ID, EventStepProgress, map[string]interface{}{
+		"step_id": stepInst.StepID,
+		"percent": update.percent,
+		"message": update.message,
+	})
+}
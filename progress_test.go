@@ -0,0 +1,49 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestReportProgress(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	clock := &manualClock{now: time.Now()}
+	orchestrator := NewOrchestrator(sm, WithClock(clock))
+	workflow, _ := NewWorkflowBuilder("export", "Export").
+		AddStepFunc("dump", "Dump", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			ReportProgress(ctx, 10, "started")
+			ReportProgress(ctx, 20, "coalesced")
+			ReportProgress(ctx, 30, "coalesced")
+			clock.advance(2 * time.Second)
+			ReportProgress(ctx, 50, "halfway")
+			ReportProgress(ctx, 70, "flushed when the attempt returns")
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "export", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	events, _ := sm.GetWorkflowEvents(context.Background(), result.WorkflowInst.ID)
+	var percents []float64
+	for _, event := range events {
+		if event.EventType == EventStepProgress {
+			percents = append(percents, event.EventData["percent"].(float64))
+		}
+	}
+	sort.Float64s(percents)
+	if want := []float64{10, 50, 70}; !reflect.DeepEqual(percents, want) {
+		t.Errorf("progress events = %v, want %v", percents, want)
+	}
+
+	if err := ReportProgress(context.Background(), 50, ""); !errors.Is(err, ErrNoWorkflowContext) {
+		t.Errorf("ReportProgress() outside a step error = %v, want ErrNoWorkflowContext", err)
+	}
+}
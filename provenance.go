@@ -0,0 +1,37 @@
+package orchwf
+
+// Sources recorded in StepInstance.Provenance. A key taken from a dependency's output,
+// flattened or under the dependency's ID, is recorded as "step:" followed by the step ID.
+const (
+	ProvenanceInput   = "input"   // The workflow input
+	ProvenanceContext = "context" // The workflow context, which holds the outputs merged so far
+	ProvenanceFanIn   = "fan_in"  // The dependency outputs collected by a fan-in step
+	ProvenanceReducer = "reducer" // The result of a fan-in step's reducer
+)
+
+// WithInputProvenance records, for every step, where each key of its input came from:
+// the workflow input, a dependency's output, the workflow context or a fan-in. The map
+// is persisted with the step in StepInstance.Provenance. It is off by default to save
+// the overhead.
+func WithInputProvenance(enabled bool) Option {
+	return func(o *Orchestrator) {
+		o.inputProvenance = enabled
+	}
+}
+
+// dependencyProvenance returns the source recorded for keys from a dependency's output
+func dependencyProvenance(depID string) string {
+	return "step:" + depID
+}
+
+// copyProvenance returns a copy of a provenance map, keeping nil as nil
+func copyProvenance(provenance map[string]string) map[string]string {
+	if provenance == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(provenance))
+	for k, v := range provenance {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,112 @@
+package orchwf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestOrchestrator_InputProvenance(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithInputProvenance(true))
+
+	var shipInput map[string]interface{}
+	workflow, _ := NewWorkflowBuilder("provenance", "Provenance").
+		AddStepFunc("price", "Price", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"amount": 12, "currency": "EUR"}, nil
+		}).
+		AddStepFunc("audit", "Audit", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"currency": "USD"}, nil
+		}).
+		AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			shipInput = input
+			return nil, nil
+		}, WithStepDeps("price")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "provenance", map[string]interface{}{"order_id": "o1", "amount": 10}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	var provenance map[string]string
+	for _, step := range steps {
+		if step.StepID == "ship" {
+			provenance = step.Provenance
+		}
+	}
+
+	// The audit step is not a dependency, but its currency reaches ship through the context
+	want := map[string]string{
+		"order_id": ProvenanceInput,
+		"amount":   "step:price",
+		"price":    "step:price",
+		"currency": ProvenanceContext,
+		"audit":    ProvenanceContext,
+	}
+	if !reflect.DeepEqual(provenance, want) {
+		t.Errorf("ship provenance = %v, want %v", provenance, want)
+	}
+	if shipInput["currency"] != "USD" {
+		t.Errorf("ship currency = %v, want USD from the context", shipInput["currency"])
+	}
+}
+
+func TestOrchestrator_InputProvenanceFanIn(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm, WithInputProvenance(true))
+
+	value := func(n int) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"n": n}, nil
+		}
+	}
+	workflow, _ := NewWorkflowBuilder("fan-in-provenance", "Fan-In Provenance").
+		AddStepFunc("a", "A", value(1)).
+		AddStepFunc("b", "B", value(2)).
+		AddStepFunc("sum", "Sum", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("a", "b"), WithStepFanIn(), WithStepReducer(func(outputs []map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{"total": len(outputs)}
+		})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "fan-in-provenance", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	for _, step := range steps {
+		if step.StepID != "sum" {
+			continue
+		}
+		if step.Provenance["sum"] != ProvenanceFanIn || step.Provenance["total"] != ProvenanceReducer {
+			t.Errorf("sum provenance = %v, want sum from the fan-in and total from the reducer", step.Provenance)
+		}
+	}
+}
+
+func TestOrchestrator_InputProvenanceOffByDefault(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	workflow, _ := NewWorkflowBuilder("no-provenance", "No Provenance").
+		AddStepFunc("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "no-provenance", map[string]interface{}{"key": "value"}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	steps, _ := sm.GetWorkflowSteps(context.Background(), result.WorkflowInst.ID)
+	if steps[0].Provenance != nil {
+		t.Errorf("Provenance = %v, want nil without WithInputProvenance", steps[0].Provenance)
+	}
+}
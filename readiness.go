@@ -0,0 +1,108 @@
+package orchwf
+
+import (
+	"context"
+	"time"
+)
+
+// StepBlocker names what a step that has not run is waiting on
+type StepBlocker string
+
+const (
+	StepBlockerNone         StepBlocker = ""             // Nothing known holds the step back; it runs when the instance is next scheduled
+	StepBlockerDependencies StepBlocker = "dependencies" // Some dependencies have not completed or been skipped
+	StepBlockerTimer        StepBlocker = "timer"        // A timer step waiting for its wake time
+	StepBlockerStepLimit    StepBlocker = "step_limit"   // Waiting for a slot of the workflow's WithMaxConcurrentSteps cap
+	StepBlockerResource     StepBlocker = "resource"     // Waiting for units of a shared resource pool
+	StepBlockerBackpressure StepBlocker = "backpressure" // The next wave is held back by RequestBackpressure
+	StepBlockerPersistence  StepBlocker = "persistence"  // The instance was paused because state manager writes kept failing
+)
+
+// StepReadiness explains why a step of a workflow instance has not run yet
+type StepReadiness struct {
+	StepID            string
+	Status            StepStatus
+	BlockedOn         StepBlocker
+	UnmetDependencies []string   // Dependencies that have not completed or been skipped
+	WakeAt            *time.Time // Set for timer steps waiting for their wake time
+	Resource          string     // Pool the step waits on when BlockedOn is StepBlockerResource
+}
+
+// stepGate is a scheduling gate a step or a whole instance is waiting at
+type stepGate struct {
+	blocker  StepBlocker
+	resource string
+}
+
+// waitAtGate records that a step instance, or a whole workflow instance, is waiting at
+// a gate until the returned func is called
+func (o *Orchestrator) waitAtGate(id string, gate stepGate) func() {
+	o.gates.Store(id, gate)
+	return func() { o.gates.Delete(id) }
+}
+
+// GetStepReadiness reports, for every step of an instance that has not run, what it is
+// waiting on: unmet dependencies, a timer, a worker slot, a shared resource, backpressure
+// or the persistence breaker. Slots, resources and backpressure are only seen for runs
+// executing in this process. Running steps appear only while they wait for a resource.
+func (o *Orchestrator) GetStepReadiness(ctx context.Context, workflowInstID string) (map[string]StepReadiness, error) {
+	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := o.GetWorkflow(instance.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := o.stateManager.GetWorkflowSteps(ctx, workflowInstID)
+	if err != nil {
+		return nil, err
+	}
+	stepInstMap := make(map[string]*StepInstance)
+	for _, stepInst := range steps {
+		stepInstMap[stepInst.StepID] = stepInst
+	}
+
+	_, paused := o.paused.Load(workflowInstID)
+	readiness := make(map[string]StepReadiness)
+	for _, stepDef := range workflow.Steps {
+		ready := StepReadiness{StepID: stepDef.ID, Status: StepStatusPending}
+		var gate stepGate
+		if stepInst, ok := stepInstMap[stepDef.ID]; ok {
+			ready.Status, ready.WakeAt = stepInst.Status, stepInst.WakeAt
+			if value, ok := o.gates.Load(stepInst.ID); ok {
+				gate = value.(stepGate)
+			}
+		}
+		switch ready.Status {
+		case StepStatusPending, StepStatusWaiting:
+		case StepStatusRunning:
+			if gate.blocker == StepBlockerNone {
+				continue // Its executor is running
+			}
+		default:
+			continue
+		}
+
+		ready.UnmetDependencies = unmetDependencies(workflow, stepDef, instance.Input, stepInstMap)
+		switch {
+		case len(ready.UnmetDependencies) > 0:
+			ready.BlockedOn = StepBlockerDependencies
+		case ready.Status == StepStatusWaiting:
+			ready.BlockedOn = StepBlockerTimer
+		case gate.blocker != StepBlockerNone:
+			ready.BlockedOn, ready.Resource = gate.blocker, gate.resource
+		case paused:
+			ready.BlockedOn = StepBlockerPersistence
+		default:
+			if value, ok := o.gates.Load(workflowInstID); ok {
+				ready.BlockedOn = value.(stepGate).blocker
+			}
+		}
+		readiness[stepDef.ID] = ready
+	}
+
+	return readiness, nil
+}
@@ -0,0 +1,55 @@
+package orchwf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_GetStepReadiness(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("export", "Export").
+		WithMaxConcurrentSteps(1).
+		AddStepFunc("dump", "Dump", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		}).
+		AddStepFunc("upload", "Upload", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("dump")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	defer orchestrator.Shutdown(context.Background())
+
+	if _, err := orchestrator.StartWorkflowAsync(context.Background(), "export", nil, nil); err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	<-started
+	defer close(release)
+
+	// The second instance's dump step waits for the slot the first one holds
+	id, err := orchestrator.StartWorkflowAsync(context.Background(), "export", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+	var readiness map[string]StepReadiness
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		readiness, err = orchestrator.GetStepReadiness(context.Background(), id)
+		if err == nil && readiness["dump"].BlockedOn == StepBlockerStepLimit {
+			break
+		}
+	}
+
+	if got := readiness["dump"]; got.BlockedOn != StepBlockerStepLimit || len(got.UnmetDependencies) != 0 {
+		t.Errorf("dump readiness = %+v, want blocked on the step limit", got)
+	}
+	want := StepReadiness{StepID: "upload", Status: StepStatusPending, BlockedOn: StepBlockerDependencies, UnmetDependencies: []string{"dump"}}
+	if got := readiness["upload"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("upload readiness = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package orchwf
+
+import "context"
+
+// redactedValue replaces the values of redacted keys in persisted state
+const redactedValue = "***"
+
+// redactedKeys returns the keys masked when persisting instances of a workflow
+func (o *Orchestrator) redactedKeys(workflowID string) []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if workflow, ok := o.workflows[workflowID]; ok {
+		return workflow.RedactedKeys
+	}
+	return nil
+}
+
+// redactedKeysFromContext returns the redacted keys of the workflow running in ctx, if any
+func (o *Orchestrator) redactedKeysFromContext(ctx context.Context) []string {
+	run, ok := ctx.Value(workflowRunKey).(*workflowRun)
+	if !ok {
+		return nil
+	}
+	return o.redactedKeys(run.instance.WorkflowID)
+}
+
+// redactMap returns a copy of m with the values of keys masked at any depth.
+// m itself is returned when there is nothing to redact.
+func redactMap(m map[string]interface{}, keys []string) map[string]interface{} {
+	if len(keys) == 0 || m == nil {
+		return m
+	}
+
+	redacted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if containsString(keys, k) {
+			redacted[k] = redactedValue
+			continue
+		}
+		redacted[k] = redactValue(v, keys)
+	}
+	return redacted
+}
+
+// redactValue masks redacted keys inside nested maps and lists
+func redactValue(v interface{}, keys []string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return redactMap(value, keys)
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, item := range value {
+			redacted[i] = redactValue(item, keys)
+		}
+		return redacted
+	case []map[string]interface{}:
+		redacted := make([]map[string]interface{}, len(value))
+		for i, item := range value {
+			redacted[i] = redactMap(item, keys)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// redactInstance returns a copy of instance that is safe to persist, leaving the
+// live instance, which executors read, untouched
+func redactInstance(instance *WorkflowInstance, keys []string) *WorkflowInstance {
+	if len(keys) == 0 {
+		return instance
+	}
+
+	redacted := *instance
+	redacted.Input = redactMap(instance.Input, keys)
+	redacted.Output = redactMap(instance.Output, keys)
+	redacted.Context = redactMap(instance.Context, keys)
+	redacted.Steps = redactSteps(instance.Steps, keys)
+	return &redacted
+}
+
+// redactSteps returns copies of steps that are safe to persist
+func redactSteps(steps []*StepInstance, keys []string) []*StepInstance {
+	if len(keys) == 0 {
+		return steps
+	}
+
+	redacted := make([]*StepInstance, len(steps))
+	for i, step := range steps {
+		copied := *step
+		copied.Input = redactMap(step.Input, keys)
+		copied.Output = redactMap(step.Output, keys)
+		redacted[i] = &copied
+	}
+	return redacted
+}
@@ -0,0 +1,76 @@
+package orchwf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOrchestrator_RedactedKeys(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	var seenEmail, seenCard interface{}
+	workflow, _ := NewWorkflowBuilder("checkout", "Checkout").
+		WithRedactedKeys("customer_email", "card_number").
+		AddStepFunc("pay", "Pay", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			seenEmail = input["customer_email"]
+			return map[string]interface{}{"card_number": "4111111111111111", "payment_id": "pay_1"}, nil
+		}).
+		AddStepFunc("receipt", "Receipt", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			seenCard = input["card_number"]
+			return map[string]interface{}{}, nil
+		}, WithStepDeps("pay")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "checkout", map[string]interface{}{
+		"customer_email": "jane@example.com",
+		"amount":         42.0,
+	}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// Executors and the live instance keep the real values
+	if seenEmail != "jane@example.com" || seenCard != "4111111111111111" {
+		t.Errorf("executors saw email %v and card %v, want the real values", seenEmail, seenCard)
+	}
+	if result.Output["card_number"] != "4111111111111111" {
+		t.Errorf("result output card_number = %v, want the real value", result.Output["card_number"])
+	}
+
+	saved, err := sm.GetWorkflow(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("GetWorkflow() error = %v", err)
+	}
+	if saved.Input["customer_email"] != redactedValue || saved.Input["amount"] != 42.0 {
+		t.Errorf("persisted input = %v, want only customer_email masked", saved.Input)
+	}
+	if saved.Output["card_number"] != redactedValue || saved.Output["payment_id"] != "pay_1" {
+		t.Errorf("persisted output = %v, want only card_number masked", saved.Output)
+	}
+	for _, step := range saved.Steps {
+		if value, ok := step.Output["card_number"]; ok && value != redactedValue {
+			t.Errorf("persisted output of step %s has card_number %v", step.StepID, value)
+		}
+	}
+}
+
+func TestRedactMap(t *testing.T) {
+	m := map[string]interface{}{
+		"token": "secret",
+		"items": []interface{}{map[string]interface{}{"token": "nested", "id": 1}},
+		"name":  "widget",
+	}
+
+	redacted := redactMap(m, []string{"token"})
+	if redacted["token"] != redactedValue || redacted["name"] != "widget" {
+		t.Errorf("redactMap() = %v", redacted)
+	}
+	if item := redacted["items"].([]interface{})[0].(map[string]interface{}); item["token"] != redactedValue || item["id"] != 1 {
+		t.Errorf("redactMap() nested item = %v", item)
+	}
+	if m["token"] != "secret" || m["items"].([]interface{})[0].(map[string]interface{})["token"] != "nested" {
+		t.Errorf("redactMap() modified its input: %v", m)
+	}
+}
@@ -0,0 +1,153 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedriveFailedSteps re-runs a finished workflow from its failure: the steps that failed or
+// were skipped because of a failure run again, along with every step downstream of them,
+// while the other completed steps keep their outputs. A workflow with nothing to redrive,
+// such as one that completed cleanly, is returned as is without running anything.
+func (o *Orchestrator) RedriveFailedSteps(ctx context.Context, workflowInstID string) (*WorkflowResult, error) {
+	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow: %w", err)
+	}
+
+	workflow, err := o.GetWorkflow(instance.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !instance.IsCompleted() {
+		return nil, fmt.Errorf("workflow %s is %s; only finished workflows can be redriven", instance.ID, instance.Status)
+	}
+
+	steps := stepsToRedrive(workflow, instance)
+	if len(steps) == 0 {
+		snapshot := instance.Clone()
+		return &WorkflowResult{
+			Success:      instance.Status == WorkflowStatusCompleted,
+			WorkflowInst: snapshot,
+			Output:       snapshot.Output,
+			Duration:     time.Since(instance.StartedAt),
+		}, nil
+	}
+
+	release, err := o.lockConcurrencyKey(ctx, instance.WorkflowID, instance.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	freeSlot, err := o.acquireSlot(ctx, workflow, instance.ParentInstID)
+	if err != nil {
+		return nil, err
+	}
+	defer freeSlot()
+
+	stepIDs := make([]string, 0, len(steps))
+	for _, stepInst := range steps {
+		if err := o.stateManager.ResetStep(ctx, stepInst.ID); err != nil {
+			return nil, fmt.Errorf("failed to reset step %s: %w", stepInst.StepID, err)
+		}
+		resetStep(stepInst)
+		stepIDs = append(stepIDs, stepInst.StepID)
+	}
+	instance.Error = nil
+	instance.CompletedAt = nil
+
+	o.emitEvent(ctx, instance.ID, nil, EventWorkflowRedriven, map[string]interface{}{
+		"status": string(instance.Status),
+		"steps":  stepIDs,
+	})
+
+	return o.executeWorkflow(ctx, workflow, instance)
+}
+
+// stepsToRedrive returns the step instances a redrive resets, in execution order: the failed
+// steps, the steps skipped because of a failure, everything downstream of them, the members
+// of any alternatives group among them, and the finalizer
+func stepsToRedrive(workflow *WorkflowDefinition, instance *WorkflowInstance) []*StepInstance {
+	redrive := make(map[string]bool)
+	for _, stepInst := range instance.Steps {
+		if needsRedrive(stepInst) {
+			redrive[stepInst.StepID] = true
+		}
+	}
+
+	// Downstream steps ran against the missing outputs, so they run again too
+	for changed := true; changed; {
+		changed = false
+		for _, step := range workflow.Steps {
+			if redrive[step.ID] {
+				continue
+			}
+			for _, dep := range stepDependencies(workflow, step, instance.Input) {
+				if redrive[dep] {
+					redrive[step.ID] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var seeded bool
+	for _, step := range workflow.Steps {
+		if !redrive[step.ID] {
+			continue
+		}
+		seeded = true
+		for _, member := range step.Alternatives {
+			redrive[member.ID] = true
+		}
+	}
+	if !seeded {
+		return nil
+	}
+	if workflow.Finalizer != nil {
+		redrive[workflow.Finalizer.ID] = true
+	}
+
+	var steps []*StepInstance
+	for _, stepInst := range instance.Steps {
+		if redrive[stepInst.StepID] {
+			steps = append(steps, stepInst)
+		}
+	}
+	return steps
+}
+
+// needsRedrive reports whether a step ended in a way a redrive should retry
+func needsRedrive(stepInst *StepInstance) bool {
+	switch stepInst.Status {
+	case StepStatusFailed, StepStatusCancelled:
+		return true
+	case StepStatusSkipped:
+		switch stepInst.SkipReason {
+		case SkipReasonOptionalFailure, SkipReasonTimedOut, SkipReasonCancelled:
+			return true
+		}
+	}
+	return false
+}
+
+// resetStep returns a step instance to pending, clearing the results of its previous run
+func resetStep(stepInst *StepInstance) {
+	stepInst.Status = StepStatusPending
+	stepInst.Output = make(map[string]interface{})
+	stepInst.Error = nil
+	stepInst.SkipReason = ""
+	stepInst.FailureKind = ""
+	stepInst.StartedAt = nil
+	stepInst.CompletedAt = nil
+	stepInst.RetryCount = 0
+	stepInst.LastRetryAt = nil
+	stepInst.DurationMs = 0
+	stepInst.WakeAt = nil
+	stepInst.ReadyAt = nil
+	stepInst.WaitMs = 0
+}
@@ -0,0 +1,185 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_RedriveFailedSteps(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	runs := make(map[string]int)
+	failCharge := true
+	step := func(id string) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs[id]++
+			if id == "charge" && failCharge {
+				return nil, errors.New("card declined")
+			}
+			return map[string]interface{}{id: true}, nil
+		}
+	}
+
+	workflow, _ := NewWorkflowBuilder("redrive", "Redrive").
+		AddStepFunc("reserve", "Reserve", step("reserve")).
+		AddStepFunc("audit", "Audit", step("audit")).
+		AddStepFunc("charge", "Charge", step("charge"), WithStepDeps("reserve")).
+		AddStepFunc("ship", "Ship", step("ship"), WithStepDeps("charge")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "redrive", nil, nil)
+	if err == nil {
+		t.Fatal("StartWorkflow() expected the charge step to fail")
+	}
+
+	failCharge = false
+	redriven, err := orchestrator.RedriveFailedSteps(ctx, result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("RedriveFailedSteps() error = %v", err)
+	}
+	if !redriven.Success || redriven.WorkflowInst.Status != WorkflowStatusCompleted {
+		t.Fatalf("RedriveFailedSteps() success = %v, status = %s, want a completed workflow", redriven.Success, redriven.WorkflowInst.Status)
+	}
+
+	// Completed steps keep their outputs, only the failure and what follows it run again
+	want := map[string]int{"reserve": 1, "audit": 1, "charge": 2, "ship": 1}
+	for id, n := range want {
+		if runs[id] != n {
+			t.Errorf("step %s ran %d times, want %d", id, runs[id], n)
+		}
+	}
+	for id := range want {
+		if redriven.Output[id] != true {
+			t.Errorf("workflow output is missing %s: %v", id, redriven.Output)
+		}
+	}
+
+	steps, _ := orchestrator.GetWorkflowSteps(ctx, result.WorkflowInst.ID)
+	for _, stepInst := range steps {
+		if stepInst.Status != StepStatusCompleted || stepInst.Error != nil {
+			t.Errorf("step %s status = %s, error = %v, want completed without error", stepInst.StepID, stepInst.Status, stepInst.Error)
+		}
+	}
+}
+
+func TestOrchestrator_RedriveRerunsDependentsOfOptionalFailure(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	enrichUp := false
+	reportRuns := 0
+	var reportInput map[string]interface{}
+	workflow, _ := NewWorkflowBuilder("redrive-optional", "Redrive Optional").
+		AddStepFunc("enrich", "Enrich", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			if !enrichUp {
+				return nil, errors.New("enrichment service unavailable")
+			}
+			return map[string]interface{}{"score": 7}, nil
+		}, WithStepRequired(false)).
+		AddStepFunc("report", "Report", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			reportRuns++
+			reportInput = input
+			return map[string]interface{}{"reported": true}, nil
+		}, WithStepDeps("enrich")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "redrive-optional", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartWorkflow() success = %v, error = %v", result.Success, err)
+	}
+
+	// The report ran without the enrichment, so it runs again once enrichment works
+	enrichUp = true
+	if _, err := orchestrator.RedriveFailedSteps(ctx, result.WorkflowInst.ID); err != nil {
+		t.Fatalf("RedriveFailedSteps() error = %v", err)
+	}
+	if reportRuns != 2 {
+		t.Errorf("report ran %d times, want 2", reportRuns)
+	}
+	if reportInput["score"] != 7 {
+		t.Errorf("report input = %v, want the enrichment score", reportInput)
+	}
+}
+
+func TestOrchestrator_RedriveTimedOutOptionalStep(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	// The timed-out executor is abandoned and may still be returning during the redrive
+	var slow atomic.Bool
+	var runs atomic.Int32
+	slow.Store(true)
+	workflow, _ := NewWorkflowBuilder("redrive-timeout", "Redrive Timeout").
+		AddStepFunc("lookup", "Lookup", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs.Add(1)
+			if slow.Load() {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return map[string]interface{}{"found": true}, nil
+		}, WithStepRequired(false), WithStepTimeout(20*time.Millisecond)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "redrive-timeout", nil, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartWorkflow() success = %v, error = %v", result.Success, err)
+	}
+
+	// The optional step was skipped for timing out, so a redrive runs it again
+	slow.Store(false)
+	redriven, err := orchestrator.RedriveFailedSteps(ctx, result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("RedriveFailedSteps() error = %v", err)
+	}
+	if n := runs.Load(); n != 2 {
+		t.Errorf("lookup ran %d times, want 2", n)
+	}
+	if redriven.Output["found"] != true {
+		t.Errorf("workflow output = %v, want the redriven lookup output", redriven.Output)
+	}
+}
+
+func TestOrchestrator_RedriveSuccessfulWorkflowIsNoop(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	runs := 0
+	workflow, _ := NewWorkflowBuilder("redrive-noop", "Redrive No-op").
+		AddStepFunc("only", "Only", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs++
+			return map[string]interface{}{"done": true}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx := context.Background()
+	result, err := orchestrator.StartWorkflow(ctx, "redrive-noop", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	redriven, err := orchestrator.RedriveFailedSteps(ctx, result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("RedriveFailedSteps() error = %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("step ran %d times, want 1", runs)
+	}
+	if !redriven.Success || redriven.Output["done"] != true {
+		t.Errorf("RedriveFailedSteps() = %+v, want the existing successful result", redriven)
+	}
+
+	events, _ := sm.GetWorkflowEvents(ctx, result.WorkflowInst.ID)
+	for _, event := range events {
+		if event.EventType == EventWorkflowRedriven {
+			t.Error("a no-op redrive should not record a redriven event")
+		}
+	}
+}
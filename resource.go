@@ -0,0 +1,186 @@
+package orchwf
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// resourcePool is a weighted semaphore shared by every step that declares the same resource.
+// Waiters are served in priority order, FIFO within a priority, so a heavy step is not
+// starved by lighter ones.
+type resourcePool struct {
+	name     string
+	capacity int
+	used     int
+	waiters  list.List
+	mu       sync.Mutex
+}
+
+// resourceWaiter is a pending acquisition
+type resourceWaiter struct {
+	weight   int
+	priority int
+	ready    chan struct{}
+}
+
+// newResourcePool creates a pool with the given capacity
+func newResourcePool(name string, capacity int) *resourcePool {
+	return &resourcePool{
+		name:     name,
+		capacity: capacity,
+	}
+}
+
+// acquire blocks until weight units are available or ctx is done
+func (p *resourcePool) acquire(ctx context.Context, weight int) error {
+	return p.acquirePriority(ctx, weight, 0)
+}
+
+// acquirePriority is acquire with the waiter queued ahead of those with a lower priority
+func (p *resourcePool) acquirePriority(ctx context.Context, weight, priority int) error {
+	if weight > p.capacity {
+		return fmt.Errorf("resource %s: weight %d exceeds capacity %d", p.name, weight, p.capacity)
+	}
+
+	p.mu.Lock()
+	if p.waiters.Len() == 0 && p.used+weight <= p.capacity {
+		p.used += weight
+		p.mu.Unlock()
+		return nil
+	}
+
+	waiter := &resourceWaiter{weight: weight, priority: priority, ready: make(chan struct{})}
+	var elem *list.Element
+	for e := p.waiters.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*resourceWaiter).priority >= priority {
+			elem = p.waiters.InsertAfter(waiter, e)
+			break
+		}
+	}
+	if elem == nil {
+		// Jumping the queue; the new front may fit where the old one did not
+		elem = p.waiters.PushFront(waiter)
+		p.notifyWaiters()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		select {
+		case <-waiter.ready:
+			// Acquired while being cancelled; give the units back
+			p.used -= weight
+			p.notifyWaiters()
+		default:
+			isFront := p.waiters.Front() == elem
+			p.waiters.Remove(elem)
+			// A blocked front waiter may have been holding back smaller ones
+			if isFront {
+				p.notifyWaiters()
+			}
+		}
+		p.mu.Unlock()
+		return fmt.Errorf("resource %s: %w", p.name, ctx.Err())
+	}
+}
+
+// tryAcquire takes weight units if they are free and nobody is waiting, without blocking
+func (p *resourcePool) tryAcquire(weight int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.waiters.Len() == 0 && p.used+weight <= p.capacity {
+		p.used += weight
+		return true
+	}
+	return false
+}
+
+// release returns weight units to the pool
+func (p *resourcePool) release(weight int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.used -= weight
+	p.notifyWaiters()
+}
+
+// usage returns the units in use and the number of pending acquisitions
+func (p *resourcePool) usage() (used, waiting int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.used, p.waiters.Len()
+}
+
+// notifyWaiters grants units to waiters in order while capacity allows (caller holds mu)
+func (p *resourcePool) notifyWaiters() {
+	for {
+		front := p.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		waiter := front.Value.(*resourceWaiter)
+		if p.used+waiter.weight > p.capacity {
+			return
+		}
+
+		p.used += waiter.weight
+		p.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
+
+// WithResourcePool registers a named pool of capacity units that steps can share via
+// StepBuilder.WithResource. A step holds its weight while its executor runs.
+func WithResourcePool(name string, capacity int) Option {
+	return func(o *Orchestrator) {
+		if o.resources == nil {
+			o.resources = make(map[string]*resourcePool)
+		}
+		o.resources[name] = newResourcePool(name, capacity)
+	}
+}
+
+// validateResources checks that every step resource refers to a pool that can hold its weight
+func (o *Orchestrator) validateResources(workflow *WorkflowDefinition) error {
+	steps := make([]*StepDefinition, 0, len(workflow.Steps))
+	for _, step := range workflow.Steps {
+		steps = append(steps, step)
+		steps = append(steps, step.Alternatives...)
+	}
+
+	for _, step := range steps {
+		if step.Resource == "" {
+			continue
+		}
+
+		pool, ok := o.resources[step.Resource]
+		if !ok {
+			return fmt.Errorf("step %s uses unknown resource pool: %s", step.ID, step.Resource)
+		}
+		if step.ResourceWeight > pool.capacity {
+			return fmt.Errorf("step %s resource weight %d exceeds %s capacity %d", step.ID, step.ResourceWeight, step.Resource, pool.capacity)
+		}
+	}
+	return nil
+}
+
+// executeLimitedStep executes a step while holding a slot of the workflow's step limit, if any
+func (o *Orchestrator) executeLimitedStep(ctx context.Context, limit *resourcePool, stepDef *StepDefinition, stepInst *StepInstance, workflowInst *WorkflowInstance, stepInstMap map[string]*StepInstance) error {
+	if limit != nil {
+		leaveGate := o.waitAtGate(stepInst.ID, stepGate{blocker: StepBlockerStepLimit})
+		err := limit.acquire(ctx, 1)
+		leaveGate()
+		if err != nil {
+			return err
+		}
+		defer limit.release(1)
+	}
+	return o.executeStep(ctx, stepDef, stepInst, workflowInst, stepInstMap)
+}
@@ -0,0 +1,165 @@
+package orchwf
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResourcePool_AcquireRelease(t *testing.T) {
+	pool := newResourcePool("db", 3)
+
+	if err := pool.acquire(context.Background(), 2); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// A second acquisition of 2 must wait until the first is released
+	acquired := make(chan struct{})
+	go func() {
+		pool.acquire(context.Background(), 2)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatalf("acquire() should block while capacity is held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	pool.release(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("acquire() should succeed after release")
+	}
+
+	if err := pool.acquire(context.Background(), 4); err == nil {
+		t.Errorf("acquire() should reject weight above capacity")
+	}
+}
+
+func TestResourcePool_AcquireCancelled(t *testing.T) {
+	pool := newResourcePool("db", 1)
+	pool.acquire(context.Background(), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.acquire(ctx, 1); err == nil {
+		t.Fatalf("acquire() should fail when the context is done")
+	}
+
+	pool.release(1)
+	if err := pool.acquire(context.Background(), 1); err != nil {
+		t.Errorf("acquire() after cancelled waiter error = %v", err)
+	}
+}
+
+func TestOrchestrator_SharedResourcePool(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithResourcePool("license", 1))
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	}
+
+	workflow, _ := NewWorkflowBuilder("resource-workflow", "Resource Workflow").
+		AddStepFunc("render_a", "Render A", executor, WithStepAsync(true), WithStepResource("license", 1)).
+		AddStepFunc("render_b", "Render B", executor, WithStepAsync(true), WithStepResource("license", 1)).
+		AddStepFunc("export", "Export", executor, WithStepAsync(true), WithStepResource("license", 1)).
+		Build()
+	if err := orchestrator.RegisterWorkflow(workflow); err != nil {
+		t.Fatalf("RegisterWorkflow() error = %v", err)
+	}
+
+	if _, err := orchestrator.StartWorkflow(context.Background(), "resource-workflow", nil, nil); err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if maxRunning != 1 {
+		t.Errorf("steps sharing a pool of capacity 1 ran concurrently: max %d", maxRunning)
+	}
+}
+
+func TestOrchestrator_MaxConcurrentStepsAcrossInstances(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	}
+
+	workflow, _ := NewWorkflowBuilder("heavy", "Heavy").
+		WithMaxConcurrentSteps(2).
+		AddStepFunc("a", "A", executor, WithStepAsync(true)).
+		AddStepFunc("b", "B", executor, WithStepAsync(true)).
+		AddStepFunc("c", "C", executor, WithStepAsync(true)).
+		AddStepFunc("d", "D", executor).
+		Build()
+	if err := orchestrator.RegisterWorkflow(workflow); err != nil {
+		t.Fatalf("RegisterWorkflow() error = %v", err)
+	}
+
+	// Two instances would run up to six steps at once without the limit
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := orchestrator.StartWorkflow(context.Background(), "heavy", nil, nil); err != nil {
+				t.Errorf("StartWorkflow() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 2 {
+		t.Errorf("max concurrent steps across instances = %d, want 2", maxRunning)
+	}
+
+	if _, err := NewWorkflowBuilder("negative", "Negative").
+		WithMaxConcurrentSteps(-1).
+		AddStepFunc("a", "A", executor).
+		Build(); err == nil {
+		t.Errorf("Build() should reject a negative step limit")
+	}
+}
+
+func TestOrchestrator_RegisterWorkflowValidatesResources(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithResourcePool("db", 2))
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	}
+
+	unknown, _ := NewWorkflowBuilder("unknown", "Unknown").
+		AddStepFunc("step", "Step", executor, WithStepResource("gpu", 1)).
+		Build()
+	if err := orchestrator.RegisterWorkflow(unknown); err == nil {
+		t.Errorf("RegisterWorkflow() should reject unknown resource pools")
+	}
+
+	tooHeavy, _ := NewWorkflowBuilder("heavy", "Heavy").
+		AddStepFunc("step", "Step", executor, WithStepResource("db", 3)).
+		Build()
+	if err := orchestrator.RegisterWorkflow(tooHeavy); err == nil {
+		t.Errorf("RegisterWorkflow() should reject weights above pool capacity")
+	}
+}
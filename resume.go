@@ -0,0 +1,124 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// RetryAccounting decides how attempts made before a resume count against the retry policy
+// of a step that was interrupted mid-run. Steps that already failed are not re-run by a
+// resume; RedriveFailedSteps re-runs them with their full retry budget.
+type RetryAccounting string
+
+const (
+	// ContinueRetries resumes a step's retry budget where the interrupted run left off
+	ContinueRetries RetryAccounting = "continue"
+	// FreshRetries resets a step's retry count so it gets its full retry budget again
+	FreshRetries RetryAccounting = "fresh"
+)
+
+// ResumeOption configures a ResumeWorkflow call
+type ResumeOption func(*resumeConfig)
+
+// resumeConfig holds the settings of a ResumeWorkflow call
+type resumeConfig struct {
+	retries RetryAccounting
+}
+
+// WithResumeRetries sets how the retries an interrupted step used before the resume are
+// counted. The default is ContinueRetries.
+func WithResumeRetries(mode RetryAccounting) ResumeOption {
+	return func(c *resumeConfig) {
+		c.retries = mode
+	}
+}
+
+// resetStepRetries clears the retry count of every step that has not finished yet. Failed
+// steps keep theirs, as the resume does not run them again.
+func (o *Orchestrator) resetStepRetries(ctx context.Context, instance *WorkflowInstance) error {
+	for _, stepInst := range instance.Steps {
+		if stepInst.IsCompleted() || stepInst.RetryCount == 0 {
+			continue
+		}
+		if err := o.stateManager.UpdateStepRetry(ctx, stepInst.ID, 0, nil); err != nil {
+			return fmt.Errorf("failed to reset retries of step %s: %w", stepInst.StepID, err)
+		}
+		stepInst.RetryCount = 0
+		stepInst.LastRetryAt = nil
+	}
+	return nil
+}
+
+// interruptedStatuses are the statuses an instance is left in when its process stops mid-run
+var interruptedStatuses = []WorkflowStatus{WorkflowStatusPending, WorkflowStatusQueued, WorkflowStatusRunning, WorkflowStatusRetrying}
+
+// ResumeInterrupted resumes every instance left pending, queued, running or retrying by a process
+// that stopped mid-run, such as after a crash. Call it at startup, before this process
+// starts workflows of its own, and on a shared database from one process only, as it
+// cannot tell an interrupted instance from one another process is running. Instances of
+// unregistered workflows are skipped with a logged warning. Steps left running or retrying
+// run again, keeping the retries they used unless opts say otherwise; completed steps keep
+// their output. At most WithAsyncWorkers instances are resumed at once. It returns the
+// number of instances that were resumed without error.
+func (o *Orchestrator) ResumeInterrupted(ctx context.Context, opts ...ResumeOption) (int, error) {
+	ids, err := o.interruptedInstances(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	workers := o.asyncWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var resumed atomic.Int64
+	for _, id := range ids {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if _, err := o.ResumeWorkflow(ctx, id, opts...); err != nil {
+				o.logger.Printf("orchwf: failed to resume interrupted workflow %s: %v", id, err)
+				return
+			}
+			resumed.Add(1)
+		}(id)
+	}
+	wg.Wait()
+	return int(resumed.Load()), nil
+}
+
+// interruptedInstances lists the IDs of interrupted instances of registered workflows,
+// leaving out instances this process is running
+func (o *Orchestrator) interruptedInstances(ctx context.Context) ([]string, error) {
+	const pageSize = 100
+
+	var ids []string
+	for _, status := range interruptedStatuses {
+		for offset := 0; ; offset += pageSize {
+			page, _, err := o.stateManager.ListWorkflows(WithoutTotal(ctx), map[string]interface{}{"status": status}, pageSize, offset)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s workflows: %w", status, err)
+			}
+			for _, instance := range page {
+				if _, ok := o.running.Load(instance.ID); ok {
+					continue
+				}
+				if _, err := o.GetWorkflow(instance.WorkflowID); err != nil {
+					o.logger.Printf("orchwf: skipping interrupted workflow %s: %v", instance.ID, err)
+					continue
+				}
+				ids = append(ids, instance.ID)
+			}
+			if len(page) < pageSize {
+				break
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,213 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestOrchestrator_ResumeRetryAccounting(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ResumeOption
+		wantRuns  int
+		wantError string
+	}{
+		{"continue by default", nil, 2, "failed after 4 attempts"},
+		{"continue", []ResumeOption{WithResumeRetries(ContinueRetries)}, 2, "failed after 4 attempts"},
+		{"fresh", []ResumeOption{WithResumeRetries(FreshRetries)}, 4, "failed after 4 attempts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewInMemoryStateManager()
+			orchestrator := NewOrchestrator(sm)
+
+			runs := 0
+			workflow, _ := NewWorkflowBuilder("resume-retries", "Resume Retries").
+				AddStepFunc("flaky", "Flaky", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					runs++
+					return nil, errors.New("transient failure")
+				}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 4, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1})).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			// The process stopped while the step was on its third attempt
+			lastRetryAt := time.Now().Add(-time.Minute)
+			instance := &WorkflowInstance{
+				ID:         uuid.New().String(),
+				WorkflowID: "resume-retries",
+				Status:     WorkflowStatusRunning,
+				StartedAt:  time.Now(),
+			}
+			step := &StepInstance{
+				ID:             uuid.New().String(),
+				StepID:         "flaky",
+				WorkflowInstID: instance.ID,
+				Status:         StepStatusRetrying,
+				RetryCount:     2,
+				LastRetryAt:    &lastRetryAt,
+			}
+			ctx := context.Background()
+			sm.SaveWorkflow(ctx, instance)
+			sm.SaveStep(ctx, step)
+
+			_, err := orchestrator.ResumeWorkflow(ctx, instance.ID, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Fatalf("ResumeWorkflow() error = %v, want %q", err, tt.wantError)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("executor ran %d times, want %d", runs, tt.wantRuns)
+			}
+
+			// The persisted count matches the attempts actually made
+			saved, err := sm.GetStep(ctx, step.ID)
+			if err != nil {
+				t.Fatalf("GetStep() error = %v", err)
+			}
+			if saved.RetryCount != 3 {
+				t.Errorf("persisted RetryCount = %d, want 3", saved.RetryCount)
+			}
+			if saved.LastRetryAt == nil || !saved.LastRetryAt.After(lastRetryAt) {
+				t.Errorf("persisted LastRetryAt = %v, want a time after %v", saved.LastRetryAt, lastRetryAt)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_ResumeWithoutAttemptsLeft(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	runs := 0
+	workflow, _ := NewWorkflowBuilder("exhausted", "Exhausted").
+		AddStepFunc("step", "Step", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs++
+			return map[string]interface{}{}, nil
+		}, WithStepRetryPolicy(&RetryPolicy{MaxAttempts: 2, Multiplier: 1})).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	instance := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "exhausted", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+	ctx := context.Background()
+	sm.SaveWorkflow(ctx, instance)
+	sm.SaveStep(ctx, &StepInstance{ID: uuid.New().String(), StepID: "step", WorkflowInstID: instance.ID, Status: StepStatusRetrying, RetryCount: 2})
+
+	if _, err := orchestrator.ResumeWorkflow(ctx, instance.ID); err == nil || !strings.Contains(err.Error(), "no attempts left") {
+		t.Errorf("ResumeWorkflow() error = %v, want no attempts left", err)
+	}
+	if runs != 0 {
+		t.Errorf("executor ran %d times, want 0", runs)
+	}
+}
+
+func TestOrchestrator_ResumeLeavesFailedStepsAlone(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+
+	runs := make(map[string]int)
+	step := func(id string) StepExecutor {
+		return func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			runs[id]++
+			return map[string]interface{}{}, nil
+		}
+	}
+	policy := &RetryPolicy{MaxAttempts: 3, Multiplier: 1}
+	workflow, _ := NewWorkflowBuilder("resume-failed", "Resume Failed").
+		AddStepFunc("failed", "Failed", step("failed"), WithStepRetryPolicy(policy), WithStepRequired(false)).
+		AddStepFunc("interrupted", "Interrupted", step("interrupted"), WithStepRetryPolicy(policy)).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	// One step failed for good before the process stopped, the other was mid-retry
+	instance := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "resume-failed", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+	failed := &StepInstance{ID: uuid.New().String(), StepID: "failed", WorkflowInstID: instance.ID, Status: StepStatusFailed, RetryCount: 2}
+	interrupted := &StepInstance{ID: uuid.New().String(), StepID: "interrupted", WorkflowInstID: instance.ID, Status: StepStatusRetrying, RetryCount: 1}
+	ctx := context.Background()
+	sm.SaveWorkflow(ctx, instance)
+	sm.SaveStep(ctx, failed)
+	sm.SaveStep(ctx, interrupted)
+
+	orchestrator.ResumeWorkflow(ctx, instance.ID, WithResumeRetries(FreshRetries))
+
+	// Only the interrupted step runs again; the failed one keeps its status and count
+	if runs["failed"] != 0 || runs["interrupted"] != 1 {
+		t.Errorf("runs = %v, want only the interrupted step run", runs)
+	}
+	saved, _ := sm.GetStep(ctx, failed.ID)
+	if saved.Status != StepStatusFailed || saved.RetryCount != 2 {
+		t.Errorf("failed step = %s with RetryCount %d, want it left failed with 2", saved.Status, saved.RetryCount)
+	}
+}
+
+func TestOrchestrator_ResumeInterrupted(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	// A new process registers the workflow that an earlier one was running when it crashed
+	var reserveRuns, chargeRuns atomic.Int32
+	workflow, _ := NewWorkflowBuilder("checkout", "Checkout").
+		AddStepFunc("reserve", "Reserve", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			reserveRuns.Add(1)
+			return map[string]interface{}{"reservation": "new"}, nil
+		}).
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			chargeRuns.Add(1)
+			return map[string]interface{}{"charged": input["reservation"]}, nil
+		}, WithStepDeps("reserve")).
+		Build()
+	orchestrator := NewOrchestrator(sm)
+	orchestrator.RegisterWorkflow(workflow)
+
+	// Three checkouts were cut off mid-step, one never started and one belongs to a
+	// workflow this process no longer registers
+	var interrupted []string
+	for i := 0; i < 3; i++ {
+		instance := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "checkout", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+		sm.SaveWorkflow(ctx, instance)
+		sm.SaveSteps(ctx, []*StepInstance{
+			{ID: uuid.New().String(), StepID: "reserve", WorkflowInstID: instance.ID, Status: StepStatusCompleted, Output: map[string]interface{}{"reservation": "r1"}, ExecutionOrder: 0},
+			{ID: uuid.New().String(), StepID: "charge", WorkflowInstID: instance.ID, Status: StepStatusRunning, ExecutionOrder: 1},
+		})
+		interrupted = append(interrupted, instance.ID)
+	}
+	pending := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "checkout", Status: WorkflowStatusPending, StartedAt: time.Now()}
+	sm.SaveWorkflow(ctx, pending)
+	retired := &WorkflowInstance{ID: uuid.New().String(), WorkflowID: "retired", Status: WorkflowStatusRunning, StartedAt: time.Now()}
+	sm.SaveWorkflow(ctx, retired)
+
+	resumed, err := orchestrator.ResumeInterrupted(ctx)
+	if err != nil {
+		t.Fatalf("ResumeInterrupted() error = %v", err)
+	}
+	if resumed != 4 {
+		t.Errorf("ResumeInterrupted() = %d, want 4", resumed)
+	}
+
+	// Completed steps keep their output; the step left running runs again
+	if reserveRuns.Load() != 1 || chargeRuns.Load() != 4 {
+		t.Errorf("reserve ran %d times and charge %d times, want 1 and 4", reserveRuns.Load(), chargeRuns.Load())
+	}
+	for _, id := range interrupted {
+		instance, _ := sm.GetWorkflow(ctx, id)
+		if instance.Status != WorkflowStatusCompleted || instance.Output["charged"] != "r1" {
+			t.Errorf("instance %s = %s with output %v, want completed using the saved reservation", id, instance.Status, instance.Output)
+		}
+	}
+	if instance, _ := sm.GetWorkflow(ctx, pending.ID); instance.Status != WorkflowStatusCompleted {
+		t.Errorf("pending instance status = %s, want completed", instance.Status)
+	}
+	if instance, _ := sm.GetWorkflow(ctx, retired.ID); instance.Status != WorkflowStatusRunning {
+		t.Errorf("unregistered instance status = %s, want it left running", instance.Status)
+	}
+
+	// Nothing is left to resume
+	if resumed, _ := orchestrator.ResumeInterrupted(ctx); resumed != 0 {
+		t.Errorf("second ResumeInterrupted() = %d, want 0", resumed)
+	}
+}
@@ -0,0 +1,116 @@
+package orchwf
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SnapshotFormat selects the encoding used by InMemoryStateManager.Snapshot and Restore
+type SnapshotFormat int
+
+const (
+	// SnapshotJSON is readable and portable. Values inside input, output, context and
+	// metadata maps come back as JSON types, as they do from the database state manager.
+	SnapshotJSON SnapshotFormat = iota
+	// SnapshotGob is smaller and faster for large state and keeps the Go types of map
+	// values. Custom types stored in those maps must be passed to gob.Register.
+	SnapshotGob
+)
+
+// Types the orchestrator itself stores in map values, registered so gob can encode them
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register([]string{})
+	gob.Register(time.Time{})
+}
+
+// snapshot is the encoded form of the in-memory state
+type snapshot struct {
+	Workflows []*WorkflowInstance `json:"workflows"`
+	Steps     []*StepInstance     `json:"steps"`
+	Events    []*WorkflowEvent    `json:"events"`
+}
+
+// Snapshot writes every workflow, step and event held in memory to w in the given format
+func (m *InMemoryStateManager) Snapshot(w io.Writer, format SnapshotFormat) error {
+	m.mu.RLock()
+	snap := snapshot{
+		Workflows: make([]*WorkflowInstance, 0, len(m.workflows)),
+		Steps:     make([]*StepInstance, 0, len(m.steps)),
+		Events:    make([]*WorkflowEvent, 0, len(m.events)),
+	}
+	for _, workflow := range m.workflows {
+		snap.Workflows = append(snap.Workflows, m.deepCopyWorkflow(workflow))
+	}
+	for _, step := range m.steps {
+		snap.Steps = append(snap.Steps, m.deepCopyStep(step))
+	}
+	for _, event := range m.events {
+		snap.Events = append(snap.Events, m.deepCopyEvent(event))
+	}
+	m.mu.RUnlock()
+
+	// Sort so the same state always encodes the same way
+	sort.Slice(snap.Workflows, func(i, j int) bool { return snap.Workflows[i].ID < snap.Workflows[j].ID })
+	sort.Slice(snap.Steps, func(i, j int) bool { return snap.Steps[i].ID < snap.Steps[j].ID })
+	sort.Slice(snap.Events, func(i, j int) bool { return snap.Events[i].ID < snap.Events[j].ID })
+
+	switch format {
+	case SnapshotJSON:
+		return json.NewEncoder(w).Encode(snap)
+	case SnapshotGob:
+		return gob.NewEncoder(w).Encode(snap)
+	default:
+		return fmt.Errorf("unknown snapshot format %d", format)
+	}
+}
+
+// Restore replaces the state held in memory with a snapshot read from r. The format
+// must match the one the snapshot was written in. On error the state is left unchanged.
+func (m *InMemoryStateManager) Restore(r io.Reader, format SnapshotFormat) error {
+	var snap snapshot
+	var err error
+	switch format {
+	case SnapshotJSON:
+		err = json.NewDecoder(r).Decode(&snap)
+	case SnapshotGob:
+		err = gob.NewDecoder(r).Decode(&snap)
+	default:
+		return fmt.Errorf("unknown snapshot format %d", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	// Copying gives every record non-nil maps, which gob does not keep when empty
+	restored := NewInMemoryStateManager()
+	for _, workflow := range snap.Workflows {
+		workflowCopy := restored.deepCopyWorkflow(workflow)
+		restored.workflows[workflow.ID] = workflowCopy
+		restored.indexWorkflow(workflowCopy)
+	}
+	for _, step := range snap.Steps {
+		restored.putStep(restored.deepCopyStep(step))
+	}
+	for _, event := range snap.Events {
+		restored.events[event.ID] = restored.deepCopyEvent(event)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.workflows = restored.workflows
+	m.steps = restored.steps
+	m.events = restored.events
+	m.workflowsByDef = restored.workflowsByDef
+	m.workflowsByStatus = restored.workflowsByStatus
+	m.workflowsByBusiness = restored.workflowsByBusiness
+	m.stepsByWorkflow = restored.stepsByWorkflow
+	return nil
+}
@@ -0,0 +1,137 @@
+package orchwf
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// runSnapshotWorkflow runs a two-step workflow whose output holds a time.Time
+func runSnapshotWorkflow(t *testing.T, sm *InMemoryStateManager, at time.Time) string {
+	t.Helper()
+	orchestrator := NewOrchestrator(sm)
+	workflow, _ := NewWorkflowBuilder("snapshot", "Snapshot").
+		AddStepFunc("stamp", "Stamp", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"at": at, "count": 3}, nil
+		}).
+		AddStepFunc("done", "Done", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("stamp")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "snapshot", map[string]interface{}{"order_id": "o1"}, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	return result.WorkflowInst.ID
+}
+
+func TestInMemoryStateManager_SnapshotRestore(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		format SnapshotFormat
+		wantAt interface{} // How the time stored in the step output comes back
+	}{
+		{"json", SnapshotJSON, at.Format(time.RFC3339Nano)},
+		{"gob", SnapshotGob, at},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sm := NewInMemoryStateManager()
+			id := runSnapshotWorkflow(t, sm, at)
+
+			var buf bytes.Buffer
+			if err := sm.Snapshot(&buf, tt.format); err != nil {
+				t.Fatalf("Snapshot() error = %v", err)
+			}
+			restored := NewInMemoryStateManager()
+			if err := restored.Restore(&buf, tt.format); err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			want, _ := sm.GetWorkflow(ctx, id)
+			got, err := restored.GetWorkflow(ctx, id)
+			if err != nil {
+				t.Fatalf("GetWorkflow() after Restore error = %v", err)
+			}
+			if got.Status != WorkflowStatusCompleted || got.Input["order_id"] != "o1" {
+				t.Errorf("restored workflow = %+v, want completed with its input", got)
+			}
+			if !got.StartedAt.Equal(want.StartedAt) || got.CompletedAt == nil || !got.CompletedAt.Equal(*want.CompletedAt) {
+				t.Errorf("restored times = %v/%v, want %v/%v", got.StartedAt, got.CompletedAt, want.StartedAt, want.CompletedAt)
+			}
+
+			steps, _ := restored.GetWorkflowSteps(ctx, id)
+			if len(steps) != 2 {
+				t.Fatalf("restored steps = %d, want 2", len(steps))
+			}
+			stamp := steps[0]
+			if stamp.StartedAt == nil || stamp.CompletedAt == nil {
+				t.Errorf("restored step times = %v/%v, want both set", stamp.StartedAt, stamp.CompletedAt)
+			}
+			if stamp.Output["at"] != tt.wantAt {
+				t.Errorf("restored output at = %#v, want %#v", stamp.Output["at"], tt.wantAt)
+			}
+
+			wantEvents, _ := sm.GetWorkflowEvents(ctx, id)
+			gotEvents, _ := restored.GetWorkflowEvents(ctx, id)
+			if len(gotEvents) != len(wantEvents) {
+				t.Errorf("restored events = %d, want %d", len(gotEvents), len(wantEvents))
+			}
+
+			// Indexes are rebuilt, so filtered listing works on the restored state
+			if _, total, _ := restored.ListWorkflows(ctx, map[string]interface{}{"status": WorkflowStatusCompleted}, 10, 0); total != 1 {
+				t.Errorf("ListWorkflows() after Restore total = %d, want 1", total)
+			}
+		})
+	}
+}
+
+func TestInMemoryStateManager_RestoreRejectsFormatMismatch(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	runSnapshotWorkflow(t, sm, time.Now())
+
+	var buf bytes.Buffer
+	if err := sm.Snapshot(&buf, SnapshotGob); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if err := sm.Restore(&buf, SnapshotJSON); err == nil {
+		t.Fatalf("Restore() should fail on a gob snapshot read as JSON")
+	}
+	if _, total, _ := sm.ListWorkflows(context.Background(), nil, 10, 0); total != 1 {
+		t.Errorf("ListWorkflows() after failed Restore total = %d, want the state unchanged", total)
+	}
+}
+
+func benchmarkSnapshot(b *testing.B, format SnapshotFormat) {
+	sm := newBenchmarkStateManager(b, 10000)
+	var buf bytes.Buffer
+	if err := sm.Snapshot(&buf, format); err != nil {
+		b.Fatalf("Snapshot() error = %v", err)
+	}
+	size := buf.Len()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := sm.Snapshot(&buf, format); err != nil {
+			b.Fatalf("Snapshot() error = %v", err)
+		}
+		if err := NewInMemoryStateManager().Restore(&buf, format); err != nil {
+			b.Fatalf("Restore() error = %v", err)
+		}
+	}
+	b.ReportMetric(float64(size), "bytes/snapshot")
+}
+
+func BenchmarkInMemoryStateManager_SnapshotJSON(b *testing.B) {
+	benchmarkSnapshot(b, SnapshotJSON)
+}
+
+func BenchmarkInMemoryStateManager_SnapshotGob(b *testing.B) {
+	benchmarkSnapshot(b, SnapshotGob)
+}
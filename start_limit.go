@@ -0,0 +1,100 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// StartLimitPolicy decides what happens when a workflow is started faster than its start limit
+type StartLimitPolicy string
+
+const (
+	StartLimitPolicyReject StartLimitPolicy = "reject" // Fail immediately with ErrStartRateLimited (default)
+	StartLimitPolicyWait   StartLimitPolicy = "wait"   // Wait until the limit allows another start
+)
+
+// WithWorkflowStartLimit caps how often new instances of a workflow are started, as a token
+// bucket refilled at perSecond that holds up to burst starts. It throttles StartWorkflow and
+// StartWorkflowAsync before the instance is created; resumes and redrives are not limited.
+// A perSecond of zero or less removes the limit.
+func WithWorkflowStartLimit(workflowID string, perSecond float64, burst int) Option {
+	return func(o *Orchestrator) {
+		if perSecond <= 0 {
+			delete(o.startLimits, workflowID)
+			return
+		}
+		if o.startLimits == nil {
+			o.startLimits = make(map[string]*startLimiter)
+		}
+		o.startLimits[workflowID] = newStartLimiter(perSecond, burst)
+	}
+}
+
+// WithStartLimitPolicy sets whether starts beyond a workflow's start limit fail or wait
+func WithStartLimitPolicy(policy StartLimitPolicy) Option {
+	return func(o *Orchestrator) {
+		o.startLimitPolicy = policy
+	}
+}
+
+// startLimiter is a token bucket of workflow starts
+type startLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64 // Most tokens the bucket holds
+	tokens float64
+	last   time.Time // When tokens was last refilled (zero = never taken, bucket full)
+}
+
+func newStartLimiter(perSecond float64, burst int) *startLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &startLimiter{rate: perSecond, burst: float64(burst), tokens: float64(burst)}
+}
+
+// take removes a token if one is available at now, or returns how long until one is
+func (l *startLimiter) take(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.last.IsZero() {
+		l.last = now
+	}
+	if now.After(l.last) {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - l.tokens) / l.rate * float64(time.Second)))
+}
+
+// waitStartLimit enforces the start limit of a workflow, if it has one
+func (o *Orchestrator) waitStartLimit(ctx context.Context, workflowID string) error {
+	limiter, ok := o.startLimits[workflowID]
+	if !ok {
+		return nil
+	}
+
+	for {
+		wait := limiter.take(o.clock.Now())
+		if wait == 0 {
+			return nil
+		}
+		if o.startLimitPolicy != StartLimitPolicyWait {
+			o.metrics.IncCounter("workflow.start_limited", map[string]string{"workflow_id": workflowID})
+			return fmt.Errorf("%w: %s, next start allowed in %v", ErrStartRateLimited, workflowID, wait)
+		}
+
+		if err := o.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a clock whose Sleep advances Now instead of waiting
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Sleep(d time.Duration) { c.advance(d) }
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newStartLimitWorkflow(t *testing.T, opts ...Option) *Orchestrator {
+	t.Helper()
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), opts...)
+	workflow, _ := NewWorkflowBuilder("ingest", "Ingest").
+		AddStepFunc("store", "Store", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+	return orchestrator
+}
+
+func TestOrchestrator_WorkflowStartLimitRejects(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	orchestrator := newStartLimitWorkflow(t, WithClock(clock), WithWorkflowStartLimit("ingest", 2, 3))
+	ctx := context.Background()
+
+	// The burst is available at once, then starts are rejected
+	for i := 0; i < 3; i++ {
+		if _, err := orchestrator.StartWorkflow(ctx, "ingest", nil, nil); err != nil {
+			t.Fatalf("start %d within the burst error = %v", i+1, err)
+		}
+	}
+	if _, err := orchestrator.StartWorkflowAsync(ctx, "ingest", nil, nil); !errors.Is(err, ErrStartRateLimited) {
+		t.Fatalf("start beyond the burst error = %v, want ErrStartRateLimited", err)
+	}
+
+	// At steady state, one start is allowed every 1/perSecond
+	for i := 0; i < 4; i++ {
+		clock.advance(500 * time.Millisecond)
+		if _, err := orchestrator.StartWorkflow(ctx, "ingest", nil, nil); err != nil {
+			t.Fatalf("steady-state start %d error = %v", i+1, err)
+		}
+		if _, err := orchestrator.StartWorkflow(ctx, "ingest", nil, nil); !errors.Is(err, ErrStartRateLimited) {
+			t.Fatalf("second start in the same interval error = %v, want ErrStartRateLimited", err)
+		}
+	}
+
+	// An idle period refills the bucket up to the burst only
+	clock.advance(time.Minute)
+	var started int
+	for {
+		if _, err := orchestrator.StartWorkflow(ctx, "ingest", nil, nil); err != nil {
+			break
+		}
+		started++
+	}
+	if started != 3 {
+		t.Errorf("starts after an idle minute = %d, want the burst of 3", started)
+	}
+}
+
+func TestOrchestrator_WorkflowStartLimitWaits(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	orchestrator := newStartLimitWorkflow(t, WithClock(clock),
+		WithWorkflowStartLimit("ingest", 10, 1), WithStartLimitPolicy(StartLimitPolicyWait))
+	begin := clock.Now()
+
+	for i := 0; i < 5; i++ {
+		if _, err := orchestrator.StartWorkflow(context.Background(), "ingest", nil, nil); err != nil {
+			t.Fatalf("start %d error = %v", i+1, err)
+		}
+	}
+
+	// The first start is free, the other four each wait 100ms
+	if waited := clock.Now().Sub(begin); waited != 400*time.Millisecond {
+		t.Errorf("time waited for 5 starts = %v, want 400ms", waited)
+	}
+}
+
+func TestOrchestrator_WorkflowStartLimitWaitHonoursContext(t *testing.T) {
+	orchestrator := newStartLimitWorkflow(t,
+		WithWorkflowStartLimit("ingest", 0.5, 1), WithStartLimitPolicy(StartLimitPolicyWait))
+	if _, err := orchestrator.StartWorkflow(context.Background(), "ingest", nil, nil); err != nil {
+		t.Fatalf("first start error = %v", err)
+	}
+
+	// The next start is two seconds away, but the caller gives up after 50ms
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	_, err := orchestrator.StartWorkflow(ctx, "ingest", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second start error = %v, want context.DeadlineExceeded", err)
+	}
+	if waited := time.Since(begin); waited > time.Second {
+		t.Errorf("second start returned after %v, want soon after its context ended", waited)
+	}
+}
@@ -3,6 +3,7 @@ package orchwf
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -15,22 +16,41 @@ type StateManager interface {
 	UpdateWorkflowStatus(ctx context.Context, workflowInstID string, status WorkflowStatus) error
 	UpdateWorkflowOutput(ctx context.Context, workflowInstID string, output map[string]interface{}) error
 	UpdateWorkflowError(ctx context.Context, workflowInstID string, err error) error
+	UpdateWorkflowMetadata(ctx context.Context, workflowInstID string, metadata map[string]interface{}) error
+	UpdateWorkflowCurrentStep(ctx context.Context, workflowInstID string, stepID string) error
 	ListWorkflows(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*WorkflowInstance, int64, error)
+	CountWorkflowsByStatus(ctx context.Context, filters map[string]interface{}) (map[WorkflowStatus]int64, error)
+	GetChildWorkflows(ctx context.Context, parentInstID string) ([]*WorkflowInstance, error)
 
 	// Step operations
 	SaveStep(ctx context.Context, step *StepInstance) error
+	SaveSteps(ctx context.Context, steps []*StepInstance) error
 	GetStep(ctx context.Context, stepInstID string) (*StepInstance, error)
 	GetWorkflowSteps(ctx context.Context, workflowInstID string) ([]*StepInstance, error)
 	UpdateStepStatus(ctx context.Context, stepInstID string, status StepStatus) error
 	UpdateStepOutput(ctx context.Context, stepInstID string, output map[string]interface{}) error
 	UpdateStepError(ctx context.Context, stepInstID string, err error) error
-
-	// Event operations
+	UpdateStepSkipReason(ctx context.Context, stepInstID string, reason SkipReason) error
+	UpdateStepFailureKind(ctx context.Context, stepInstID string, kind FailureKind) error
+	UpdateStepWait(ctx context.Context, stepInstID string, readyAt time.Time, waitMs int64) error
+	UpdateStepRetry(ctx context.Context, stepInstID string, retryCount int, lastRetryAt *time.Time) error
+	UpdateStepWakeAt(ctx context.Context, stepInstID string, wakeAt time.Time) error
+	UpdateStepAttachments(ctx context.Context, stepInstID string, attachments []Attachment) error
+	UpdateStepProvenance(ctx context.Context, stepInstID string, provenance map[string]string) error
+	ResetStep(ctx context.Context, stepInstID string) error
+	GetDueWaitingSteps(ctx context.Context, before time.Time) ([]*StepInstance, error)
+
+	// Event operations. Saving an event whose ID is already stored keeps the stored
+	// event and is not an error, so a retried flush cannot duplicate or fail on events.
 	SaveEvent(ctx context.Context, event *WorkflowEvent) error
 	GetWorkflowEvents(ctx context.Context, workflowInstID string) ([]*WorkflowEvent, error)
+	DeleteEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 
 	// Transaction support
 	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// Health check
+	Ping(ctx context.Context) error
 }
 
 // InMemoryStateManager implements StateManager using in-memory storage
@@ -39,6 +59,12 @@ type InMemoryStateManager struct {
 	steps     map[string]*StepInstance
 	events    map[string]*WorkflowEvent
 	mu        sync.RWMutex
+
+	// Secondary indexes, kept in step with the maps above
+	workflowsByDef      idIndex // Workflow definition ID -> instance IDs
+	workflowsByStatus   idIndex // Workflow status -> instance IDs
+	workflowsByBusiness idIndex // Business ID -> instance IDs
+	stepsByWorkflow     idIndex // Workflow instance ID -> step instance IDs
 }
 
 // NewInMemoryStateManager creates a new in-memory state manager
@@ -47,6 +73,11 @@ func NewInMemoryStateManager() *InMemoryStateManager {
 		workflows: make(map[string]*WorkflowInstance),
 		steps:     make(map[string]*StepInstance),
 		events:    make(map[string]*WorkflowEvent),
+
+		workflowsByDef:      make(idIndex),
+		workflowsByStatus:   make(idIndex),
+		workflowsByBusiness: make(idIndex),
+		stepsByWorkflow:     make(idIndex),
 	}
 }
 
@@ -57,7 +88,11 @@ func (m *InMemoryStateManager) SaveWorkflow(ctx context.Context, workflow *Workf
 
 	// Deep copy to avoid race conditions
 	workflowCopy := m.deepCopyWorkflow(workflow)
+	if existing, ok := m.workflows[workflow.ID]; ok {
+		m.unindexWorkflow(existing)
+	}
 	m.workflows[workflow.ID] = workflowCopy
+	m.indexWorkflow(workflowCopy)
 	return nil
 }
 
@@ -68,11 +103,18 @@ func (m *InMemoryStateManager) GetWorkflow(ctx context.Context, workflowInstID s
 
 	workflow, ok := m.workflows[workflowInstID]
 	if !ok {
-		return nil, fmt.Errorf("workflow not found: %s", workflowInstID)
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
 	}
 
 	// Deep copy to avoid race conditions
-	return m.deepCopyWorkflow(workflow), nil
+	workflowCopy := m.deepCopyWorkflow(workflow)
+
+	// Steps are saved separately, so load them like the database state manager does
+	if steps := m.workflowSteps(workflowInstID); len(steps) > 0 {
+		workflowCopy.Steps = steps
+	}
+
+	return workflowCopy, nil
 }
 
 // UpdateWorkflowStatus updates the status of a workflow
@@ -82,10 +124,10 @@ func (m *InMemoryStateManager) UpdateWorkflowStatus(ctx context.Context, workflo
 
 	workflow, ok := m.workflows[workflowInstID]
 	if !ok {
-		return fmt.Errorf("workflow not found: %s", workflowInstID)
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
 	}
 
-	workflow.Status = status
+	m.setWorkflowStatus(workflow, status)
 	if status == WorkflowStatusCompleted || status == WorkflowStatusFailed || status == WorkflowStatusCancelled {
 		now := time.Now()
 		workflow.CompletedAt = &now
@@ -101,7 +143,7 @@ func (m *InMemoryStateManager) UpdateWorkflowOutput(ctx context.Context, workflo
 
 	workflow, ok := m.workflows[workflowInstID]
 	if !ok {
-		return fmt.Errorf("workflow not found: %s", workflowInstID)
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
 	}
 
 	workflow.Output = make(map[string]interface{})
@@ -112,6 +154,40 @@ func (m *InMemoryStateManager) UpdateWorkflowOutput(ctx context.Context, workflo
 	return nil
 }
 
+// UpdateWorkflowMetadata merges metadata into the stored metadata of a workflow
+func (m *InMemoryStateManager) UpdateWorkflowMetadata(ctx context.Context, workflowInstID string, metadata map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	workflow, ok := m.workflows[workflowInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
+	}
+
+	if workflow.Metadata == nil {
+		workflow.Metadata = make(map[string]interface{})
+	}
+	for k, v := range metadata {
+		workflow.Metadata[k] = v
+	}
+
+	return nil
+}
+
+// UpdateWorkflowCurrentStep records the step a workflow most recently started
+func (m *InMemoryStateManager) UpdateWorkflowCurrentStep(ctx context.Context, workflowInstID string, stepID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	workflow, ok := m.workflows[workflowInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
+	}
+
+	workflow.CurrentStepID = stepID
+	return nil
+}
+
 // UpdateWorkflowError updates the error of a workflow
 func (m *InMemoryStateManager) UpdateWorkflowError(ctx context.Context, workflowInstID string, err error) error {
 	m.mu.Lock()
@@ -119,12 +195,12 @@ func (m *InMemoryStateManager) UpdateWorkflowError(ctx context.Context, workflow
 
 	workflow, ok := m.workflows[workflowInstID]
 	if !ok {
-		return fmt.Errorf("workflow not found: %s", workflowInstID)
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowInstID)
 	}
 
 	errorMsg := err.Error()
 	workflow.Error = &errorMsg
-	workflow.Status = WorkflowStatusFailed
+	m.setWorkflowStatus(workflow, WorkflowStatusFailed)
 	now := time.Now()
 	workflow.CompletedAt = &now
 
@@ -137,43 +213,14 @@ func (m *InMemoryStateManager) ListWorkflows(ctx context.Context, filters map[st
 	defer m.mu.RUnlock()
 
 	var results []*WorkflowInstance
-	for _, workflow := range m.workflows {
-		// Apply filters
-		matches := true
-		for key, value := range filters {
-			switch key {
-			case "workflow_id":
-				if workflow.WorkflowID != value {
-					matches = false
-				}
-			case "status":
-				if workflow.Status != value {
-					matches = false
-				}
-			case "trace_id":
-				if workflow.TraceID != value {
-					matches = false
-				}
-			case "correlation_id":
-				if workflow.CorrelationID != value {
-					matches = false
-				}
-			case "business_id":
-				if workflow.BusinessID != value {
-					matches = false
-				}
-			}
-			if !matches {
-				break
-			}
-		}
-
-		if matches {
-			results = append(results, m.deepCopyWorkflow(workflow))
-		}
-	}
+	m.scanWorkflows(filters, func(workflow *WorkflowInstance) {
+		results = append(results, m.deepCopyWorkflow(workflow))
+	})
 
 	total := int64(len(results))
+	if skipTotal(ctx) {
+		total = -1
+	}
 
 	// Apply pagination
 	if offset >= len(results) {
@@ -188,14 +235,181 @@ func (m *InMemoryStateManager) ListWorkflows(ctx context.Context, filters map[st
 	return results[offset:end], total, nil
 }
 
+// CountWorkflowsByStatus counts the workflows matching filters, grouped by status
+func (m *InMemoryStateManager) CountWorkflowsByStatus(ctx context.Context, filters map[string]interface{}) (map[WorkflowStatus]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[WorkflowStatus]int64)
+	m.scanWorkflows(filters, func(workflow *WorkflowInstance) {
+		counts[workflow.Status]++
+	})
+	return counts, nil
+}
+
+// workflowMatchesFilters applies the ListWorkflows filter keys to a workflow
+func workflowMatchesFilters(workflow *WorkflowInstance, filters map[string]interface{}) bool {
+	for key, value := range filters {
+		matches := true
+		switch key {
+		case "workflow_id":
+			matches = workflow.WorkflowID == value
+		case "status":
+			matches = workflow.Status == value
+		case "trace_id":
+			matches = workflow.TraceID == value
+		case "correlation_id":
+			matches = workflow.CorrelationID == value
+		case "business_id":
+			matches = workflow.BusinessID == value
+		case "parent_workflow_inst_id":
+			matches = workflow.ParentInstID == value
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// scanWorkflows calls fn for each workflow matching filters. When a filter has an
+// index, only the instances in the smallest matching index entry are checked.
+// The caller must hold the lock.
+func (m *InMemoryStateManager) scanWorkflows(filters map[string]interface{}, fn func(*WorkflowInstance)) {
+	var candidates map[string]struct{}
+	indexed := false
+	for key, value := range filters {
+		var idx idIndex
+		switch key {
+		case "workflow_id":
+			idx = m.workflowsByDef
+		case "status":
+			idx = m.workflowsByStatus
+		case "business_id":
+			idx = m.workflowsByBusiness
+		default:
+			continue
+		}
+		indexKey, ok := value.(string)
+		if status, isStatus := value.(WorkflowStatus); isStatus {
+			indexKey, ok = string(status), true
+		}
+		if !ok {
+			continue
+		}
+		if ids := idx[indexKey]; !indexed || len(ids) < len(candidates) {
+			candidates = ids
+			indexed = true
+		}
+	}
+
+	if !indexed {
+		for _, workflow := range m.workflows {
+			if workflowMatchesFilters(workflow, filters) {
+				fn(workflow)
+			}
+		}
+		return
+	}
+	for id := range candidates {
+		if workflow := m.workflows[id]; workflowMatchesFilters(workflow, filters) {
+			fn(workflow)
+		}
+	}
+}
+
+// indexWorkflow adds a stored workflow to the secondary indexes
+func (m *InMemoryStateManager) indexWorkflow(workflow *WorkflowInstance) {
+	m.workflowsByDef.add(workflow.WorkflowID, workflow.ID)
+	m.workflowsByStatus.add(string(workflow.Status), workflow.ID)
+	m.workflowsByBusiness.add(workflow.BusinessID, workflow.ID)
+}
+
+// unindexWorkflow removes a stored workflow from the secondary indexes
+func (m *InMemoryStateManager) unindexWorkflow(workflow *WorkflowInstance) {
+	m.workflowsByDef.remove(workflow.WorkflowID, workflow.ID)
+	m.workflowsByStatus.remove(string(workflow.Status), workflow.ID)
+	m.workflowsByBusiness.remove(workflow.BusinessID, workflow.ID)
+}
+
+// setWorkflowStatus changes a stored workflow's status and moves it in the status index
+func (m *InMemoryStateManager) setWorkflowStatus(workflow *WorkflowInstance, status WorkflowStatus) {
+	m.workflowsByStatus.remove(string(workflow.Status), workflow.ID)
+	workflow.Status = status
+	m.workflowsByStatus.add(string(status), workflow.ID)
+}
+
+// putStep stores a step copy and indexes it under its workflow instance
+func (m *InMemoryStateManager) putStep(step *StepInstance) {
+	if existing, ok := m.steps[step.ID]; ok {
+		m.stepsByWorkflow.remove(existing.WorkflowInstID, existing.ID)
+	}
+	m.steps[step.ID] = step
+	m.stepsByWorkflow.add(step.WorkflowInstID, step.ID)
+}
+
+// idIndex maps a field value to the IDs of the records holding that value
+type idIndex map[string]map[string]struct{}
+
+// add records id under key
+func (idx idIndex) add(key, id string) {
+	ids, ok := idx[key]
+	if !ok {
+		ids = make(map[string]struct{})
+		idx[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// remove drops id from key, forgetting the key once it is empty
+func (idx idIndex) remove(key, id string) {
+	ids, ok := idx[key]
+	if !ok {
+		return
+	}
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(idx, key)
+	}
+}
+
+// GetChildWorkflows retrieves the workflows started by the given parent instance
+func (m *InMemoryStateManager) GetChildWorkflows(ctx context.Context, parentInstID string) ([]*WorkflowInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var children []*WorkflowInstance
+	for _, workflow := range m.workflows {
+		if workflow.ParentInstID == parentInstID {
+			children = append(children, m.deepCopyWorkflow(workflow))
+		}
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].StartedAt.Before(children[j].StartedAt)
+	})
+
+	return children, nil
+}
+
 // SaveStep saves a step instance to memory
 func (m *InMemoryStateManager) SaveStep(ctx context.Context, step *StepInstance) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Deep copy to avoid race conditions
-	stepCopy := m.deepCopyStep(step)
-	m.steps[step.ID] = stepCopy
+	m.putStep(m.deepCopyStep(step))
+	return nil
+}
+
+// SaveSteps saves a batch of step instances to memory
+func (m *InMemoryStateManager) SaveSteps(ctx context.Context, steps []*StepInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, step := range steps {
+		m.putStep(m.deepCopyStep(step))
+	}
 	return nil
 }
 
@@ -206,7 +420,7 @@ func (m *InMemoryStateManager) GetStep(ctx context.Context, stepInstID string) (
 
 	step, ok := m.steps[stepInstID]
 	if !ok {
-		return nil, fmt.Errorf("step not found: %s", stepInstID)
+		return nil, fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
 	}
 
 	// Deep copy to avoid race conditions
@@ -218,14 +432,22 @@ func (m *InMemoryStateManager) GetWorkflowSteps(ctx context.Context, workflowIns
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return m.workflowSteps(workflowInstID), nil
+}
+
+// workflowSteps returns copies of a workflow's steps ordered by execution order.
+// The caller must hold the lock.
+func (m *InMemoryStateManager) workflowSteps(workflowInstID string) []*StepInstance {
 	var steps []*StepInstance
-	for _, step := range m.steps {
-		if step.WorkflowInstID == workflowInstID {
-			steps = append(steps, m.deepCopyStep(step))
-		}
+	for stepID := range m.stepsByWorkflow[workflowInstID] {
+		steps = append(steps, m.deepCopyStep(m.steps[stepID]))
 	}
 
-	return steps, nil
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].ExecutionOrder < steps[j].ExecutionOrder
+	})
+
+	return steps
 }
 
 // UpdateStepStatus updates the status of a step
@@ -235,7 +457,7 @@ func (m *InMemoryStateManager) UpdateStepStatus(ctx context.Context, stepInstID
 
 	step, ok := m.steps[stepInstID]
 	if !ok {
-		return fmt.Errorf("step not found: %s", stepInstID)
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
 	}
 
 	step.Status = status
@@ -244,7 +466,7 @@ func (m *InMemoryStateManager) UpdateStepStatus(ctx context.Context, stepInstID
 		step.StartedAt = &now
 	}
 
-	if status == StepStatusCompleted || status == StepStatusFailed || status == StepStatusSkipped {
+	if status == StepStatusCompleted || status == StepStatusFailed || status == StepStatusSkipped || status == StepStatusCancelled {
 		now := time.Now()
 		step.CompletedAt = &now
 	}
@@ -259,7 +481,7 @@ func (m *InMemoryStateManager) UpdateStepOutput(ctx context.Context, stepInstID
 
 	step, ok := m.steps[stepInstID]
 	if !ok {
-		return fmt.Errorf("step not found: %s", stepInstID)
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
 	}
 
 	step.Output = make(map[string]interface{})
@@ -277,7 +499,7 @@ func (m *InMemoryStateManager) UpdateStepError(ctx context.Context, stepInstID s
 
 	step, ok := m.steps[stepInstID]
 	if !ok {
-		return fmt.Errorf("step not found: %s", stepInstID)
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
 	}
 
 	errorMsg := err.Error()
@@ -289,11 +511,153 @@ func (m *InMemoryStateManager) UpdateStepError(ctx context.Context, stepInstID s
 	return nil
 }
 
+// UpdateStepFailureKind records why a failed step failed
+func (m *InMemoryStateManager) UpdateStepFailureKind(ctx context.Context, stepInstID string, kind FailureKind) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.FailureKind = kind
+	return nil
+}
+
+// UpdateStepSkipReason marks a step as skipped and records why
+func (m *InMemoryStateManager) UpdateStepSkipReason(ctx context.Context, stepInstID string, reason SkipReason) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.SkipReason = reason
+	step.Status = StepStatusSkipped
+	now := time.Now()
+	step.CompletedAt = &now
+
+	return nil
+}
+
+// UpdateStepWait records when a step became ready and how long it waited to start
+func (m *InMemoryStateManager) UpdateStepWait(ctx context.Context, stepInstID string, readyAt time.Time, waitMs int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.ReadyAt = &readyAt
+	step.WaitMs = waitMs
+	return nil
+}
+
+// UpdateStepRetry records how many retries a step has used and when the last one started
+func (m *InMemoryStateManager) UpdateStepRetry(ctx context.Context, stepInstID string, retryCount int, lastRetryAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.RetryCount = retryCount
+	step.LastRetryAt = nil
+	if lastRetryAt != nil {
+		at := *lastRetryAt
+		step.LastRetryAt = &at
+	}
+	return nil
+}
+
+// ResetStep returns a step to pending and clears the results of its previous run
+func (m *InMemoryStateManager) ResetStep(ctx context.Context, stepInstID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	resetStep(step)
+	return nil
+}
+
+// UpdateStepWakeAt updates the wake time of a timer step
+func (m *InMemoryStateManager) UpdateStepWakeAt(ctx context.Context, stepInstID string, wakeAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.WakeAt = &wakeAt
+	return nil
+}
+
+// UpdateStepAttachments replaces the attachments recorded on a step
+func (m *InMemoryStateManager) UpdateStepAttachments(ctx context.Context, stepInstID string, attachments []Attachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.Attachments = append([]Attachment(nil), attachments...)
+	return nil
+}
+
+// UpdateStepProvenance records the source of each key in a step's input
+func (m *InMemoryStateManager) UpdateStepProvenance(ctx context.Context, stepInstID string, provenance map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	step, ok := m.steps[stepInstID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, stepInstID)
+	}
+
+	step.Provenance = copyProvenance(provenance)
+	return nil
+}
+
+// GetDueWaitingSteps retrieves waiting steps whose wake time is at or before the given time
+func (m *InMemoryStateManager) GetDueWaitingSteps(ctx context.Context, before time.Time) ([]*StepInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var steps []*StepInstance
+	for _, step := range m.steps {
+		if step.Status == StepStatusWaiting && step.WakeAt != nil && !step.WakeAt.After(before) {
+			steps = append(steps, m.deepCopyStep(step))
+		}
+	}
+
+	return steps, nil
+}
+
 // SaveEvent saves a workflow event to memory
 func (m *InMemoryStateManager) SaveEvent(ctx context.Context, event *WorkflowEvent) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// The first save of an ID wins, like ON CONFLICT DO NOTHING in the database
+	if _, ok := m.events[event.ID]; ok {
+		return nil
+	}
+
 	// Deep copy to avoid race conditions
 	eventCopy := m.deepCopyEvent(event)
 	m.events[event.ID] = eventCopy
@@ -315,6 +679,21 @@ func (m *InMemoryStateManager) GetWorkflowEvents(ctx context.Context, workflowIn
 	return events, nil
 }
 
+// DeleteEventsOlderThan deletes events recorded before cutoff and returns how many were deleted
+func (m *InMemoryStateManager) DeleteEventsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for id, event := range m.events {
+		if event.Timestamp.Before(cutoff) {
+			delete(m.events, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 // WithTransaction executes a function within a transaction (no-op for in-memory)
 func (m *InMemoryStateManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
 	// For in-memory, we just execute the function
@@ -322,102 +701,19 @@ func (m *InMemoryStateManager) WithTransaction(ctx context.Context, fn func(ctx
 	return fn(ctx)
 }
 
+// Ping reports whether the state manager is reachable (always nil for in-memory)
+func (m *InMemoryStateManager) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Helper methods for deep copying
 
 func (m *InMemoryStateManager) deepCopyWorkflow(w *WorkflowInstance) *WorkflowInstance {
-	copy := &WorkflowInstance{
-		ID:            w.ID,
-		WorkflowID:    w.WorkflowID,
-		Status:        w.Status,
-		CurrentStepID: w.CurrentStepID,
-		StartedAt:     w.StartedAt,
-		RetryCount:    w.RetryCount,
-		TraceID:       w.TraceID,
-		CorrelationID: w.CorrelationID,
-		BusinessID:    w.BusinessID,
-	}
-
-	// Copy pointers
-	if w.CompletedAt != nil {
-		completedAt := *w.CompletedAt
-		copy.CompletedAt = &completedAt
-	}
-	if w.Error != nil {
-		error := *w.Error
-		copy.Error = &error
-	}
-	if w.LastRetryAt != nil {
-		lastRetryAt := *w.LastRetryAt
-		copy.LastRetryAt = &lastRetryAt
-	}
-
-	// Copy maps
-	copy.Input = make(map[string]interface{})
-	for k, v := range w.Input {
-		copy.Input[k] = v
-	}
-	copy.Output = make(map[string]interface{})
-	for k, v := range w.Output {
-		copy.Output[k] = v
-	}
-	copy.Context = make(map[string]interface{})
-	for k, v := range w.Context {
-		copy.Context[k] = v
-	}
-	copy.Metadata = make(map[string]interface{})
-	for k, v := range w.Metadata {
-		copy.Metadata[k] = v
-	}
-
-	// Copy steps
-	copy.Steps = make([]*StepInstance, len(w.Steps))
-	for i, step := range w.Steps {
-		copy.Steps[i] = m.deepCopyStep(step)
-	}
-
-	return copy
+	return w.Clone()
 }
 
 func (m *InMemoryStateManager) deepCopyStep(s *StepInstance) *StepInstance {
-	copy := &StepInstance{
-		ID:             s.ID,
-		StepID:         s.StepID,
-		WorkflowInstID: s.WorkflowInstID,
-		Status:         s.Status,
-		RetryCount:     s.RetryCount,
-		DurationMs:     s.DurationMs,
-		ExecutionOrder: s.ExecutionOrder,
-	}
-
-	// Copy pointers
-	if s.StartedAt != nil {
-		startedAt := *s.StartedAt
-		copy.StartedAt = &startedAt
-	}
-	if s.CompletedAt != nil {
-		completedAt := *s.CompletedAt
-		copy.CompletedAt = &completedAt
-	}
-	if s.Error != nil {
-		error := *s.Error
-		copy.Error = &error
-	}
-	if s.LastRetryAt != nil {
-		lastRetryAt := *s.LastRetryAt
-		copy.LastRetryAt = &lastRetryAt
-	}
-
-	// Copy maps
-	copy.Input = make(map[string]interface{})
-	for k, v := range s.Input {
-		copy.Input[k] = v
-	}
-	copy.Output = make(map[string]interface{})
-	for k, v := range s.Output {
-		copy.Output[k] = v
-	}
-
-	return copy
+	return s.Clone()
 }
 
 func (m *InMemoryStateManager) deepCopyEvent(e *WorkflowEvent) *WorkflowEvent {
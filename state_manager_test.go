@@ -2,6 +2,8 @@ package orchwf
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -82,6 +84,55 @@ func TestInMemoryStateManager_UpdateWorkflowOutput(t *testing.T) {
 	}
 }
 
+func TestInMemoryStateManager_UpdateWorkflowMetadata(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	workflow := &WorkflowInstance{
+		ID:         "test-workflow",
+		WorkflowID: "test",
+		Status:     WorkflowStatusRunning,
+		StartedAt:  time.Now(),
+		Metadata:   map[string]interface{}{"tenant": "acme"},
+	}
+
+	sm.SaveWorkflow(ctx, workflow)
+
+	err := sm.UpdateWorkflowMetadata(ctx, "test-workflow", map[string]interface{}{"order_ref": "ORD-1"})
+	if err != nil {
+		t.Errorf("UpdateWorkflowMetadata() error = %v", err)
+	}
+
+	saved, _ := sm.GetWorkflow(ctx, "test-workflow")
+	want := map[string]interface{}{"tenant": "acme", "order_ref": "ORD-1"}
+	if !mapsEqual(saved.Metadata, want) {
+		t.Errorf("UpdateWorkflowMetadata() = %v, want %v", saved.Metadata, want)
+	}
+
+	if err := sm.UpdateWorkflowMetadata(ctx, "missing", nil); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("UpdateWorkflowMetadata() on missing workflow error = %v, want ErrWorkflowNotFound", err)
+	}
+}
+
+func TestInMemoryStateManager_UpdateWorkflowCurrentStep(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	sm.SaveWorkflow(ctx, &WorkflowInstance{ID: "test-workflow", WorkflowID: "test", Status: WorkflowStatusRunning, StartedAt: time.Now()})
+
+	if err := sm.UpdateWorkflowCurrentStep(ctx, "test-workflow", "charge"); err != nil {
+		t.Fatalf("UpdateWorkflowCurrentStep() error = %v", err)
+	}
+	saved, _ := sm.GetWorkflow(ctx, "test-workflow")
+	if saved.CurrentStepID != "charge" {
+		t.Errorf("UpdateWorkflowCurrentStep() stored %q, want charge", saved.CurrentStepID)
+	}
+
+	if err := sm.UpdateWorkflowCurrentStep(ctx, "missing", "charge"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("UpdateWorkflowCurrentStep() on missing workflow error = %v, want ErrWorkflowNotFound", err)
+	}
+}
+
 func TestInMemoryStateManager_UpdateWorkflowError(t *testing.T) {
 	sm := NewInMemoryStateManager()
 	ctx := context.Background()
@@ -144,6 +195,120 @@ func TestInMemoryStateManager_ListWorkflows(t *testing.T) {
 	if len(filtered) != 2 {
 		t.Errorf("ListWorkflows() filtered count = %v, want %v", len(filtered), 2)
 	}
+
+	// Skipping the total still returns the page
+	page, total, err := sm.ListWorkflows(WithoutTotal(ctx), map[string]interface{}{}, 2, 0)
+	if err != nil {
+		t.Errorf("ListWorkflows() without total error = %v", err)
+	}
+	if len(page) != 2 || total != -1 {
+		t.Errorf("ListWorkflows() without total = %d workflows, total %d, want 2 and -1", len(page), total)
+	}
+}
+
+func TestInMemoryStateManager_CountWorkflowsByStatus(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	workflows := []*WorkflowInstance{
+		{ID: "wf1", WorkflowID: "test", Status: WorkflowStatusCompleted, StartedAt: time.Now()},
+		{ID: "wf2", WorkflowID: "test", Status: WorkflowStatusCompleted, StartedAt: time.Now()},
+		{ID: "wf3", WorkflowID: "test", Status: WorkflowStatusFailed, StartedAt: time.Now()},
+		{ID: "wf4", WorkflowID: "other", Status: WorkflowStatusCompleted, StartedAt: time.Now()},
+	}
+	for _, wf := range workflows {
+		sm.SaveWorkflow(ctx, wf)
+	}
+
+	counts, err := sm.CountWorkflowsByStatus(ctx, map[string]interface{}{"workflow_id": "test"})
+	if err != nil {
+		t.Fatalf("CountWorkflowsByStatus() error = %v", err)
+	}
+	if counts[WorkflowStatusCompleted] != 2 {
+		t.Errorf("completed count = %v, want %v", counts[WorkflowStatusCompleted], 2)
+	}
+	if counts[WorkflowStatusFailed] != 1 {
+		t.Errorf("failed count = %v, want %v", counts[WorkflowStatusFailed], 1)
+	}
+	if len(counts) != 2 {
+		t.Errorf("CountWorkflowsByStatus() returned %d statuses, want %d", len(counts), 2)
+	}
+
+	// Totals agree with ListWorkflows for the same filters
+	_, total, _ := sm.ListWorkflows(ctx, map[string]interface{}{"workflow_id": "test"}, 10, 0)
+	if sum := counts[WorkflowStatusCompleted] + counts[WorkflowStatusFailed]; sum != total {
+		t.Errorf("CountWorkflowsByStatus() sum = %v, ListWorkflows() total = %v", sum, total)
+	}
+}
+
+func TestInMemoryStateManager_IndexesFollowUpdates(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	sm.SaveWorkflow(ctx, &WorkflowInstance{ID: "wf1", WorkflowID: "test", Status: WorkflowStatusRunning, StartedAt: time.Now()})
+	sm.SaveWorkflow(ctx, &WorkflowInstance{ID: "wf2", WorkflowID: "test", Status: WorkflowStatusRunning, StartedAt: time.Now()})
+	sm.UpdateWorkflowStatus(ctx, "wf1", WorkflowStatusCompleted)
+	sm.UpdateWorkflowError(ctx, "wf2", errors.New("boom"))
+	// Re-saving under another definition moves the instance between index entries
+	sm.SaveWorkflow(ctx, &WorkflowInstance{ID: "wf2", WorkflowID: "other", Status: WorkflowStatusFailed, StartedAt: time.Now()})
+
+	tests := []struct {
+		filters map[string]interface{}
+		want    int64
+	}{
+		{map[string]interface{}{"status": WorkflowStatusRunning}, 0},
+		{map[string]interface{}{"status": WorkflowStatusCompleted}, 1},
+		{map[string]interface{}{"status": WorkflowStatusFailed, "workflow_id": "other"}, 1},
+		{map[string]interface{}{"workflow_id": "test"}, 1},
+		{map[string]interface{}{"status": "completed"}, 0}, // Untyped strings never matched a status
+	}
+	for _, tt := range tests {
+		if _, total, _ := sm.ListWorkflows(ctx, tt.filters, 10, 0); total != tt.want {
+			t.Errorf("ListWorkflows(%v) total = %v, want %v", tt.filters, total, tt.want)
+		}
+	}
+
+	sm.SaveStep(ctx, &StepInstance{ID: "s1", StepID: "a", WorkflowInstID: "wf1"})
+	sm.SaveStep(ctx, &StepInstance{ID: "s1", StepID: "a", WorkflowInstID: "wf2"})
+	if steps, _ := sm.GetWorkflowSteps(ctx, "wf1"); len(steps) != 0 {
+		t.Errorf("GetWorkflowSteps(wf1) count = %v, want 0 after the step moved", len(steps))
+	}
+	if steps, _ := sm.GetWorkflowSteps(ctx, "wf2"); len(steps) != 1 {
+		t.Errorf("GetWorkflowSteps(wf2) count = %v, want 1", len(steps))
+	}
+}
+
+func TestInMemoryStateManager_GetChildWorkflows(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	now := time.Now()
+	workflows := []*WorkflowInstance{
+		{ID: "parent", WorkflowID: "wf", StartedAt: now},
+		{ID: "child2", WorkflowID: "wf", ParentInstID: "parent", StartedAt: now.Add(2 * time.Second)},
+		{ID: "child1", WorkflowID: "wf", ParentInstID: "parent", StartedAt: now.Add(time.Second)},
+		{ID: "other", WorkflowID: "wf", ParentInstID: "someone-else", StartedAt: now},
+	}
+	for _, w := range workflows {
+		sm.SaveWorkflow(ctx, w)
+	}
+
+	children, err := sm.GetChildWorkflows(ctx, "parent")
+	if err != nil {
+		t.Errorf("GetChildWorkflows() error = %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("GetChildWorkflows() count = %v, want %v", len(children), 2)
+	}
+	if children[0].ID != "child1" || children[1].ID != "child2" {
+		t.Errorf("GetChildWorkflows() order = [%v %v], want [child1 child2]", children[0].ID, children[1].ID)
+	}
+
+	// Parent filter is also available through ListWorkflows
+	_, total, _ := sm.ListWorkflows(ctx, map[string]interface{}{"parent_workflow_inst_id": "parent"}, 10, 0)
+	if total != 2 {
+		t.Errorf("ListWorkflows() by parent total = %v, want %v", total, 2)
+	}
 }
 
 func TestInMemoryStateManager_SaveStep(t *testing.T) {
@@ -174,6 +339,36 @@ func TestInMemoryStateManager_SaveStep(t *testing.T) {
 	}
 }
 
+func TestInMemoryStateManager_SaveSteps(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	steps := []*StepInstance{
+		{ID: "step1", StepID: "s1", WorkflowInstID: "test-workflow", ExecutionOrder: 0},
+		{ID: "step2", StepID: "s2", WorkflowInstID: "test-workflow", ExecutionOrder: 1},
+	}
+
+	if err := sm.SaveSteps(ctx, steps); err != nil {
+		t.Errorf("SaveSteps() error = %v", err)
+	}
+
+	saved, _ := sm.GetWorkflowSteps(ctx, "test-workflow")
+	if len(saved) != 2 {
+		t.Fatalf("GetWorkflowSteps() count = %v, want %v", len(saved), 2)
+	}
+
+	// Saved steps are copies
+	steps[0].Status = StepStatusCompleted
+	step, _ := sm.GetStep(ctx, "step1")
+	if step.Status == StepStatusCompleted {
+		t.Errorf("SaveSteps() should store copies of the steps")
+	}
+
+	if err := sm.SaveSteps(ctx, nil); err != nil {
+		t.Errorf("SaveSteps() with no steps error = %v", err)
+	}
+}
+
 func TestInMemoryStateManager_GetWorkflowSteps(t *testing.T) {
 	sm := NewInMemoryStateManager()
 	ctx := context.Background()
@@ -198,6 +393,103 @@ func TestInMemoryStateManager_GetWorkflowSteps(t *testing.T) {
 	}
 }
 
+func TestInMemoryStateManager_GetDueWaitingSteps(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	now := time.Now()
+	steps := []*StepInstance{
+		{ID: "due", StepID: "s1", WorkflowInstID: "wf1", Status: StepStatusWaiting},
+		{ID: "later", StepID: "s2", WorkflowInstID: "wf2", Status: StepStatusWaiting},
+		{ID: "pending", StepID: "s3", WorkflowInstID: "wf3", Status: StepStatusPending},
+	}
+	for _, step := range steps {
+		sm.SaveStep(ctx, step)
+	}
+
+	sm.UpdateStepWakeAt(ctx, "due", now.Add(-time.Minute))
+	sm.UpdateStepWakeAt(ctx, "later", now.Add(time.Hour))
+	sm.UpdateStepWakeAt(ctx, "pending", now.Add(-time.Minute))
+
+	due, err := sm.GetDueWaitingSteps(ctx, now)
+	if err != nil {
+		t.Errorf("GetDueWaitingSteps() error = %v", err)
+	}
+	if len(due) != 1 || due[0].ID != "due" {
+		t.Errorf("GetDueWaitingSteps() = %v, want only step 'due'", due)
+	}
+	if due[0].WakeAt == nil {
+		t.Errorf("GetDueWaitingSteps() step WakeAt should be set")
+	}
+
+	if err := sm.UpdateStepWakeAt(ctx, "missing", now); err == nil {
+		t.Errorf("UpdateStepWakeAt() with missing step should return error")
+	}
+}
+
+func TestInMemoryStateManager_UpdateStepRetry(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	sm.SaveStep(ctx, &StepInstance{ID: "step", StepID: "s1", WorkflowInstID: "wf1", Status: StepStatusRetrying})
+
+	now := time.Now()
+	if err := sm.UpdateStepRetry(ctx, "step", 2, &now); err != nil {
+		t.Fatalf("UpdateStepRetry() error = %v", err)
+	}
+	step, _ := sm.GetStep(ctx, "step")
+	if step.RetryCount != 2 || step.LastRetryAt == nil || !step.LastRetryAt.Equal(now) {
+		t.Errorf("UpdateStepRetry() stored RetryCount = %d, LastRetryAt = %v", step.RetryCount, step.LastRetryAt)
+	}
+
+	if err := sm.UpdateStepRetry(ctx, "step", 0, nil); err != nil {
+		t.Fatalf("UpdateStepRetry() error = %v", err)
+	}
+	step, _ = sm.GetStep(ctx, "step")
+	if step.RetryCount != 0 || step.LastRetryAt != nil {
+		t.Errorf("UpdateStepRetry() reset stored RetryCount = %d, LastRetryAt = %v", step.RetryCount, step.LastRetryAt)
+	}
+
+	if err := sm.UpdateStepRetry(ctx, "missing", 1, &now); err == nil {
+		t.Errorf("UpdateStepRetry() with missing step should return error")
+	}
+}
+
+func TestInMemoryStateManager_ResetStep(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	now := time.Now()
+	sm.SaveStep(ctx, &StepInstance{
+		ID:             "step",
+		StepID:         "s1",
+		WorkflowInstID: "wf1",
+		Status:         StepStatusFailed,
+		Output:         map[string]interface{}{"partial": true},
+		Error:          stringPtr("boom"),
+		StartedAt:      &now,
+		CompletedAt:    &now,
+		RetryCount:     3,
+		LastRetryAt:    &now,
+		DurationMs:     120,
+	})
+
+	if err := sm.ResetStep(ctx, "step"); err != nil {
+		t.Fatalf("ResetStep() error = %v", err)
+	}
+	step, _ := sm.GetStep(ctx, "step")
+	if step.Status != StepStatusPending || step.Error != nil || len(step.Output) != 0 {
+		t.Errorf("ResetStep() stored status = %s, error = %v, output = %v", step.Status, step.Error, step.Output)
+	}
+	if step.StartedAt != nil || step.CompletedAt != nil || step.RetryCount != 0 || step.LastRetryAt != nil || step.DurationMs != 0 {
+		t.Errorf("ResetStep() kept timing from the previous run: %+v", step)
+	}
+
+	if err := sm.ResetStep(ctx, "missing"); err == nil {
+		t.Errorf("ResetStep() with missing step should return error")
+	}
+}
+
 func TestInMemoryStateManager_UpdateStepStatus(t *testing.T) {
 	sm := NewInMemoryStateManager()
 	ctx := context.Background()
@@ -251,6 +543,44 @@ func TestInMemoryStateManager_SaveEvent(t *testing.T) {
 	if events[0].ID != "test-event" {
 		t.Errorf("GetWorkflowEvents() = %v, want %v", events[0].ID, "test-event")
 	}
+
+	// Saving the same event again, as a retried flush would, keeps the first copy
+	retry := *event
+	retry.EventData = map[string]interface{}{"key": "changed"}
+	if err := sm.SaveEvent(ctx, &retry); err != nil {
+		t.Errorf("SaveEvent() of a duplicate ID error = %v, want nil", err)
+	}
+	events, _ = sm.GetWorkflowEvents(ctx, "test-workflow")
+	if len(events) != 1 || events[0].EventData["key"] != "value" {
+		t.Errorf("GetWorkflowEvents() after duplicate save = %v, want the first event only", events)
+	}
+}
+
+func TestInMemoryStateManager_DeleteEventsOlderThan(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	now := time.Now()
+	sm.SaveEvent(ctx, &WorkflowEvent{ID: "old", WorkflowInstID: "wf1", EventType: "step.started", Timestamp: now.Add(-48 * time.Hour)})
+	sm.SaveEvent(ctx, &WorkflowEvent{ID: "recent", WorkflowInstID: "wf1", EventType: "step.completed", Timestamp: now})
+	sm.SaveWorkflow(ctx, &WorkflowInstance{ID: "wf1", WorkflowID: "def", Status: WorkflowStatusCompleted, StartedAt: now.Add(-48 * time.Hour)})
+
+	deleted, err := sm.DeleteEventsOlderThan(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteEventsOlderThan() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteEventsOlderThan() deleted = %d, want 1", deleted)
+	}
+
+	events, _ := sm.GetWorkflowEvents(ctx, "wf1")
+	if len(events) != 1 || events[0].ID != "recent" {
+		t.Errorf("GetWorkflowEvents() = %v, want only event 'recent'", events)
+	}
+	// Instances are kept on their own retention
+	if _, err := sm.GetWorkflow(ctx, "wf1"); err != nil {
+		t.Errorf("GetWorkflow() error = %v, want instance kept", err)
+	}
 }
 
 func TestInMemoryStateManager_WithTransaction(t *testing.T) {
@@ -307,3 +637,75 @@ func TestInMemoryStateManager_DeepCopy(t *testing.T) {
 		t.Errorf("Deep copy failed - context was modified")
 	}
 }
+
+func TestInMemoryStateManager_NotFoundErrors(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+
+	if _, err := sm.GetWorkflow(ctx, "missing"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("GetWorkflow() error = %v, want ErrWorkflowNotFound", err)
+	}
+	if err := sm.UpdateWorkflowStatus(ctx, "missing", WorkflowStatusRunning); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("UpdateWorkflowStatus() error = %v, want ErrWorkflowNotFound", err)
+	}
+	if _, err := sm.GetStep(ctx, "missing"); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("GetStep() error = %v, want ErrStepNotFound", err)
+	}
+	if err := sm.UpdateStepStatus(ctx, "missing", StepStatusRunning); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("UpdateStepStatus() error = %v, want ErrStepNotFound", err)
+	}
+}
+
+// newBenchmarkStateManager stores n workflow instances spread over 100 workflow
+// definitions, each with 5 steps
+func newBenchmarkStateManager(b *testing.B, n int) *InMemoryStateManager {
+	b.Helper()
+	sm := NewInMemoryStateManager()
+	ctx := context.Background()
+	statuses := []WorkflowStatus{WorkflowStatusCompleted, WorkflowStatusFailed, WorkflowStatusRunning}
+	for i := 0; i < n; i++ {
+		instID := fmt.Sprintf("inst-%d", i)
+		sm.SaveWorkflow(ctx, &WorkflowInstance{
+			ID:         instID,
+			WorkflowID: fmt.Sprintf("wf-%d", i%100),
+			Status:     statuses[i%len(statuses)],
+			BusinessID: fmt.Sprintf("order-%d", i),
+			StartedAt:  time.Now(),
+		})
+		for j := 0; j < 5; j++ {
+			sm.SaveStep(ctx, &StepInstance{
+				ID:             fmt.Sprintf("%s-step-%d", instID, j),
+				StepID:         fmt.Sprintf("step-%d", j),
+				WorkflowInstID: instID,
+				Status:         StepStatusCompleted,
+				ExecutionOrder: j,
+			})
+		}
+	}
+	return sm
+}
+
+func BenchmarkInMemoryStateManager_ListWorkflows(b *testing.B) {
+	sm := newBenchmarkStateManager(b, 10000)
+	ctx := context.Background()
+	filters := map[string]interface{}{"workflow_id": "wf-7"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, total, _ := sm.ListWorkflows(ctx, filters, 10, 0); total != 100 {
+			b.Fatalf("ListWorkflows() total = %d, want 100", total)
+		}
+	}
+}
+
+func BenchmarkInMemoryStateManager_GetWorkflowSteps(b *testing.B) {
+	sm := newBenchmarkStateManager(b, 10000)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if steps, _ := sm.GetWorkflowSteps(ctx, "inst-4242"); len(steps) != 5 {
+			b.Fatalf("GetWorkflowSteps() count = %d, want 5", len(steps))
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package orchwf
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// checkStrictOrdering verifies that no two steps of an instance ran at the same time.
+// Steps that never started or have not finished are ignored.
+func checkStrictOrdering(instance *WorkflowInstance) error {
+	var ran []*StepInstance
+	for _, stepInst := range instance.Steps {
+		if stepInst.StartedAt != nil && stepInst.CompletedAt != nil {
+			ran = append(ran, stepInst)
+		}
+	}
+	sort.SliceStable(ran, func(i, j int) bool {
+		return ran[i].StartedAt.Before(*ran[j].StartedAt)
+	})
+
+	for i := 1; i < len(ran); i++ {
+		prev, next := ran[i-1], ran[i]
+		if next.StartedAt.Before(*prev.CompletedAt) {
+			return fmt.Errorf("%w: %s started at %s, before %s completed at %s", ErrStrictOrderViolated,
+				next.StepID, next.StartedAt.Format(time.RFC3339Nano), prev.StepID, prev.CompletedAt.Format(time.RFC3339Nano))
+		}
+	}
+	return nil
+}
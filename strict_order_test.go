@@ -0,0 +1,69 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_StrictOrdering(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	orchestrator := NewOrchestrator(sm)
+	var running, maxRunning atomic.Int32
+	executor := func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			peak := maxRunning.Load()
+			if n <= peak || maxRunning.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	}
+
+	// Async steps in one wave, under the overlap strategy, would otherwise run together
+	workflow, _ := NewWorkflowBuilder("audited", "Audited").
+		WithStrictOrdering(true).
+		WithOverlapAsync(true).
+		AddStepFunc("collect", "Collect", executor).
+		AddStepFunc("screen", "Screen", executor, WithStepAsync(true)).
+		AddStepFunc("score", "Score", executor, WithStepAsync(true)).
+		AddStepFunc("report", "Report", executor, WithStepDeps("collect", "screen", "score")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "audited", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+	if peak := maxRunning.Load(); peak != 1 {
+		t.Errorf("steps running at once = %d, want 1", peak)
+	}
+	if err := checkStrictOrdering(result.WorkflowInst); err != nil {
+		t.Errorf("checkStrictOrdering() error = %v", err)
+	}
+}
+
+func TestCheckStrictOrdering(t *testing.T) {
+	at := func(ms int) *time.Time {
+		ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(ms) * time.Millisecond)
+		return &ts
+	}
+	instance := &WorkflowInstance{Steps: []*StepInstance{
+		{StepID: "b", StartedAt: at(10), CompletedAt: at(20)},
+		{StepID: "a", StartedAt: at(0), CompletedAt: at(10)},
+		{StepID: "skipped"},
+	}}
+	if err := checkStrictOrdering(instance); err != nil {
+		t.Errorf("checkStrictOrdering() error = %v, want back-to-back steps accepted", err)
+	}
+
+	instance.Steps = append(instance.Steps, &StepInstance{StepID: "c", StartedAt: at(15), CompletedAt: at(25)})
+	if err := checkStrictOrdering(instance); !errors.Is(err, ErrStrictOrderViolated) {
+		t.Errorf("checkStrictOrdering() error = %v, want ErrStrictOrderViolated", err)
+	}
+}
@@ -0,0 +1,51 @@
+package orchwf
+
+import (
+	"sort"
+	"strings"
+)
+
+// tagLabel returns the step's tags as a metric label value: sorted, without duplicates
+// and joined by commas. It is empty for a step without tags.
+func tagLabel(stepDef *StepDefinition) string {
+	if len(stepDef.Tags) == 0 {
+		return ""
+	}
+	tags := append([]string(nil), stepDef.Tags...)
+	sort.Strings(tags)
+	unique := tags[:1]
+	for _, tag := range tags[1:] {
+		if tag != unique[len(unique)-1] {
+			unique = append(unique, tag)
+		}
+	}
+	return strings.Join(unique, ",")
+}
+
+// withTags adds the step's tags to an event payload when it has any
+func withTags(data map[string]interface{}, stepDef *StepDefinition) map[string]interface{} {
+	if len(stepDef.Tags) > 0 {
+		data["tags"] = append([]string(nil), stepDef.Tags...)
+	}
+	return data
+}
+
+// tagTimeline sets the tags of each step entry from the workflow definition, if it is registered
+func (o *Orchestrator) tagTimeline(timeline *Timeline, workflowID string) {
+	workflow, err := o.GetWorkflow(workflowID)
+	if err != nil {
+		return
+	}
+	tags := make(map[string][]string)
+	for _, stepDef := range workflow.Steps {
+		tags[stepDef.ID] = stepDef.Tags
+		for _, member := range stepDef.Alternatives {
+			tags[member.ID] = member.Tags
+		}
+	}
+	for i := range timeline.Entries {
+		if step := timeline.Entries[i].Step; step != nil {
+			timeline.Entries[i].Tags = tags[step.StepID]
+		}
+	}
+}
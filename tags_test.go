@@ -0,0 +1,91 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// labelRecordingMetrics records the labels of every observation by metric name
+type labelRecordingMetrics struct {
+	mu     sync.Mutex
+	labels map[string][]map[string]string
+}
+
+func (m *labelRecordingMetrics) IncCounter(name string, labels map[string]string) {
+	m.record(name, labels)
+}
+
+func (m *labelRecordingMetrics) ObserveDuration(name string, duration time.Duration, labels map[string]string) {
+	m.record(name, labels)
+}
+
+func (m *labelRecordingMetrics) record(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels[name] = append(m.labels[name], labels)
+}
+
+func TestOrchestrator_StepTags(t *testing.T) {
+	sm := NewInMemoryStateManager()
+	metrics := &labelRecordingMetrics{labels: make(map[string][]map[string]string)}
+	orchestrator := NewOrchestrator(sm, WithMetrics(metrics))
+	workflow, _ := NewWorkflowBuilder("tagged", "Tagged").
+		AddStepFunc("fetch", "Fetch Rates", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"rate": 1.1}, nil
+		}, WithStepTags("external-call", "fx"), WithStepTags("external-call")).
+		AddStepFunc("store", "Store", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("disk full")
+		}, WithStepDeps("fetch"), WithStepRequired(false), WithStepTags("db-write")).
+		AddStepFunc("notify", "Notify", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("fetch")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "tagged", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	// Metric labels hold the sorted, de-duplicated tags; untagged steps get an empty label
+	durationTags := make(map[string]string)
+	for _, labels := range metrics.labels["step.duration"] {
+		durationTags[labels["step_id"]] = labels["tags"]
+	}
+	if want := map[string]string{"fetch": "external-call,fx", "notify": ""}; !reflect.DeepEqual(durationTags, want) {
+		t.Errorf("step.duration tags = %v, want %v", durationTags, want)
+	}
+	if failed := metrics.labels["step.failed"]; len(failed) != 1 || failed[0]["tags"] != "db-write" {
+		t.Errorf("step.failed labels = %v, want the db-write tag", failed)
+	}
+
+	// Event payloads carry the tags of tagged steps
+	events, _ := sm.GetWorkflowEvents(context.Background(), result.WorkflowInst.ID)
+	var taggedEvents int
+	for _, event := range events {
+		if tags, ok := event.EventData["tags"]; ok {
+			taggedEvents++
+			if event.EventType == EventStepFailed && !reflect.DeepEqual(tags, []string{"db-write"}) {
+				t.Errorf("%s tags = %v, want [db-write]", event.EventType, tags)
+			}
+		}
+	}
+	if taggedEvents != 4 {
+		t.Errorf("events with tags = %d, want started and completed for fetch, started and failed for store", taggedEvents)
+	}
+
+	// Timeline entries show the tags of each step
+	timeline, err := orchestrator.GetWorkflowTimeline(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("GetWorkflowTimeline() error = %v", err)
+	}
+	for _, entry := range timeline.Entries {
+		if entry.Step != nil && entry.Step.StepID == "fetch" && !reflect.DeepEqual(entry.Tags, []string{"external-call", "fx"}) {
+			t.Errorf("fetch timeline tags = %v, want the step's tags", entry.Tags)
+		}
+	}
+}
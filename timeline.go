@@ -0,0 +1,104 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimelineEntryType identifies the kind of a timeline entry
+type TimelineEntryType string
+
+const (
+	TimelineEntryStep  TimelineEntryType = "step"
+	TimelineEntryEvent TimelineEntryType = "event"
+)
+
+// TimelineEntry is a single step span or event in a workflow timeline.
+// Exactly one of Step or Event is set, depending on Type.
+type TimelineEntry struct {
+	Type     TimelineEntryType
+	Time     time.Time     // Step start time or event timestamp
+	EndTime  *time.Time    // Step completion time (nil for events and running steps)
+	Duration time.Duration // Step duration so far (zero for events)
+	Running  bool          // True if the step has started but not completed
+	Step     *StepInstance
+	Event    *WorkflowEvent
+	Tags     []string // Tags of the step's definition (nil for events)
+}
+
+// Timeline is the chronological view of a workflow instance's steps and events
+type Timeline struct {
+	WorkflowInstID string
+	Entries        []TimelineEntry
+}
+
+// GetWorkflowTimeline returns the started steps and events of a workflow instance merged into
+// a single slice sorted by time. Steps that never started are omitted.
+func (o *Orchestrator) GetWorkflowTimeline(ctx context.Context, workflowInstID string) (*Timeline, error) {
+	instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow: %w", err)
+	}
+
+	events, err := o.stateManager.GetWorkflowEvents(ctx, workflowInstID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+
+	timeline := buildTimeline(workflowInstID, instance.Steps, events, time.Now())
+	o.tagTimeline(timeline, instance.WorkflowID)
+	return timeline, nil
+}
+
+// buildTimeline merges steps and events into a time-sorted timeline
+func buildTimeline(workflowInstID string, steps []*StepInstance, events []*WorkflowEvent, now time.Time) *Timeline {
+	entries := make([]TimelineEntry, 0, len(steps)+len(events))
+
+	for _, step := range steps {
+		if step.StartedAt == nil {
+			continue
+		}
+
+		entry := TimelineEntry{
+			Type: TimelineEntryStep,
+			Time: *step.StartedAt,
+			Step: step,
+		}
+
+		switch {
+		case step.CompletedAt != nil:
+			entry.EndTime = step.CompletedAt
+			entry.Duration = step.CompletedAt.Sub(*step.StartedAt)
+		case step.IsCompleted():
+			entry.Duration = time.Duration(step.DurationMs) * time.Millisecond
+		default:
+			entry.Running = true
+			entry.Duration = now.Sub(*step.StartedAt)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	for _, event := range events {
+		entries = append(entries, TimelineEntry{
+			Type:  TimelineEntryEvent,
+			Time:  event.Timestamp,
+			Event: event,
+		})
+	}
+
+	// Steps sort before events recorded at the same instant
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Type == TimelineEntryStep && entries[j].Type == TimelineEntryEvent
+	})
+
+	return &Timeline{
+		WorkflowInstID: workflowInstID,
+		Entries:        entries,
+	}
+}
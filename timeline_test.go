@@ -0,0 +1,94 @@
+package orchwf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) *time.Time {
+		ts := base.Add(time.Duration(seconds) * time.Second)
+		return &ts
+	}
+
+	steps := []*StepInstance{
+		{ID: "si2", StepID: "running", Status: StepStatusRunning, StartedAt: at(5)},
+		{ID: "si1", StepID: "done", Status: StepStatusCompleted, StartedAt: at(1), CompletedAt: at(3)},
+		{ID: "si3", StepID: "pending", Status: StepStatusPending},
+	}
+	events := []*WorkflowEvent{
+		{ID: "e2", EventType: "step.completed", Timestamp: *at(3)},
+		{ID: "e1", EventType: "workflow.started", Timestamp: *at(0)},
+		{ID: "e3", EventType: "step.started", Timestamp: *at(1)},
+	}
+
+	timeline := buildTimeline("wf", steps, events, base.Add(10*time.Second))
+
+	if len(timeline.Entries) != 5 {
+		t.Fatalf("buildTimeline() entries = %d, want %d", len(timeline.Entries), 5)
+	}
+
+	want := []string{"e1", "si1", "e3", "e2", "si2"}
+	for i, entry := range timeline.Entries {
+		var id string
+		if entry.Type == TimelineEntryStep {
+			id = entry.Step.ID
+		} else {
+			id = entry.Event.ID
+		}
+		if id != want[i] {
+			t.Errorf("buildTimeline() entry %d = %s, want %s", i, id, want[i])
+		}
+	}
+
+	done := timeline.Entries[1]
+	if done.Running || done.EndTime == nil || done.Duration != 2*time.Second {
+		t.Errorf("completed step entry = %+v, want 2s duration with end time", done)
+	}
+
+	running := timeline.Entries[4]
+	if !running.Running || running.EndTime != nil || running.Duration != 5*time.Second {
+		t.Errorf("running step entry = %+v, want running with 5s elapsed", running)
+	}
+}
+
+func TestOrchestrator_GetWorkflowTimeline(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	workflow, _ := NewWorkflowBuilder("timeline-workflow", "Timeline Workflow").
+		AddStepFunc("step1", "Step 1", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"result": "success"}, nil
+		}).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	result, err := orchestrator.StartWorkflow(context.Background(), "timeline-workflow", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow() error = %v", err)
+	}
+
+	timeline, err := orchestrator.GetWorkflowTimeline(context.Background(), result.WorkflowInst.ID)
+	if err != nil {
+		t.Fatalf("GetWorkflowTimeline() error = %v", err)
+	}
+
+	var steps, events int
+	for i, entry := range timeline.Entries {
+		if i > 0 && entry.Time.Before(timeline.Entries[i-1].Time) {
+			t.Errorf("GetWorkflowTimeline() entries are not sorted at index %d", i)
+		}
+		if entry.Type == TimelineEntryStep {
+			steps++
+		} else {
+			events++
+		}
+	}
+	if steps != 1 {
+		t.Errorf("GetWorkflowTimeline() step entries = %d, want %d", steps, 1)
+	}
+	if events == 0 {
+		t.Errorf("GetWorkflowTimeline() should include workflow events")
+	}
+}
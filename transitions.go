@@ -0,0 +1,38 @@
+package orchwf
+
+import "fmt"
+
+// StepTransitions lists the statuses each step status may move to. Completed, skipped
+// and cancelled steps are terminal; a failed step can only be recorded as skipped (optional
+// step) or cancelled (sibling failure). Running and retrying may repeat so a step left
+// running by a crashed process can be executed again on resume.
+var StepTransitions = map[StepStatus][]StepStatus{
+	StepStatusPending:   {StepStatusRunning, StepStatusWaiting, StepStatusSkipped, StepStatusFailed, StepStatusCancelled},
+	StepStatusWaiting:   {StepStatusRunning, StepStatusSkipped, StepStatusFailed, StepStatusCancelled},
+	StepStatusRunning:   {StepStatusRunning, StepStatusCompleted, StepStatusFailed, StepStatusRetrying, StepStatusCancelled},
+	StepStatusRetrying:  {StepStatusRetrying, StepStatusRunning, StepStatusCompleted, StepStatusFailed, StepStatusCancelled},
+	StepStatusFailed:    {StepStatusSkipped, StepStatusCancelled},
+	StepStatusCompleted: {},
+	StepStatusSkipped:   {},
+	StepStatusCancelled: {},
+}
+
+// CanTransitionStep reports whether a step may move from one status to another
+func CanTransitionStep(from, to StepStatus) bool {
+	for _, allowed := range StepTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionStep moves a step instance to a new status, rejecting illegal transitions.
+// Callers persist the new status themselves.
+func (o *Orchestrator) transitionStep(stepInst *StepInstance, to StepStatus) error {
+	if !CanTransitionStep(stepInst.Status, to) {
+		return fmt.Errorf("%w: step %s from %s to %s", ErrInvalidTransition, stepInst.StepID, stepInst.Status, to)
+	}
+	stepInst.Status = to
+	return nil
+}
@@ -0,0 +1,63 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCanTransitionStep(t *testing.T) {
+	tests := []struct {
+		from, to StepStatus
+		want     bool
+	}{
+		{StepStatusPending, StepStatusRunning, true},
+		{StepStatusRunning, StepStatusCompleted, true},
+		{StepStatusRunning, StepStatusRetrying, true},
+		{StepStatusRetrying, StepStatusFailed, true},
+		{StepStatusFailed, StepStatusSkipped, true},
+		{StepStatusWaiting, StepStatusRunning, true},
+		{StepStatusCompleted, StepStatusRunning, false},
+		{StepStatusSkipped, StepStatusRunning, false},
+		{StepStatusCancelled, StepStatusCompleted, false},
+		{StepStatusFailed, StepStatusRunning, false},
+		{StepStatusPending, StepStatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionStep(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionStep(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStepTransitions_CoverAllStatuses(t *testing.T) {
+	statuses := []StepStatus{
+		StepStatusPending, StepStatusRunning, StepStatusCompleted, StepStatusFailed,
+		StepStatusSkipped, StepStatusRetrying, StepStatusWaiting, StepStatusCancelled,
+	}
+	for _, status := range statuses {
+		if _, ok := StepTransitions[status]; !ok {
+			t.Errorf("StepTransitions has no entry for %s", status)
+		}
+	}
+}
+
+func TestOrchestrator_TransitionStepRejectsTerminalStep(t *testing.T) {
+	logger := &recordingLogger{}
+	orchestrator := NewOrchestrator(NewInMemoryStateManager(), WithLogger(logger))
+
+	stepInst := &StepInstance{ID: "inst", StepID: "step", Status: StepStatusCompleted}
+	if err := orchestrator.transitionStep(stepInst, StepStatusRunning); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("transitionStep() error = %v, want ErrInvalidTransition", err)
+	}
+	if stepInst.Status != StepStatusCompleted {
+		t.Errorf("transitionStep() changed status to %s on an illegal transition", stepInst.Status)
+	}
+
+	// A completed step cannot be failed afterwards
+	orchestrator.failStep(context.Background(), &StepDefinition{ID: "step"}, stepInst, &WorkflowInstance{ID: "wf"}, errors.New("late failure"), FailureKindExhaustedRetries)
+	if stepInst.Status != StepStatusCompleted || len(logger.messages) != 1 {
+		t.Errorf("failStep() on completed step: status = %s, logs = %v", stepInst.Status, logger.messages)
+	}
+}
@@ -0,0 +1,65 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ExecutorTransport invokes a step's executor. It is the seam for running steps outside the
+// orchestrator's process, such as on remote workers; retries, timeouts, resources and
+// persistence stay with the orchestrator whichever transport runs the step.
+type ExecutorTransport interface {
+	Execute(ctx context.Context, stepDef *StepDefinition, input map[string]interface{}) (map[string]interface{}, error)
+}
+
+// LocalTransport calls step executors in the orchestrator's process. It is the default transport.
+type LocalTransport struct{}
+
+// Execute calls the step's executor directly
+func (LocalTransport) Execute(ctx context.Context, stepDef *StepDefinition, input map[string]interface{}) (map[string]interface{}, error) {
+	return stepDef.Executor(ctx, input)
+}
+
+// WithTransport sets the transport used to invoke step executors. The default is LocalTransport.
+func WithTransport(transport ExecutorTransport) Option {
+	return func(o *Orchestrator) {
+		if transport != nil {
+			o.transport = transport
+		}
+	}
+}
+
+// validateExecutors checks that the local transport can run every step, naming the steps,
+// alternatives and finalizer that have no executor. Other transports bind steps to
+// executors themselves, so they are not checked.
+func (o *Orchestrator) validateExecutors(workflow *WorkflowDefinition) error {
+	switch o.transport.(type) {
+	case LocalTransport, *LocalTransport:
+	default:
+		return nil
+	}
+
+	var missing []string
+	check := func(stepDef *StepDefinition) {
+		if stepDef.Executor == nil && stepDef.AdvancedExecutor == nil {
+			missing = append(missing, stepDef.ID)
+		}
+	}
+	for _, stepDef := range workflow.Steps {
+		if len(stepDef.Alternatives) == 0 {
+			check(stepDef)
+		}
+		for _, member := range stepDef.Alternatives {
+			check(member)
+		}
+	}
+	if workflow.Finalizer != nil {
+		check(workflow.Finalizer)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: workflow %s has no executor bound for step %s", ErrExecutorNotFound, workflow.ID, strings.Join(missing, ", "))
+	}
+	return nil
+}
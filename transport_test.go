@@ -0,0 +1,63 @@
+package orchwf
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// countingTransport counts calls per step and delegates to LocalTransport
+type countingTransport struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (t *countingTransport) Execute(ctx context.Context, stepDef *StepDefinition, input map[string]interface{}) (map[string]interface{}, error) {
+	t.mu.Lock()
+	t.calls[stepDef.ID]++
+	t.mu.Unlock()
+	return LocalTransport{}.Execute(ctx, stepDef, input)
+}
+
+func TestOrchestrator_Transport(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"direct", nil},
+		{"sandboxed", []Option{WithStepSandbox(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &countingTransport{calls: make(map[string]int)}
+			orchestrator := NewOrchestrator(NewInMemoryStateManager(), append(tt.opts, WithTransport(transport))...)
+
+			workflow, _ := NewWorkflowBuilder("transport", "Transport").
+				AddStepFunc("sync", "Sync", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return map[string]interface{}{"sync": true}, nil
+				}).
+				AddStepFunc("async", "Async", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+					return map[string]interface{}{"async": true}, nil
+				}, WithStepAsync(true)).
+				Build()
+			orchestrator.RegisterWorkflow(workflow)
+
+			result, err := orchestrator.StartWorkflow(context.Background(), "transport", nil, nil)
+			if err != nil {
+				t.Fatalf("StartWorkflow() error = %v", err)
+			}
+			if result.Output["sync"] != true || result.Output["async"] != true {
+				t.Errorf("workflow output = %v, want both step outputs", result.Output)
+			}
+			if transport.calls["sync"] != 1 || transport.calls["async"] != 1 {
+				t.Errorf("transport calls = %v, want one per step", transport.calls)
+			}
+		})
+	}
+
+	// Without WithTransport, executors run locally
+	if _, ok := NewOrchestrator(NewInMemoryStateManager()).transport.(LocalTransport); !ok {
+		t.Error("default transport should be LocalTransport")
+	}
+}
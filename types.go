@@ -3,6 +3,8 @@ package orchwf
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,11 +13,14 @@ type WorkflowStatus string
 
 const (
 	WorkflowStatusPending   WorkflowStatus = "pending"
+	WorkflowStatusQueued    WorkflowStatus = "queued" // Accepted but waiting for a slot under WithMaxInFlight
 	WorkflowStatusRunning   WorkflowStatus = "running"
 	WorkflowStatusCompleted WorkflowStatus = "completed"
 	WorkflowStatusFailed    WorkflowStatus = "failed"
 	WorkflowStatusCancelled WorkflowStatus = "cancelled"
 	WorkflowStatusRetrying  WorkflowStatus = "retrying"
+	WorkflowStatusWaiting   WorkflowStatus = "waiting" // Paused until a timer step is due
+	WorkflowStatusPaused    WorkflowStatus = "paused"  // Stopped because the state manager kept failing
 )
 
 // StepStatus represents the current status of a workflow step
@@ -28,6 +33,56 @@ const (
 	StepStatusFailed    StepStatus = "failed"
 	StepStatusSkipped   StepStatus = "skipped"
 	StepStatusRetrying  StepStatus = "retrying"
+	StepStatusWaiting   StepStatus = "waiting"   // Timer step waiting for its wake time
+	StepStatusCancelled StepStatus = "cancelled" // Cancelled because a sibling required step failed
+)
+
+// FailurePolicy defines what happens to the rest of the workflow when a required step fails
+type FailurePolicy string
+
+const (
+	FailurePolicyFailFast     FailurePolicy = "fail_fast"     // Cancel running siblings and fail immediately (default)
+	FailurePolicyCompleteWave FailurePolicy = "complete_wave" // Let the current wave of ready steps finish, then fail
+)
+
+// WaveStrategy controls how sync and async steps that become ready together are run
+type WaveStrategy string
+
+const (
+	WaveStrategySequential WaveStrategy = ""        // Sync steps run one by one, then async steps run concurrently (default)
+	WaveStrategyOverlap    WaveStrategy = "overlap" // Async steps start first and run while the sync steps execute
+)
+
+// PersistPolicy controls how often step state is saved to the state manager while a workflow runs
+type PersistPolicy string
+
+const (
+	PersistEveryTransition PersistPolicy = ""              // Save every step transition and its event (default)
+	PersistTerminalOnly    PersistPolicy = "terminal_only" // Save each step once it completes, fails or is skipped
+	PersistOnFailure       PersistPolicy = "on_failure"    // Save completed steps only if the workflow fails or waits
+)
+
+// SkipReason explains why a step was skipped
+type SkipReason string
+
+const (
+	SkipReasonOptionalFailure SkipReason = "optional_failure" // A non-required step failed
+	SkipReasonCancelled       SkipReason = "cancelled"        // The workflow failed before the step ran
+	SkipReasonEarlySuccess    SkipReason = "early_success"    // A success step completed the workflow first
+	SkipReasonAlternativeWon  SkipReason = "alternative_won"  // An earlier alternative of the group succeeded
+	SkipReasonTimedOut        SkipReason = "timed_out"        // A non-required step ran out of time
+)
+
+// FailureKind classifies why a step failed, so callers need not match error messages
+type FailureKind string
+
+const (
+	FailureKindExhaustedRetries FailureKind = "exhausted_retries" // The executor returned an error on every attempt allowed
+	FailureKindTimeout          FailureKind = "timeout"           // The step's timeout or input deadline ran out
+	FailureKindMaxDuration      FailureKind = "max_duration"      // The step ran past its max duration across retries
+	FailureKindCancelled        FailureKind = "cancelled"         // The workflow's context was cancelled while the step ran
+	FailureKindValidation       FailureKind = "validation"        // The step's input was invalid, such as an unparsable deadline
+	FailureKindPanic            FailureKind = "panic"             // The executor panicked on the last attempt
 )
 
 // ExecutionMode defines how steps should be executed
@@ -42,32 +97,81 @@ const (
 // It receives the context, input data, and returns output data or error
 type StepExecutor func(ctx context.Context, input map[string]interface{}) (output map[string]interface{}, err error)
 
+// StepTimer computes the time at which a timer step should wake up
+type StepTimer func(input map[string]interface{}) time.Time
+
+// StepReducer folds the collected outputs of a fan-in step's dependencies into a single map
+type StepReducer func(outputs []map[string]interface{}) map[string]interface{}
+
+// DependencyResolver returns the IDs of the steps a step depends on for a given workflow input
+type DependencyResolver func(input map[string]interface{}) []string
+
+// OutputMapper builds a workflow's output from the outputs of its completed steps, keyed by step ID
+type OutputMapper func(stepOutputs map[string]map[string]interface{}) map[string]interface{}
+
+// InputInterceptor returns the input passed to one attempt of a step executor, for
+// example with a freshly fetched secret added. An error fails the attempt.
+type InputInterceptor func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error)
+
+// OutputPredicate inspects a step's output and reports whether it matches a condition
+type OutputPredicate func(output map[string]interface{}) bool
+
 // StepCompensator is a function that compensates/rolls back a step on failure
 type StepCompensator func(ctx context.Context, input map[string]interface{}) error
 
 // WorkflowDefinition defines the structure of a workflow
 type WorkflowDefinition struct {
-	ID          string
-	Name        string
-	Description string
-	Version     string
-	Steps       []*StepDefinition
-	Metadata    map[string]interface{}
+	ID                    string
+	Name                  string
+	Description           string
+	Version               string
+	Steps                 []*StepDefinition
+	Metadata              map[string]interface{}
+	FailurePolicy         FailurePolicy          // Behavior on required step failure (default: FailurePolicyFailFast)
+	Finalizer             *StepDefinition        // Runs after the steps complete or fail, like a defer
+	InputDefaults         map[string]interface{} // Values merged beneath the caller's input when an instance starts
+	SuccessSteps          []string               // Steps whose completion ends the workflow successfully
+	WaveStrategy          WaveStrategy           // How sync and async steps of the same wave are scheduled
+	GateStep              string                 // Step that must succeed before any other step starts
+	OutputMapping         OutputMapper           // Builds the output of a completed workflow from its step outputs
+	DeepMerge             bool                   // Merge nested output maps recursively instead of replacing them
+	PersistPolicy         PersistPolicy          // How often step state is saved while the workflow runs (default: PersistEveryTransition)
+	MaxConcurrentSteps    int                    // Cap on steps running at once across all instances of the workflow (0 = unlimited)
+	RedactedKeys          []string               // Input and output keys masked in persisted state and events
+	Priority              int                    // Higher number = served first when instances queue for a slot (default: 0)
+	CompensateOnCancel    bool                   // Run compensation when CancelWorkflow stops an instance
+	StrictOrdering        bool                   // Run every step one at a time and fail the instance if any two overlapped
+	FailOnOptionalFailure bool                   // Fail the instance at completion if a non-required step failed and was skipped
 }
 
 // StepDefinition defines a single step in the workflow
 type StepDefinition struct {
-	ID           string
-	Name         string
-	Description  string
-	Executor     StepExecutor
-	Compensator  StepCompensator
-	Dependencies []string // IDs of steps that must complete before this step
-	RetryPolicy  *RetryPolicy
-	Timeout      time.Duration
-	Required     bool // If false, failure won't stop the workflow
-	Async        bool // If true, step runs asynchronously
-	Priority     int  // Higher number = higher priority (default: 0)
+	ID                  string
+	Name                string
+	Description         string
+	Executor            StepExecutor
+	ExecutorKey         string // Registry key of the executor, used when exporting; defaults to the step ID
+	Compensator         StepCompensator
+	Dependencies        []string // IDs of steps that must complete before this step
+	RetryPolicy         *RetryPolicy
+	Timeout             time.Duration
+	Required            bool               // If false, failure won't stop the workflow
+	Async               bool               // If true, step runs asynchronously
+	Priority            int                // Higher number = higher priority (default: 0)
+	TimerUntil          StepTimer          // If set, the step waits until the returned time before executing
+	DeadlineKey         string             // Input key holding an absolute deadline (time.Time or RFC3339 string)
+	Resource            string             // Name of a shared resource pool the step draws from
+	ResourceWeight      int                // Units of the resource pool held while the step runs
+	Alternatives        []*StepDefinition  // If set, the step is a group that runs these in order until one succeeds
+	FanIn               bool               // If true, dependency outputs are collected as a list under the step's own ID
+	Reducer             StepReducer        // Folds the collected fan-in outputs; its result is merged into the input
+	RetryableOutput     OutputPredicate    // If it returns true for a successful attempt's output, the attempt is retried
+	DynamicDependencies DependencyResolver // If set, replaces Dependencies with IDs resolved from the workflow input
+	InputInterceptor    InputInterceptor   // If set, rewrites the prepared input before every attempt
+	AdvancedExecutor    AdvancedExecutor   // If set, runs instead of Executor with a StepContext
+	MaxDuration         time.Duration      // Wall-clock cap on all attempts, counted from the first one (0 = none)
+	Tags                []string           // Groups steps across workflows in metrics, events and timelines
+	RequiredOutputKeys  []string           // Keys a successful attempt's output must hold with non-nil values
 }
 
 // RetryPolicy defines retry behavior for a step
@@ -79,42 +183,52 @@ type RetryPolicy struct {
 	RetryableErrors []string // Specific error patterns that should trigger retry
 }
 
-// WorkflowInstance represents a running instance of a workflow
+// WorkflowInstance represents a running instance of a workflow.
+// The JSON tags follow WorkflowInstanceDTO; use ToDTO for a stable wire format.
 type WorkflowInstance struct {
-	ID            string
-	WorkflowID    string
-	Status        WorkflowStatus
-	Input         map[string]interface{}
-	Output        map[string]interface{}
-	Context       map[string]interface{}
-	CurrentStepID string
-	Steps         []*StepInstance
-	StartedAt     time.Time
-	CompletedAt   *time.Time
-	Error         *string
-	RetryCount    int
-	LastRetryAt   *time.Time
-	Metadata      map[string]interface{}
-	TraceID       string
-	CorrelationID string
-	BusinessID    string
+	ID            string                 `json:"id"`
+	WorkflowID    string                 `json:"workflow_id"`
+	Status        WorkflowStatus         `json:"status"`
+	Input         map[string]interface{} `json:"input"`
+	Output        map[string]interface{} `json:"output"`
+	Context       map[string]interface{} `json:"context"`
+	CurrentStepID string                 `json:"current_step_id,omitempty"`
+	Steps         []*StepInstance        `json:"steps"`
+	StartedAt     time.Time              `json:"started_at"`
+	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
+	Error         *string                `json:"error,omitempty"`
+	RetryCount    int                    `json:"retry_count"`
+	LastRetryAt   *time.Time             `json:"last_retry_at,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	TraceID       string                 `json:"trace_id,omitempty"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	BusinessID    string                 `json:"business_id,omitempty"`
+	ParentInstID  string                 `json:"parent_workflow_inst_id,omitempty"` // ID of the workflow instance that started this one, if any
 }
 
 // StepInstance represents a running instance of a step
 type StepInstance struct {
-	ID             string
-	StepID         string
-	WorkflowInstID string
-	Status         StepStatus
-	Input          map[string]interface{}
-	Output         map[string]interface{}
-	StartedAt      *time.Time
-	CompletedAt    *time.Time
-	Error          *string
-	RetryCount     int
-	LastRetryAt    *time.Time
-	DurationMs     int64
-	ExecutionOrder int
+	ID             string                 `json:"id"`
+	StepID         string                 `json:"step_id"`
+	WorkflowInstID string                 `json:"workflow_inst_id"`
+	Status         StepStatus             `json:"status"`
+	Input          map[string]interface{} `json:"input,omitempty"`
+	Output         map[string]interface{} `json:"output,omitempty"`
+	StartedAt      *time.Time             `json:"started_at,omitempty"`
+	CompletedAt    *time.Time             `json:"completed_at,omitempty"`
+	Error          *string                `json:"error,omitempty"`
+	RetryCount     int                    `json:"retry_count"`
+	LastRetryAt    *time.Time             `json:"last_retry_at,omitempty"`
+	DurationMs     int64                  `json:"duration_ms"`
+	ExecutionOrder int                    `json:"execution_order"`
+	Priority       int                    `json:"priority"`               // Effective priority captured from the definition at creation
+	WakeAt         *time.Time             `json:"wake_at,omitempty"`      // Persisted wake time for timer steps
+	SkipReason     SkipReason             `json:"skip_reason,omitempty"`  // Why the step was skipped (empty unless skipped)
+	ReadyAt        *time.Time             `json:"ready_at,omitempty"`     // When the step's dependencies were met and it became ready to run
+	WaitMs         int64                  `json:"wait_ms"`                // Time between ReadyAt and StartedAt (scheduling delay)
+	Attachments    []Attachment           `json:"attachments,omitempty"`  // Artifacts the step produced, stored in a BlobStore
+	Provenance     map[string]string      `json:"provenance,omitempty"`   // Source of each input key, recorded under WithInputProvenance
+	FailureKind    FailureKind            `json:"failure_kind,omitempty"` // Why the step failed (empty unless failed)
 }
 
 // WorkflowEvent represents an event in the workflow lifecycle
@@ -127,6 +241,35 @@ type WorkflowEvent struct {
 	Timestamp      time.Time
 }
 
+// Event types recorded in WorkflowEvent.EventType. Listeners can switch on these
+// instead of matching strings.
+const (
+	EventWorkflowStarted      = "workflow.started"          // An instance started running
+	EventWorkflowResumed      = "workflow.resumed"          // ResumeWorkflow picked up a saved instance
+	EventWorkflowRedriven     = "workflow.redriven"         // RedriveFailedSteps reset steps to run again
+	EventWorkflowWaiting      = "workflow.waiting"          // The instance is waiting on timer steps
+	EventWorkflowPaused       = "workflow.paused"           // The persistence breaker paused the instance
+	EventWorkflowCompleted    = "workflow.completed"        // All steps completed
+	EventWorkflowFailed       = "workflow.failed"           // A required step failed
+	EventWorkflowCancelled    = "workflow.cancelled"        // CancelWorkflow stopped the instance
+	EventWorkflowEarlySuccess = "workflow.early_success"    // A success step ended the workflow early
+	EventWorkflowBackpressure = "workflow.backpressure"     // A step delayed the next wave
+	EventWorkflowChildStarted = "workflow.child_started"    // Recorded on the parent when a child instance starts
+	EventFinalizerFailed      = "workflow.finalizer_failed" // The workflow finalizer failed
+	EventStepStarted          = "step.started"              // A step's first attempt started
+	EventStepRetry            = "step.retry"                // A step is about to retry
+	EventStepTimeout          = "step.timeout"              // A step attempt ran out of time
+	EventStepWaiting          = "step.waiting"              // A timer step is waiting for its wake time
+	EventStepProgress         = "step.progress"             // A step reported progress with ReportProgress
+	EventStepCompleted        = "step.completed"            // A step completed
+	EventStepFailed           = "step.failed"               // A step failed after its last attempt
+	EventStepSkipped          = "step.skipped"              // A step was skipped; the payload holds the reason
+	EventStepCancelled        = "step.cancelled"            // A step was cancelled after a sibling failed
+	EventAlternativeWon       = "step.alternative_won"      // An alternative succeeded for its group
+	EventStepCompensated      = "step.compensated"          // A completed step's compensator succeeded
+	EventCompensationFailed   = "step.compensation_failed"  // A completed step's compensator failed
+)
+
 // StepResult represents the result of a step execution
 type StepResult struct {
 	Success  bool
@@ -137,11 +280,13 @@ type StepResult struct {
 
 // WorkflowResult represents the final result of a workflow execution
 type WorkflowResult struct {
-	Success      bool
-	WorkflowInst *WorkflowInstance
-	Output       map[string]interface{}
-	Error        error
-	Duration     time.Duration
+	Success        bool
+	WorkflowInst   *WorkflowInstance
+	Output         map[string]interface{}
+	Error          error
+	FinalizerError error               // Error returned by the workflow finalizer, if any
+	Compensation   *CompensationResult // Compensators run after a failure, nil when none ran
+	Duration       time.Duration
 }
 
 // Helper methods
@@ -234,7 +379,8 @@ func (w *WorkflowInstance) CanRetry(maxRetries int) bool {
 func (s *StepInstance) IsCompleted() bool {
 	return s.Status == StepStatusCompleted ||
 		s.Status == StepStatusFailed ||
-		s.Status == StepStatusSkipped
+		s.Status == StepStatusSkipped ||
+		s.Status == StepStatusCancelled
 }
 
 // CanRetry checks if the step can be retried
@@ -244,3 +390,169 @@ func (s *StepInstance) CanRetry(policy *RetryPolicy) bool {
 	}
 	return s.Status == StepStatusFailed && s.RetryCount < policy.MaxAttempts
 }
+
+// Clone returns a deep copy of the instance and its steps, including nested maps and slices
+// in its input, output, context and metadata. Results hold a clone, so changing them
+// never touches the orchestrator's state.
+func (w *WorkflowInstance) Clone() *WorkflowInstance {
+	if w == nil {
+		return nil
+	}
+
+	copy := &WorkflowInstance{
+		ID:            w.ID,
+		WorkflowID:    w.WorkflowID,
+		Status:        w.Status,
+		CurrentStepID: w.CurrentStepID,
+		StartedAt:     w.StartedAt,
+		RetryCount:    w.RetryCount,
+		TraceID:       w.TraceID,
+		CorrelationID: w.CorrelationID,
+		BusinessID:    w.BusinessID,
+		ParentInstID:  w.ParentInstID,
+	}
+
+	// Copy pointers
+	if w.CompletedAt != nil {
+		completedAt := *w.CompletedAt
+		copy.CompletedAt = &completedAt
+	}
+	if w.Error != nil {
+		error := *w.Error
+		copy.Error = &error
+	}
+	if w.LastRetryAt != nil {
+		lastRetryAt := *w.LastRetryAt
+		copy.LastRetryAt = &lastRetryAt
+	}
+
+	// Copy maps
+	copy.Input = cloneMap(w.Input)
+	copy.Output = cloneMap(w.Output)
+	copy.Context = cloneMap(w.Context)
+	copy.Metadata = cloneMap(w.Metadata)
+
+	// Copy steps
+	copy.Steps = make([]*StepInstance, len(w.Steps))
+	for i, step := range w.Steps {
+		copy.Steps[i] = step.Clone()
+	}
+
+	return copy
+}
+
+// Clone returns a deep copy of the step instance
+func (s *StepInstance) Clone() *StepInstance {
+	if s == nil {
+		return nil
+	}
+
+	copy := &StepInstance{
+		ID:             s.ID,
+		StepID:         s.StepID,
+		WorkflowInstID: s.WorkflowInstID,
+		Status:         s.Status,
+		RetryCount:     s.RetryCount,
+		DurationMs:     s.DurationMs,
+		ExecutionOrder: s.ExecutionOrder,
+		Priority:       s.Priority,
+		SkipReason:     s.SkipReason,
+		FailureKind:    s.FailureKind,
+		WaitMs:         s.WaitMs,
+	}
+
+	// Copy pointers
+	if s.StartedAt != nil {
+		startedAt := *s.StartedAt
+		copy.StartedAt = &startedAt
+	}
+	if s.ReadyAt != nil {
+		readyAt := *s.ReadyAt
+		copy.ReadyAt = &readyAt
+	}
+	if s.CompletedAt != nil {
+		completedAt := *s.CompletedAt
+		copy.CompletedAt = &completedAt
+	}
+	if s.Error != nil {
+		error := *s.Error
+		copy.Error = &error
+	}
+	if s.LastRetryAt != nil {
+		lastRetryAt := *s.LastRetryAt
+		copy.LastRetryAt = &lastRetryAt
+	}
+	if s.WakeAt != nil {
+		wakeAt := *s.WakeAt
+		copy.WakeAt = &wakeAt
+	}
+
+	// Copy maps
+	copy.Input = cloneMap(s.Input)
+	copy.Output = cloneMap(s.Output)
+
+	copy.Provenance = copyProvenance(s.Provenance)
+
+	// Copy slices
+	if s.Attachments != nil {
+		copy.Attachments = append([]Attachment(nil), s.Attachments...)
+	}
+
+	return copy
+}
+
+// Validate reports whether the retry policy can drive the retry loop
+func (p *RetryPolicy) Validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return fmt.Errorf("%w: max attempts must be at least 1, got %d", ErrInvalidRetryPolicy, p.MaxAttempts)
+	case p.InitialInterval < 0:
+		return fmt.Errorf("%w: initial interval must not be negative, got %v", ErrInvalidRetryPolicy, p.InitialInterval)
+	case p.MaxInterval < 0:
+		return fmt.Errorf("%w: max interval must not be negative, got %v", ErrInvalidRetryPolicy, p.MaxInterval)
+	case p.Multiplier <= 0:
+		return fmt.Errorf("%w: multiplier must be positive, got %v", ErrInvalidRetryPolicy, p.Multiplier)
+	}
+	return nil
+}
+
+// StepOutput returns the output recorded for the given step ID.
+// The step instances are consulted first, falling back to the per-step entry
+// stored in the workflow context.
+func (r *WorkflowResult) StepOutput(stepID string) (map[string]interface{}, bool) {
+	if r == nil || r.WorkflowInst == nil {
+		return nil, false
+	}
+
+	for _, step := range r.WorkflowInst.Steps {
+		if step.StepID == stepID && step.Status == StepStatusCompleted {
+			return step.Output, true
+		}
+	}
+
+	output, ok := r.WorkflowInst.Context[stepID].(map[string]interface{})
+	return output, ok
+}
+
+// GetPath looks up a nested value in the workflow output using a dot-separated
+// path such as "payment.receipt.id". It returns false if any segment is missing
+// or an intermediate value is not a map.
+func (r *WorkflowResult) GetPath(path string) (interface{}, bool) {
+	if r == nil || path == "" {
+		return nil, false
+	}
+
+	var current interface{} = r.Output
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
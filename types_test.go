@@ -132,6 +132,7 @@ func TestWorkflowInstance_IsCompleted(t *testing.T) {
 		expected bool
 	}{
 		{"pending", WorkflowStatusPending, false},
+		{"queued", WorkflowStatusQueued, false},
 		{"running", WorkflowStatusRunning, false},
 		{"completed", WorkflowStatusCompleted, true},
 		{"failed", WorkflowStatusFailed, true},
@@ -184,6 +185,7 @@ func TestStepInstance_IsCompleted(t *testing.T) {
 		{"completed", StepStatusCompleted, true},
 		{"failed", StepStatusFailed, true},
 		{"skipped", StepStatusSkipped, true},
+		{"cancelled", StepStatusCancelled, true},
 		{"retrying", StepStatusRetrying, false},
 	}
 
@@ -220,3 +222,110 @@ func TestStepInstance_CanRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowInstance_Clone(t *testing.T) {
+	original := &WorkflowInstance{
+		ID:     "inst-1",
+		Status: WorkflowStatusCompleted,
+		Output: map[string]interface{}{"order": map[string]interface{}{"items": []interface{}{"a"}}},
+		Steps: []*StepInstance{
+			{StepID: "step1", Status: StepStatusCompleted, Output: map[string]interface{}{"ok": true}},
+		},
+	}
+
+	clone := original.Clone()
+	clone.Output["order"].(map[string]interface{})["items"].([]interface{})[0] = "changed"
+	clone.Steps[0].Status = StepStatusFailed
+	clone.Steps[0].Output["ok"] = false
+
+	if items := original.Output["order"].(map[string]interface{})["items"].([]interface{}); items[0] != "a" {
+		t.Errorf("original nested output = %v, want it unchanged", items)
+	}
+	if step := original.Steps[0]; step.Status != StepStatusCompleted || step.Output["ok"] != true {
+		t.Errorf("original step = %+v, want it unchanged", step)
+	}
+	if (*WorkflowInstance)(nil).Clone() != nil {
+		t.Errorf("nil Clone() should return nil")
+	}
+}
+
+func TestWorkflowResult_StepOutput(t *testing.T) {
+	result := &WorkflowResult{
+		WorkflowInst: &WorkflowInstance{
+			Steps: []*StepInstance{
+				{StepID: "step1", Status: StepStatusCompleted, Output: map[string]interface{}{"result": "ok"}},
+				{StepID: "step2", Status: StepStatusFailed, Output: map[string]interface{}{}},
+			},
+			Context: map[string]interface{}{
+				"step3": map[string]interface{}{"value": 3},
+				"step4": "not a map",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		stepID string
+		wantOK bool
+	}{
+		{"completed step", "step1", true},
+		{"failed step", "step2", false},
+		{"context fallback", "step3", true},
+		{"wrong type in context", "step4", false},
+		{"missing step", "missing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := result.StepOutput(tt.stepID); ok != tt.wantOK {
+				t.Errorf("StepOutput(%q) ok = %v, want %v", tt.stepID, ok, tt.wantOK)
+			}
+		})
+	}
+
+	output, _ := result.StepOutput("step1")
+	if output["result"] != "ok" {
+		t.Errorf("StepOutput() = %v, want result=ok", output)
+	}
+
+	var nilResult *WorkflowResult
+	if _, ok := nilResult.StepOutput("step1"); ok {
+		t.Errorf("StepOutput() on nil result should return ok=false")
+	}
+}
+
+func TestWorkflowResult_GetPath(t *testing.T) {
+	result := &WorkflowResult{
+		Output: map[string]interface{}{
+			"status": "done",
+			"payment": map[string]interface{}{
+				"receipt": map[string]interface{}{"id": "r-1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected interface{}
+		wantOK   bool
+	}{
+		{"top level", "status", "done", true},
+		{"nested", "payment.receipt.id", "r-1", true},
+		{"missing key", "payment.missing", nil, false},
+		{"through non-map", "status.value", nil, false},
+		{"empty path", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := result.GetPath(tt.path)
+			if ok != tt.wantOK {
+				t.Errorf("GetPath(%q) ok = %v, want %v", tt.path, ok, tt.wantOK)
+			}
+			if ok && got != tt.expected {
+				t.Errorf("GetPath(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
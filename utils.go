@@ -8,3 +8,77 @@ func stringPtr(s string) *string {
 	}
 	return &s
 }
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// copyMap returns a shallow copy of m, or nil if m is nil
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// cloneMap deep-copies a map of JSON-like values; a nil map becomes an empty one
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	cloned := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cloned[k] = cloneValue(v)
+	}
+	return cloned
+}
+
+// cloneValue deep-copies the maps and slices of a JSON-like value; other values are shared
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		cloned := make([]interface{}, len(v))
+		for i, item := range v {
+			cloned[i] = cloneValue(item)
+		}
+		return cloned
+	case []map[string]interface{}:
+		cloned := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			cloned[i] = cloneMap(item)
+		}
+		return cloned
+	default:
+		return v
+	}
+}
+
+// applyInputDefaults returns input layered over defaults; caller values win
+func applyInputDefaults(defaults, input map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return input
+	}
+	merged := make(map[string]interface{}, len(defaults)+len(input))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range input {
+		merged[k] = v
+	}
+	return merged
+}
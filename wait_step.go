@@ -0,0 +1,116 @@
+package orchwf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// eventWatchers wakes the callers waiting on a workflow instance whenever one of its
+// events is emitted. The zero value is ready to use.
+type eventWatchers struct {
+	mu       sync.Mutex
+	watching map[string]map[chan struct{}]struct{} // Workflow instance ID -> wake channels
+}
+
+// watch registers a wake channel for an instance and returns it with the func that
+// unregisters it
+func (w *eventWatchers) watch(workflowInstID string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	if w.watching == nil {
+		w.watching = make(map[string]map[chan struct{}]struct{})
+	}
+	if w.watching[workflowInstID] == nil {
+		w.watching[workflowInstID] = make(map[chan struct{}]struct{})
+	}
+	w.watching[workflowInstID][wake] = struct{}{}
+	w.mu.Unlock()
+
+	return wake, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watching[workflowInstID], wake)
+		if len(w.watching[workflowInstID]) == 0 {
+			delete(w.watching, workflowInstID)
+		}
+	}
+}
+
+// notify wakes every caller watching an instance without blocking the emitter
+func (w *eventWatchers) notify(workflowInstID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for wake := range w.watching[workflowInstID] {
+		select {
+		case wake <- struct{}{}:
+		default: // Already woken; the caller re-reads the state anyway
+		}
+	}
+}
+
+// WaitForStep blocks until the named step of a workflow instance reaches status, as
+// saved by the state manager. It re-checks the instance each time the instance emits
+// an event rather than polling. If the step can no longer reach status, because it
+// moved past it or the workflow finished without it getting there, WaitForStep returns
+// ErrStepUnreachable. Steps of a successful run are not saved under PersistOnFailure, so
+// wait for the workflow itself there.
+func (o *Orchestrator) WaitForStep(ctx context.Context, workflowInstID, stepID string, status StepStatus) error {
+	wake, stop := o.watchers.watch(workflowInstID)
+	defer stop()
+
+	for {
+		instance, err := o.stateManager.GetWorkflow(ctx, workflowInstID)
+		if err != nil {
+			return err
+		}
+
+		// Steps are saved once the instance starts executing; until then the step is pending
+		current, found := StepStatusPending, len(instance.Steps) == 0
+		for _, stepInst := range instance.Steps {
+			if stepInst.StepID == stepID {
+				current, found = stepInst.Status, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s in workflow instance %s", ErrStepNotFound, stepID, workflowInstID)
+		}
+		if current == status {
+			return nil
+		}
+		if instance.IsCompleted() || !canReachStep(current, status) {
+			return fmt.Errorf("%w: step %s is %s and workflow %s is %s, want %s",
+				ErrStepUnreachable, stepID, current, workflowInstID, instance.Status, status)
+		}
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// canReachStep reports whether a step can move from one status to another through
+// StepTransitions
+func canReachStep(from, to StepStatus) bool {
+	seen := map[StepStatus]bool{from: true}
+	queue := []StepStatus{from}
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+		for _, next := range StepTransitions[status] {
+			if next == to {
+				return true
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package orchwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_WaitForStep(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	unblock := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("wait-step", "Wait Step").
+		AddStepFunc("validate_payment", "Validate Payment", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"valid": true}, nil
+		}).
+		AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-unblock
+			return nil, nil
+		}, WithStepDeps("validate_payment")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	instanceID, err := orchestrator.StartWorkflowAsync(ctx, "wait-step", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+
+	if err := orchestrator.WaitForStep(ctx, instanceID, "validate_payment", StepStatusCompleted); err != nil {
+		t.Fatalf("WaitForStep(validate_payment, completed) error = %v", err)
+	}
+	if err := orchestrator.WaitForStep(ctx, instanceID, "ship", StepStatusRunning); err != nil {
+		t.Fatalf("WaitForStep(ship, running) error = %v", err)
+	}
+
+	// The running step does not complete until it is unblocked
+	shortCtx, cancelShort := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelShort()
+	if err := orchestrator.WaitForStep(shortCtx, instanceID, "ship", StepStatusCompleted); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForStep(ship, completed) error = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(unblock)
+	if err := orchestrator.WaitForStep(ctx, instanceID, "ship", StepStatusCompleted); err != nil {
+		t.Fatalf("WaitForStep(ship, completed) error = %v", err)
+	}
+
+	// A completed step can no longer fail, and unknown steps are reported
+	if err := orchestrator.WaitForStep(ctx, instanceID, "ship", StepStatusFailed); !errors.Is(err, ErrStepUnreachable) {
+		t.Errorf("WaitForStep(ship, failed) error = %v, want %v", err, ErrStepUnreachable)
+	}
+	if err := orchestrator.WaitForStep(ctx, instanceID, "missing", StepStatusCompleted); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("WaitForStep(missing) error = %v, want %v", err, ErrStepNotFound)
+	}
+}
+
+func TestOrchestrator_WaitForStepAfterWorkflowFailed(t *testing.T) {
+	orchestrator := NewOrchestrator(NewInMemoryStateManager())
+
+	unblock := make(chan struct{})
+	workflow, _ := NewWorkflowBuilder("wait-failed", "Wait Failed").
+		AddStepFunc("charge", "Charge", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			<-unblock
+			return nil, errors.New("card declined")
+		}).
+		AddStepFunc("ship", "Ship", func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		}, WithStepDeps("charge")).
+		Build()
+	orchestrator.RegisterWorkflow(workflow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	instanceID, err := orchestrator.StartWorkflowAsync(ctx, "wait-failed", nil, nil)
+	if err != nil {
+		t.Fatalf("StartWorkflowAsync() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- orchestrator.WaitForStep(ctx, instanceID, "ship", StepStatusCompleted)
+	}()
+	close(unblock)
+
+	if err := <-done; !errors.Is(err, ErrStepUnreachable) {
+		t.Errorf("WaitForStep(ship, completed) error = %v, want %v", err, ErrStepUnreachable)
+	}
+}